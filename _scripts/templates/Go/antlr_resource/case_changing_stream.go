@@ -8,19 +8,76 @@ import (
 	"github.com/antlr/antlr4/runtime/Go/antlr"
 )
 
+// ModeReporter is implemented by lexers that can report the mode they
+// are currently matching in. The Go runtime keeps the lexer mode
+// private, so a lexer base class that wants mode-scoped case folding
+// overrides SetMode, PushMode and PopMode to record the mode and
+// returns it from CurrentMode.
+type ModeReporter interface {
+	CurrentMode() int
+}
+
 // CaseChangingStream wraps an existing CharStream, but upper cases, or
 // lower cases the input before it is tokenized.
+//
+// Folding applies to the whole input unless it is switched off with
+// SetFolding, or restricted to some lexer modes with FoldModes or
+// SetModeFolding. This lets a grammar match keywords case
+// insensitively while string literals or embedded languages lexed in
+// their own modes keep their original case.
 type CaseChangingStream struct {
 	antlr.CharStream
 
 	upper bool
+	fold  bool
+
+	lexer    ModeReporter
+	foldMode func(mode int) bool
 }
 
 // NewCaseChangingStream returns a new CaseChangingStream that forces
 // all tokens read from the underlying stream to be either upper case
 // or lower case based on the upper argument.
 func NewCaseChangingStream(in antlr.CharStream, upper bool) *CaseChangingStream {
-	return &CaseChangingStream{in, upper}
+	return &CaseChangingStream{CharStream: in, upper: upper, fold: true}
+}
+
+// SetFolding switches case folding on or off. Lexer actions can call
+// it when entering or leaving a case sensitive region.
+func (is *CaseChangingStream) SetFolding(fold bool) {
+	is.fold = fold
+}
+
+// IsFolding returns true if the next symbol read from the stream
+// will be case folded.
+func (is *CaseChangingStream) IsFolding() bool {
+	if !is.fold {
+		return false
+	}
+	if is.lexer != nil && is.foldMode != nil {
+		return is.foldMode(is.lexer.CurrentMode())
+	}
+	return true
+}
+
+// SetModeFolding asks fold, for each symbol read, whether the current
+// mode of lexer is case insensitive. Passing a nil lexer restores
+// folding of the whole input.
+func (is *CaseChangingStream) SetModeFolding(lexer ModeReporter, fold func(mode int) bool) {
+	is.lexer = lexer
+	is.foldMode = fold
+}
+
+// FoldModes restricts case folding to the given lexer modes; input
+// lexed in any other mode is passed through unchanged.
+func (is *CaseChangingStream) FoldModes(lexer ModeReporter, modes ...int) {
+	folded := make(map[int]bool, len(modes))
+	for _, mode := range modes {
+		folded[mode] = true
+	}
+	is.SetModeFolding(lexer, func(mode int) bool {
+		return folded[mode]
+	})
 }
 
 // LA gets the value of the symbol at offset from the current position
@@ -32,6 +89,9 @@ func(is *CaseChangingStream) LA(offset int) int {
 		// Such as antlr.TokenEOF which is -1
 		return in
 	}
+	if !is.IsFolding() {
+		return in
+	}
 	if is.upper {
 		return int(unicode.ToUpper(rune(in)))
 	}
@@ -0,0 +1,136 @@
+﻿// Template generated code from Antlr4BuildTasks.dotnet-antlr v <version>
+
+package antlr_resource
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+const modeLexerCODE = 1
+
+// modeLexer splits its input into runs of upper case and lower case
+// letters. '{' pushes the CODE mode and '}' pops it. It records the
+// mode it is in the way the ModeReporter doc comment describes, and
+// lexes by hand so that it does not depend on the ATN version of the
+// runtime.
+type modeLexer struct {
+	*antlr.BaseLexer
+	input antlr.CharStream
+	modes []int
+}
+
+func newModeLexer(input antlr.CharStream) *modeLexer {
+	return &modeLexer{BaseLexer: antlr.NewBaseLexer(input), input: input, modes: []int{antlr.LexerDefaultMode}}
+}
+
+func (l *modeLexer) SetMode(m int) {
+	l.modes[len(l.modes)-1] = m
+	l.BaseLexer.SetMode(m)
+}
+
+func (l *modeLexer) PushMode(m int) {
+	l.modes = append(l.modes, m)
+	l.BaseLexer.PushMode(m)
+}
+
+func (l *modeLexer) PopMode() int {
+	l.modes = l.modes[:len(l.modes)-1]
+	return l.BaseLexer.PopMode()
+}
+
+func (l *modeLexer) CurrentMode() int {
+	return l.modes[len(l.modes)-1]
+}
+
+// tokens lexes the whole input and returns its tokens as KIND:text.
+func (l *modeLexer) tokens() string {
+	var tokens []string
+	for c := l.input.LA(1); c != antlr.TokenEOF; c = l.input.LA(1) {
+		start := l.input.Index()
+		kind := "LOWER"
+		switch {
+		case c == ' ':
+			l.input.Consume()
+			continue
+		case c == '{':
+			kind = "OPEN"
+			l.input.Consume()
+			l.PushMode(modeLexerCODE)
+		case c == '}':
+			kind = "CLOSE"
+			l.input.Consume()
+			l.PopMode()
+		case unicode.IsUpper(rune(c)):
+			kind = "UPPER"
+			for unicode.IsUpper(rune(l.input.LA(1))) {
+				l.input.Consume()
+			}
+		default:
+			for unicode.IsLower(rune(l.input.LA(1))) {
+				l.input.Consume()
+			}
+		}
+		tokens = append(tokens, kind+":"+l.input.GetText(start, l.input.Index()-1))
+	}
+	return strings.Join(tokens, " ")
+}
+
+func TestCaseChangingStreamModes(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(*CaseChangingStream, *modeLexer)
+		want      string
+	}{
+		{
+			"whole input",
+			func(*CaseChangingStream, *modeLexer) {},
+			"UPPER:select UPPER:Name OPEN:{ UPPER:Foo UPPER:bar CLOSE:} UPPER:x",
+		},
+		{
+			"FoldModes",
+			func(is *CaseChangingStream, l *modeLexer) { is.FoldModes(l, antlr.LexerDefaultMode) },
+			"UPPER:select UPPER:Name OPEN:{ UPPER:F LOWER:oo LOWER:bar CLOSE:} UPPER:x",
+		},
+		{
+			"SetModeFolding",
+			func(is *CaseChangingStream, l *modeLexer) {
+				is.SetModeFolding(l, func(mode int) bool { return mode != modeLexerCODE })
+			},
+			"UPPER:select UPPER:Name OPEN:{ UPPER:F LOWER:oo LOWER:bar CLOSE:} UPPER:x",
+		},
+		{
+			"SetModeFolding nil",
+			func(is *CaseChangingStream, l *modeLexer) {
+				is.FoldModes(l, antlr.LexerDefaultMode)
+				is.SetModeFolding(nil, nil)
+			},
+			"UPPER:select UPPER:Name OPEN:{ UPPER:Foo UPPER:bar CLOSE:} UPPER:x",
+		},
+	}
+	for _, test := range tests {
+		is := NewCaseChangingStream(antlr.NewInputStream("select Name {Foo bar} x"), true)
+		lexer := newModeLexer(is)
+		test.configure(is, lexer)
+		if got := lexer.tokens(); got != test.want {
+			t.Errorf("%s: got %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestCaseChangingStreamSetFolding(t *testing.T) {
+	is := NewCaseChangingStream(antlr.NewInputStream("aB"), false)
+	if got := is.LA(2); got != 'b' {
+		t.Errorf("LA(2) = %c, want b", got)
+	}
+	is.SetFolding(false)
+	if is.IsFolding() || is.LA(2) != 'B' {
+		t.Errorf("LA(2) = %c with folding off, want B", is.LA(2))
+	}
+	if got := is.LA(3); got != antlr.TokenEOF {
+		t.Errorf("LA(3) = %d, want EOF", got)
+	}
+}
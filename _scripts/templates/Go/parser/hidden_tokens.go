@@ -0,0 +1,57 @@
+﻿// Template generated code from Antlr4BuildTasks.dotnet-antlr v <version>
+
+package parser
+
+// Helpers for parser bases that look at the hidden channel tokens to
+// decide where a statement may end, like the javascript, jsx and
+// typescript ones. They do not depend on the grammar, and are tested
+// with a hand-written lexer in hidden_tokens_test.go.
+
+import (
+	"strings"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// hiddenTokensBefore returns the hidden channel tokens between the
+// token at index and the previous default channel token, or the start
+// of the input, in order.
+func hiddenTokensBefore(stream antlr.TokenStream, index int) []antlr.Token {
+	if index \<= 0 {
+		return nil
+	}
+	if s, ok := stream.(*antlr.CommonTokenStream); ok {
+		return s.GetHiddenTokensToLeft(index, antlr.TokenHiddenChannel)
+	}
+	var hidden []antlr.Token
+	for i := index - 1; i >= 0 && stream.Get(i).GetChannel() != antlr.TokenDefaultChannel; i-- {
+		if t := stream.Get(i); t.GetChannel() == antlr.TokenHiddenChannel {
+			hidden = append([]antlr.Token{t}, hidden...)
+		}
+	}
+	return hidden
+}
+
+// hasHiddenToken returns true if a token of type tokenType is among
+// the hidden channel tokens before the token at index.
+func hasHiddenToken(stream antlr.TokenStream, index, tokenType int) bool {
+	for _, t := range hiddenTokensBefore(stream, index) {
+		if t.GetTokenType() == tokenType {
+			return true
+		}
+	}
+	return false
+}
+
+// newlineBefore returns true if a line terminator separates the token
+// at index from the previous default channel token: a line terminator
+// token or a comment spanning lines among the hidden channel tokens
+// between them, however many there are (a /* x */ /* y */ \n b).
+func newlineBefore(stream antlr.TokenStream, index int) bool {
+	for _, t := range hiddenTokensBefore(stream, index) {
+		if strings.ContainsAny(t.GetText(), "\r\n\u2028\u2029") {
+			return true
+		}
+	}
+	return false
+}
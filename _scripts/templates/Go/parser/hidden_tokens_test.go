@@ -0,0 +1,129 @@
+﻿// Template generated code from Antlr4BuildTasks.dotnet-antlr v <version>
+
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// Token types of hiddenLexer.
+const (
+	hiddenLexerWord = iota + 1
+	hiddenLexerSpace
+	hiddenLexerLineTerminator
+	hiddenLexerComment
+)
+
+// hiddenLexer lexes words and semicolons on the default channel, and
+// white space, line terminators and comments on the hidden channel,
+// like the lexers of the grammars using the helpers. It lexes by hand
+// so that the test does not depend on a grammar.
+type hiddenLexer struct {
+	*antlr.BaseLexer
+	src []rune
+	pos int
+}
+
+func newHiddenLexer(src string) *hiddenLexer {
+	return &hiddenLexer{BaseLexer: antlr.NewBaseLexer(antlr.NewInputStream(src)), src: []rune(src)}
+}
+
+func (l *hiddenLexer) NextToken() antlr.Token {
+	start := l.pos
+	at := func(s string) bool {
+		r := []rune(s)
+		return l.pos+len(r) \<= len(l.src) && string(l.src[l.pos:l.pos+len(r)]) == s
+	}
+	ttype, channel := hiddenLexerWord, antlr.TokenDefaultChannel
+	switch {
+	case l.pos == len(l.src):
+		ttype = antlr.TokenEOF
+	case at(" ") || at("\t"):
+		ttype, channel = hiddenLexerSpace, antlr.TokenHiddenChannel
+		for l.pos \< len(l.src) && (at(" ") || at("\t")) {
+			l.pos++
+		}
+	case at("\n") || at("\r") || at("\u2028") || at("\u2029"):
+		ttype, channel = hiddenLexerLineTerminator, antlr.TokenHiddenChannel
+		l.pos++
+	case at("/*"):
+		ttype, channel = hiddenLexerComment, antlr.TokenHiddenChannel
+		for l.pos += 2; l.pos \< len(l.src) && !at("*/"); l.pos++ {
+		}
+		l.pos += 2
+	case at("//"):
+		ttype, channel = hiddenLexerComment, antlr.TokenHiddenChannel
+		for l.pos \< len(l.src) && !at("\n") {
+			l.pos++
+		}
+	case at(";"):
+		l.pos++
+	default:
+		for l.pos \< len(l.src) && l.src[l.pos] >= 'a' && l.src[l.pos] \<= 'z' {
+			l.pos++
+		}
+	}
+	t := antlr.NewCommonToken(&antlr.TokenSourceCharStreamPair{}, ttype, channel, start, l.pos-1)
+	t.SetText(string(l.src[start:l.pos]))
+	return t
+}
+
+// tokenStream hides the type of a CommonTokenStream, so that the
+// helpers walk the stream themselves.
+type tokenStream struct {
+	antlr.TokenStream
+}
+
+func TestHiddenTokens(t *testing.T) {
+	tests := []struct {
+		src, at    string // at is the text of the token looked at
+		hidden     []string
+		terminator bool // a line terminator token is among hidden
+		newline    bool
+	}{
+		{"a", "a", nil, false, false},
+		{"a b", "b", []string{" "}, false, false},
+		{"a\nb", "b", []string{"\n"}, true, true},
+		{"a /*x*/ /*y*/ \n b", "b", []string{" ", "/*x*/", " ", "/*y*/", " ", "\n", " "}, true, true},
+		{"a /*x*/\n/*y*/ b", "b", []string{" ", "/*x*/", "\n", "/*y*/", " "}, true, true},
+		{"a /*x\n*/ b", "b", []string{" ", "/*x\n*/", " "}, false, true},
+		{"a /*x\u2029*/ b", "b", []string{" ", "/*x\u2029*/", " "}, false, true},
+		{"a // x\n\tb", "b", []string{" ", "// x", "\n", "\t"}, true, true},
+		{"a\u2028b", "b", []string{"\u2028"}, true, true},
+		{"a /*x*/ b", "b", []string{" ", "/*x*/", " "}, false, false},
+		{"a;\nb", ";", nil, false, false},
+		{"\n a", "a", []string{"\n", " "}, true, true},
+	}
+	for _, test := range tests {
+		tokens := antlr.NewCommonTokenStream(newHiddenLexer(test.src), antlr.TokenDefaultChannel)
+		tokens.Fill()
+		index := -1
+		for _, tok := range tokens.GetAllTokens() {
+			if tok.GetChannel() == antlr.TokenDefaultChannel && tok.GetText() == test.at {
+				index = tok.GetTokenIndex()
+				break
+			}
+		}
+		if index \< 0 {
+			t.Fatalf("%q: no token %q", test.src, test.at)
+		}
+		for _, stream := range []antlr.TokenStream{tokens, tokenStream{tokens}} {
+			var hidden []string
+			for _, tok := range hiddenTokensBefore(stream, index) {
+				hidden = append(hidden, tok.GetText())
+			}
+			if !reflect.DeepEqual(hidden, test.hidden) {
+				t.Errorf("%q: hidden tokens before %q are %q, want %q", test.src, test.at, hidden, test.hidden)
+			}
+			if got := hasHiddenToken(stream, index, hiddenLexerLineTerminator); got != test.terminator {
+				t.Errorf("%q: line terminator before %q is %v, want %v", test.src, test.at, got, test.terminator)
+			}
+			if got := newlineBefore(stream, index); got != test.newline {
+				t.Errorf("%q: newline before %q is %v, want %v", test.src, test.at, got, test.newline)
+			}
+		}
+	}
+}
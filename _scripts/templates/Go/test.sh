@@ -29,4 +29,13 @@ do
     fi
   fi
 done
+# Run the tests of the Go support code, and of the grammar's, if any.
+if [ "$err" = "0" ]
+then
+  go test ./antlr_resource || err=1
+fi
+if [ "$err" = "0" ] && ls parser/*_test.go > /dev/null 2>&1
+then
+  go test ./parser || err=1
+fi
 exit $err
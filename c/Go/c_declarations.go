@@ -0,0 +1,253 @@
+package parser
+
+import (
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// Position is a 1-based line and a 0-based column, as reported by
+// ANTLR tokens.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// DeclKind is the kind of a file scope declaration.
+type DeclKind int
+
+const (
+	FuncDecl   DeclKind = iota // function, defined or declared
+	VarDecl                    // object
+	TypeDecl                   // typedef name
+	StructDecl                 // struct tag
+	UnionDecl                  // union tag
+	EnumDecl                   // enum tag
+	ConstDecl                  // enumeration constant
+)
+
+var declKinds = [...]string{"func", "var", "type", "struct", "union", "enum", "const"}
+
+func (k DeclKind) String() string {
+	return declKinds[k]
+}
+
+// Declaration is a name declared at file scope. Struct, union and enum
+// tags live in a name space of their own, the other kinds share the
+// ordinary one.
+type Declaration struct {
+	Name string
+	Kind DeclKind
+	Pos  Position
+	Decl antlr.ParserRuleContext
+}
+
+// FileDeclarations returns the names declared at file scope by a C
+// translation unit, in order. A name declared twice, such as a
+// function with a prototype, is returned once at its first
+// declaration. Tags declared in function bodies are left out.
+//
+// Without a symbol table the grammar cannot tell a typedef name from
+// a declarator, and may read the last name of "unsigned long n;" or
+// "typedef struct s s_t;" as a type specifier. A declaration without
+// declarators that ends with such a name after another type specifier
+// is taken to declare it.
+func FileDeclarations(source *CSource) []Declaration {
+	c := &declCollector{seen: map[declKey]bool{}}
+	antlr.ParseTreeWalkerDefault.Walk(c, source.Tree)
+	return c.decls
+}
+
+// ParsePreamble parses src, the C code of a cgo preamble, and calls
+// declare for every declaration of FileDeclarations and report for
+// every syntax error. It is the CParser of CheckCgo in the Go
+// grammar's package (golang/Go/cgo.go):
+//
+//	file := golang.CheckCgo(source, c.ParsePreamble)
+func ParsePreamble(name, src string, declare func(name, kind string, line, column int), report func(line, column int, msg string)) {
+	source := ParseCString(name, src)
+	for _, d := range FileDeclarations(source) {
+		declare(d.Name, d.Kind.String(), d.Pos.Line, d.Pos.Column)
+	}
+	for _, e := range source.Errors {
+		report(e.Line, e.Column, e.Msg)
+	}
+}
+
+type declKey struct {
+	name string
+	tag  bool
+}
+
+type declCollector struct {
+	BaseCListener
+
+	decls  []Declaration
+	seen   map[declKey]bool
+	bodies int // depth of compound statements
+}
+
+func (c *declCollector) add(ident antlr.TerminalNode, kind DeclKind, decl antlr.ParserRuleContext) {
+	if ident == nil {
+		return
+	}
+	key := declKey{ident.GetText(), kind == StructDecl || kind == UnionDecl || kind == EnumDecl}
+	if c.seen[key] {
+		return
+	}
+	c.seen[key] = true
+	t := ident.GetSymbol()
+	c.decls = append(c.decls, Declaration{key.name, kind, Position{t.GetLine(), t.GetColumn()}, decl})
+}
+
+func (c *declCollector) EnterCompoundStatement(ctx *CompoundStatementContext) { c.bodies++ }
+func (c *declCollector) ExitCompoundStatement(ctx *CompoundStatementContext)  { c.bodies-- }
+
+func (c *declCollector) EnterFunctionDefinition(ctx *FunctionDefinitionContext) {
+	if d, ok := ctx.Declarator().(*DeclaratorContext); ok {
+		ident, _ := declaratorName(d)
+		c.add(ident, FuncDecl, ctx)
+	}
+}
+
+func (c *declCollector) EnterDeclaration(ctx *DeclarationContext) {
+	if _, params := ctx.GetParent().(*DeclarationListContext); c.bodies > 0 || params {
+		// Local, or a parameter of an old style function definition
+		return
+	}
+	specs, ok := ctx.DeclarationSpecifiers().(*DeclarationSpecifiersContext)
+	if !ok {
+		return
+	}
+	typedef := false
+	for _, spec := range specs.AllDeclarationSpecifier() {
+		if sc, ok := spec.(*DeclarationSpecifierContext).StorageClassSpecifier().(*StorageClassSpecifierContext); ok && sc.Typedef() != nil {
+			typedef = true
+		}
+	}
+	list, ok := ctx.InitDeclaratorList().(*InitDeclaratorListContext)
+	if !ok {
+		if ident := trailingTypedefName(specs); ident != nil {
+			kind := VarDecl
+			if typedef {
+				kind = TypeDecl
+			}
+			c.add(ident, kind, ctx)
+		}
+		return
+	}
+	for _, init := range list.AllInitDeclarator() {
+		d, ok := init.(*InitDeclaratorContext).Declarator().(*DeclaratorContext)
+		if !ok {
+			continue
+		}
+		ident, function := declaratorName(d)
+		switch {
+		case typedef:
+			c.add(ident, TypeDecl, ctx)
+		case function:
+			c.add(ident, FuncDecl, ctx)
+		default:
+			c.add(ident, VarDecl, ctx)
+		}
+	}
+}
+
+func (c *declCollector) EnterStructOrUnionSpecifier(ctx *StructOrUnionSpecifierContext) {
+	if c.bodies > 0 {
+		return
+	}
+	kind := StructDecl
+	if su, ok := ctx.StructOrUnion().(*StructOrUnionContext); ok && su.Union() != nil {
+		kind = UnionDecl
+	}
+	c.add(ctx.Identifier(), kind, ctx)
+}
+
+func (c *declCollector) EnterEnumSpecifier(ctx *EnumSpecifierContext) {
+	if c.bodies > 0 {
+		return
+	}
+	c.add(ctx.Identifier(), EnumDecl, ctx)
+}
+
+func (c *declCollector) EnterEnumerator(ctx *EnumeratorContext) {
+	if c.bodies > 0 {
+		return
+	}
+	if ec, ok := ctx.EnumerationConstant().(*EnumerationConstantContext); ok {
+		c.add(ec.Identifier(), ConstDecl, ctx)
+	}
+}
+
+// trailingTypedefName returns the last declaration specifier if it is
+// a typedef name following another type specifier.
+func trailingTypedefName(specs *DeclarationSpecifiersContext) antlr.TerminalNode {
+	all := specs.AllDeclarationSpecifier()
+	if len(all) < 2 {
+		return nil
+	}
+	last, ok := all[len(all)-1].(*DeclarationSpecifierContext).TypeSpecifier().(*TypeSpecifierContext)
+	if !ok {
+		return nil
+	}
+	name, ok := last.TypedefName().(*TypedefNameContext)
+	if !ok {
+		return nil
+	}
+	for _, spec := range all[:len(all)-1] {
+		if spec.(*DeclarationSpecifierContext).TypeSpecifier() != nil {
+			return name.Identifier()
+		}
+	}
+	return nil
+}
+
+// derivation is the first type derivation applied to a declared name,
+// reading the declarator from the name outwards.
+type derivation int
+
+const (
+	noDerivation derivation = iota
+	functionDerivation
+	otherDerivation // pointer or array
+)
+
+// declaratorName returns the name a declarator declares and whether
+// it declares a function: in "int *f(void)" f is a function returning
+// a pointer, in "int (*f)(void)" a pointer to a function.
+func declaratorName(d *DeclaratorContext) (antlr.TerminalNode, bool) {
+	ident, first := declaratorDerivation(d)
+	return ident, first == functionDerivation
+}
+
+func declaratorDerivation(d *DeclaratorContext) (antlr.TerminalNode, derivation) {
+	dd, ok := d.DirectDeclarator().(*DirectDeclaratorContext)
+	if !ok {
+		return nil, noDerivation
+	}
+	ident, first := directDeclaratorDerivation(dd)
+	if first == noDerivation && d.Pointer() != nil {
+		first = otherDerivation
+	}
+	return ident, first
+}
+
+func directDeclaratorDerivation(dd *DirectDeclaratorContext) (antlr.TerminalNode, derivation) {
+	if inner, ok := dd.DirectDeclarator().(*DirectDeclaratorContext); ok {
+		ident, first := directDeclaratorDerivation(inner)
+		if first != noDerivation {
+			return ident, first
+		}
+		switch {
+		case dd.Pointer() != nil:
+			// (__cdecl *f)
+			return ident, otherDerivation
+		case dd.LeftParen() != nil:
+			return ident, functionDerivation
+		}
+		return ident, otherDerivation
+	}
+	if d, ok := dd.Declarator().(*DeclaratorContext); ok {
+		return declaratorDerivation(d)
+	}
+	return dd.Identifier(), noDerivation
+}
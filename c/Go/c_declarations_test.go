@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFileDeclarations(t *testing.T) {
+	source := ParseCString("decls.c", `#include <stdio.h>
+struct point { int x, y; };
+typedef struct point point_t;
+union value { int i; double d; };
+enum color { RED, GREEN = 2 };
+typedef unsigned long size;
+int count, *counts[4];
+int (*handler)(int);
+int *make(void);
+static int add(int a, int b);
+int add(int a, int b)
+{
+	struct local { int z; };
+	int sum = a + b;
+	return sum;
+}
+unsigned long total;
+`)
+	if len(source.Errors) > 0 {
+		t.Fatal(source.Errors)
+	}
+	var got []string
+	for _, d := range FileDeclarations(source) {
+		got = append(got, fmt.Sprintf("%s %s %d:%d", d.Kind, d.Name, d.Pos.Line, d.Pos.Column))
+	}
+	want := []string{
+		"struct point 2:7",
+		"type point_t 3:21",
+		"union value 4:6",
+		"enum color 5:5",
+		"const RED 5:13",
+		"const GREEN 5:18",
+		"type size 6:22",
+		"var count 7:4",
+		"var counts 7:12",
+		"var handler 8:6",
+		"func make 9:5",
+		"func add 10:11",
+		"var total 17:14",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got declarations\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestParsePreamble(t *testing.T) {
+	var got []string
+	ParsePreamble("p.go", "\nstruct point { int x; };\nint f(void) { return ; }\nint g(\n", func(name, kind string, line, column int) {
+		got = append(got, fmt.Sprintf("%s %s %d:%d", kind, name, line, column))
+	}, func(line, column int, msg string) {
+		got = append(got, fmt.Sprintf("error %d:%d", line, column))
+	})
+	want := []string{
+		"struct point 2:7",
+		"func f 3:4",
+		"error 5:0",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
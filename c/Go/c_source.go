@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// SyntaxError is a lexer or parser error reported while parsing
+// a C source file.
+type SyntaxError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e SyntaxError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Msg)
+}
+
+// syntaxErrorCollector records syntax errors instead of printing
+// them to the console.
+type syntaxErrorCollector struct {
+	*antlr.DefaultErrorListener
+
+	errors []SyntaxError
+}
+
+func (c *syntaxErrorCollector) SyntaxError(recognizer antlr.Recognizer, offendingSymbol interface{}, line, column int, msg string, e antlr.RecognitionException) {
+	c.errors = append(c.errors, SyntaxError{line, column, msg})
+}
+
+// CSource is a C source file parsed with CParser. The lexer skips
+// comments, white space and most preprocessor lines, so the token
+// stream only holds the tokens of the tree.
+type CSource struct {
+	Name   string
+	Tokens *antlr.CommonTokenStream
+	Tree   *CompilationUnitContext
+	Errors []SyntaxError
+}
+
+// ParseCSource parses input as a C source file. Syntax errors do
+// not stop the parse, they are collected in Errors and the tree
+// holds whatever the parser recovered.
+func ParseCSource(name string, input antlr.CharStream) *CSource {
+	errors := &syntaxErrorCollector{DefaultErrorListener: antlr.NewDefaultErrorListener()}
+
+	lexer := NewCLexer(input)
+	lexer.RemoveErrorListeners()
+	lexer.AddErrorListener(errors)
+
+	tokens := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
+	p := NewCParser(tokens)
+	p.RemoveErrorListeners()
+	p.AddErrorListener(errors)
+
+	tree := p.CompilationUnit().(*CompilationUnitContext)
+	return &CSource{name, tokens, tree, errors.errors}
+}
+
+// ParseCFile reads and parses the C source file at path.
+func ParseCFile(path string) (*CSource, error) {
+	input, err := antlr.NewFileStream(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCSource(path, input), nil
+}
+
+// ParseCString parses src as a C source file called name.
+func ParseCString(name, src string) *CSource {
+	return ParseCSource(name, antlr.NewInputStream(src))
+}
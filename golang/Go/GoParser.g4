@@ -59,11 +59,22 @@ expressionList: expression (COMMA expression)*;
 
 typeDecl: TYPE (typeSpec | L_PAREN (typeSpec eos)* R_PAREN);
 
-typeSpec: IDENTIFIER ASSIGN? type_;
+typeSpec: IDENTIFIER typeParameters? ASSIGN? type_;
+
+// Type parameters
+
+typeParameters:
+	L_BRACKET typeParameterDecl (COMMA typeParameterDecl)* COMMA? R_BRACKET;
+
+typeParameterDecl: identifierList typeElement;
+
+typeElement: typeTerm (OR typeTerm)*;
+
+typeTerm: TILDE? type_;
 
 // Function declarations
 
-functionDecl: FUNC IDENTIFIER (signature block?);
+functionDecl: FUNC IDENTIFIER typeParameters? (signature block?);
 
 methodDecl: FUNC receiver IDENTIFIER ( signature block?);
 
@@ -79,7 +90,9 @@ varSpec:
 
 block: L_CURLY statementList? R_CURLY;
 
-statementList: (statement eos)+;
+// The lexer inserts no EOS after a semicolon, so the semicolons of empty
+// statements, as in "{ ;\n", are matched before the next statement.
+statementList: (SEMI* statement eos)+;
 
 statement:
 	declaration
@@ -199,7 +212,9 @@ rangeClause: (
 
 goStmt: GO expression;
 
-type_: typeName | typeLit | L_PAREN type_ R_PAREN;
+type_: typeName typeArgs? | typeLit | L_PAREN type_ R_PAREN;
+
+typeArgs: L_BRACKET typeList COMMA? R_BRACKET;
 
 typeName: qualifiedIdent | IDENTIFIER;
 
@@ -222,7 +237,7 @@ elementType: type_;
 pointerType: STAR type_;
 
 interfaceType:
-	INTERFACE L_CURLY ((methodSpec | typeName) eos)* R_CURLY;
+	INTERFACE L_CURLY ((methodSpec | typeElement) eos)* R_CURLY;
 
 sliceType: L_BRACKET R_BRACKET elementType;
 
@@ -231,15 +246,11 @@ mapType: MAP L_BRACKET type_ R_BRACKET elementType;
 
 channelType: (CHAN | CHAN RECEIVE | RECEIVE CHAN) elementType;
 
-methodSpec:
-	{ p.noTerminatorAfterParams(2) }? IDENTIFIER parameters result
-	| IDENTIFIER parameters;
+methodSpec: IDENTIFIER parameters result?;
 
 functionType: FUNC signature;
 
-signature:
-	{ p.noTerminatorAfterParams(1) }? parameters result
-	| parameters;
+signature: parameters result?;
 
 result: parameters | type_;
 
@@ -298,7 +309,10 @@ unaryExpr:
 
 conversion: type_ L_PAREN expression COMMA? R_PAREN;
 
-operand: literal | operandName | L_PAREN expression R_PAREN;
+// Brackets after a name hold an index, as in a[i], whenever they may:
+// the type arguments are not greedy. Type arguments that cannot be an
+// index, as in f[int, string] or f[[]int], still match typeArgs.
+operand: literal | operandName typeArgs?? | L_PAREN expression R_PAREN;
 
 literal: basicLit | compositeLit | functionLit;
 
@@ -330,7 +344,7 @@ literalType:
 	| L_BRACKET ELLIPSIS R_BRACKET elementType
 	| sliceType
 	| mapType
-	| typeName;
+	| typeName typeArgs?;
 
 literalValue: L_CURLY (elementList COMMA?)? R_CURLY;
 
@@ -344,14 +358,11 @@ element: expression | literalValue;
 
 structType: STRUCT L_CURLY (fieldDecl eos)* R_CURLY;
 
-fieldDecl: (
-		{ p.noTerminatorBetween(2) }? identifierList type_
-		| embeddedField
-	) tag = string_?;
+fieldDecl: (identifierList type_ | embeddedField) tag = string_?;
 
 string_: RAW_STRING_LIT | INTERPRETED_STRING_LIT;
 
-embeddedField: STAR? typeName;
+embeddedField: STAR? typeName typeArgs?;
 
 functionLit: FUNC signature block; // function
 
@@ -376,10 +387,8 @@ methodExpr: receiverType DOT IDENTIFIER;
 
 receiverType: type_;
 
-eos:
-	SEMI
-	| EOF
-	| { p.lineTerminatorAhead() }?
-	| { p.checkPreviousTokenText("}") }?
-	| { p.checkPreviousTokenText(")") }?;
+// GoLexerBase emits EOS for the semicolons the specification inserts
+// at line ends, so only the omitted semicolon before a closing ")" or
+// "}" is left to a predicate.
+eos: SEMI | EOS | EOF | { p.closingBracket() }?;
 
@@ -0,0 +1,138 @@
+package parser
+
+import (
+	"go/build"
+	"go/build/constraint"
+	"path/filepath"
+	"strings"
+)
+
+// BuildTarget is the platform and tag set that build constraints and
+// file name suffixes are evaluated against, without running the go
+// tool. The fields mean the same as in go/build's Context.
+type BuildTarget struct {
+	GOOS        string
+	GOARCH      string
+	Compiler    string
+	CgoEnabled  bool
+	BuildTags   []string
+	ReleaseTags []string
+}
+
+// DefaultBuildTarget returns the target of go/build's default context,
+// i.e. the host platform unless GOOS and GOARCH are set.
+func DefaultBuildTarget() BuildTarget {
+	d := build.Default
+	return BuildTarget{d.GOOS, d.GOARCH, d.Compiler, d.CgoEnabled, d.BuildTags, d.ReleaseTags}
+}
+
+// HasTag returns true if tag is satisfied by the target. As with the go
+// tool, "unix" matches Unix-like systems, "linux" matches android,
+// "darwin" matches ios and "solaris" matches illumos.
+func (t BuildTarget) HasTag(tag string) bool {
+	switch {
+	case tag == t.GOOS || tag == t.GOARCH || tag == t.Compiler:
+		return true
+	case tag == "cgo":
+		return t.CgoEnabled
+	case tag == "unix":
+		return unixOS[t.GOOS]
+	case tag == "linux" && t.GOOS == "android",
+		tag == "darwin" && t.GOOS == "ios",
+		tag == "solaris" && t.GOOS == "illumos":
+		return true
+	}
+	for _, tags := range [][]string{t.BuildTags, t.ReleaseTags} {
+		for _, have := range tags {
+			if have == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Eval evaluates a build constraint expression for the target.
+func (t BuildTarget) Eval(expr constraint.Expr) bool {
+	return expr.Eval(t.HasTag)
+}
+
+// MatchFile returns true if the file of source is built for the target:
+// its name suffix must match, and so must its build constraints. A
+// //go:build line takes precedence over // +build lines, which must all
+// be satisfied otherwise. As for go build, a second //go:build line is
+// an error.
+func (t BuildTarget) MatchFile(source *GoSource) (bool, error) {
+	if !t.MatchFileName(source.Name) {
+		return false, nil
+	}
+	constraints, err := FileBuildConstraints(source)
+	if err != nil {
+		return false, err
+	}
+	goBuild := false
+	for _, c := range constraints {
+		if c.Plus {
+			continue
+		}
+		if goBuild {
+			return false, SyntaxError{c.Pos.Line, c.Pos.Column, "multiple //go:build comments"}
+		}
+		goBuild = true
+	}
+	for _, c := range constraints {
+		if c.Plus != goBuild && !t.Eval(c.Expr) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// MatchFileName returns true unless the name of a Go file ends in an
+// _GOOS, _GOARCH or _GOOS_GOARCH suffix (before an optional _test) for
+// another platform.
+func (t BuildTarget) MatchFileName(name string) bool {
+	name = strings.TrimSuffix(filepath.Base(name), ".go")
+	if i := strings.Index(name, "_"); i >= 0 {
+		name = name[i:]
+	} else {
+		return true
+	}
+	parts := strings.Split(name, "_")
+	if n := len(parts); n > 0 && parts[n-1] == "test" {
+		parts = parts[:n-1]
+	}
+	n := len(parts)
+	if n >= 2 && knownOS[parts[n-2]] && knownArch[parts[n-1]] {
+		return t.HasTag(parts[n-2]) && t.HasTag(parts[n-1])
+	}
+	if n >= 1 && (knownOS[parts[n-1]] || knownArch[parts[n-1]]) {
+		return t.HasTag(parts[n-1])
+	}
+	return true
+}
+
+// The lists of go/build's syslist.go.
+
+var knownOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true,
+	"freebsd": true, "hurd": true, "illumos": true, "ios": true,
+	"js": true, "linux": true, "nacl": true, "netbsd": true,
+	"openbsd": true, "plan9": true, "solaris": true, "wasip1": true,
+	"windows": true, "zos": true,
+}
+
+var unixOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true,
+	"freebsd": true, "hurd": true, "illumos": true, "ios": true,
+	"linux": true, "netbsd": true, "openbsd": true, "solaris": true,
+}
+
+var knownArch = map[string]bool{
+	"386": true, "amd64": true, "amd64p32": true, "arm": true,
+	"armbe": true, "arm64": true, "arm64be": true, "loong64": true,
+	"mips": true, "mipsle": true, "mips64": true, "mips64le": true,
+	"mips64p32": true, "mips64p32le": true, "ppc": true, "ppc64": true,
+	"ppc64le": true, "riscv": true, "riscv64": true, "s390": true,
+	"s390x": true, "sparc": true, "sparc64": true, "wasm": true,
+}
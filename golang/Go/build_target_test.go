@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"go/build"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchFile(t *testing.T) {
+	linux := BuildTarget{GOOS: "linux", GOARCH: "amd64", Compiler: "gc", CgoEnabled: true,
+		BuildTags: []string{"foo"}, ReleaseTags: []string{"go1.17", "go1.18"}}
+	ios := BuildTarget{GOOS: "ios", GOARCH: "arm64", Compiler: "gc"}
+	tests := []struct {
+		target  BuildTarget
+		name    string
+		src     string
+		want    bool
+		wantErr bool
+	}{
+		{linux, "a.go", "package p\n", true, false},
+		{linux, "a_linux.go", "package p\n", true, false},
+		{linux, "a_windows.go", "package p\n", false, false},
+		{linux, "a_linux_amd64.go", "package p\n", true, false},
+		{linux, "a_linux_arm64.go", "package p\n", false, false},
+		{linux, "a_windows_test.go", "package p\n", false, false},
+		{linux, "a_amd64_test.go", "package p\n", true, false},
+		{linux, "linux.go", "package p\n", true, false},
+		{linux, "a_foo.go", "package p\n", true, false},
+		{linux, "a.go", "//go:build linux\n\npackage p\n", true, false},
+		{linux, "a.go", "//go:build windows\n\npackage p\n", false, false},
+		{linux, "a.go", "//go:build unix && go1.18 && foo\n\npackage p\n", true, false},
+		{linux, "a.go", "//go:build cgo && !gccgo\npackage p\n", true, false},
+		{linux, "a.go", "// Copyright\n\n//go:build windows\n\n// Package p.\npackage p\n", false, false},
+		{linux, "a.go", "// +build linux,!cgo\n\npackage p\n", false, false},
+		{linux, "a.go", "// +build foo\n// +build amd64\n\npackage p\n", true, false},
+		{linux, "a.go", "// +build foo\n// +build arm\n\npackage p\n", false, false},
+		{linux, "a.go", "// +build windows\npackage p\n", true, false},
+		{linux, "a.go", "//go:build !foo\n// +build foo\n\npackage p\n", false, false},
+		{linux, "a.go", "//go:build foo\n// +build !foo\n\npackage p\n", true, false},
+		{linux, "a.go", "//go:build linux\n//go:build windows\n\npackage p\n", false, true},
+		{linux, "a.go", "//go:build linux\n\n//go:build linux\npackage p\n", false, true},
+		{linux, "a.go", "//go:build linux &&\n\npackage p\n", false, true},
+		{ios, "a_darwin.go", "package p\n", true, false},
+		{ios, "a.go", "//go:build darwin && unix && !cgo\n\npackage p\n", true, false},
+	}
+	dir := t.TempDir()
+	for _, test := range tests {
+		ok, err := test.target.MatchFile(ParseGoString(test.name, test.src))
+		if ok != test.want || (err != nil) != test.wantErr {
+			t.Errorf("%s %q: got %v, %v, want %v, error %v", test.name, test.src, ok, err, test.want, test.wantErr)
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, test.name), []byte(test.src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		ctx := build.Context{GOOS: test.target.GOOS, GOARCH: test.target.GOARCH, Compiler: test.target.Compiler,
+			CgoEnabled: test.target.CgoEnabled, BuildTags: test.target.BuildTags, ReleaseTags: test.target.ReleaseTags}
+		if goOK, goErr := ctx.MatchFile(dir, test.name); goOK != ok || (goErr != nil) != (err != nil) {
+			t.Errorf("%s %q: got %v, %v, go/build %v, %v", test.name, test.src, ok, err, goOK, goErr)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// CgoPreamble is the C code in the doc comment of an import "C"
+// declaration. Text is the code as cgo reads it: the comment markers
+// are removed, the comments of the group are concatenated, and the
+// #cgo lines, which cgo handles itself, are blanked.
+type CgoPreamble struct {
+	Import     *ImportSpecContext
+	Comments   *CommentGroup
+	Text       string
+	Directives []Directive // the #cgo lines, named "cgo"
+
+	segments []preambleSegment
+}
+
+// preambleSegment maps a run of preamble text to where it starts in
+// the Go file. There is one at every line of the preamble and at the
+// start of every comment.
+type preambleSegment struct {
+	preamble Position
+	source   Position
+}
+
+// CgoPreambles returns the preambles of the cgo imports of a file, in
+// order. As with cgo, the comment is the doc comment of the import
+// spec, or of the import declaration if it holds a single spec.
+func CgoPreambles(source *GoSource) []*CgoPreamble {
+	var preambles []*CgoPreamble
+	for _, decl := range source.Tree.AllImportDecl() {
+		decl := decl.(*ImportDeclContext)
+		specs := decl.AllImportSpec()
+		for _, spec := range specs {
+			spec := spec.(*ImportSpecContext)
+			if ImportPath(spec) != "C" {
+				continue
+			}
+			doc := DocComment(source.Tokens, spec)
+			if doc == nil && len(specs) == 1 {
+				doc = DocComment(source.Tokens, decl)
+			}
+			if doc != nil {
+				preambles = append(preambles, newCgoPreamble(spec, doc))
+			}
+		}
+	}
+	return preambles
+}
+
+func newCgoPreamble(spec *ImportSpecContext, doc *CommentGroup) *CgoPreamble {
+	p := &CgoPreamble{Import: spec, Comments: doc}
+	var text strings.Builder
+	at := Position{1, 0}
+	for _, c := range doc.List {
+		body := c.GetText()
+		if strings.HasPrefix(body, "//") {
+			body = body[2:] + "\n"
+		} else {
+			body = body[2 : len(body)-2]
+		}
+		src := Position{c.GetLine(), c.GetColumn() + 2}
+		p.segments = append(p.segments, preambleSegment{at, src})
+		for _, r := range body {
+			if r == '\r' {
+				continue
+			}
+			text.WriteRune(r)
+			if r != '\n' {
+				at.Column++
+				continue
+			}
+			at = Position{at.Line + 1, 0}
+			src = Position{src.Line + 1, 0}
+			if strings.HasPrefix(c.GetText(), "/*") {
+				p.segments = append(p.segments, preambleSegment{at, src})
+			}
+		}
+	}
+	lines := strings.Split(text.String(), "\n")
+	for i, line := range lines {
+		if m := cgoLine.FindStringSubmatchIndex(line); m != nil {
+			pos, _ := p.SourcePos(Position{i + 1, len([]rune(line[:m[2]]))})
+			p.Directives = append(p.Directives, Directive{Name: "cgo", Args: strings.TrimSpace(line[m[3]:]), Pos: pos})
+			lines[i] = ""
+		}
+	}
+	p.Text = strings.Join(lines, "\n")
+	return p
+}
+
+// Same test as cgo's ProcessCgoDirectives.
+var cgoLine = regexp.MustCompile(`^\s*(#cgo)\s`)
+
+// SourcePos returns the position in the Go file of a position in the
+// preamble text, false if it is outside the text.
+func (p *CgoPreamble) SourcePos(pos Position) (Position, bool) {
+	var seg *preambleSegment
+	for i := range p.segments {
+		s := &p.segments[i]
+		if s.preamble.Line > pos.Line || s.preamble.Line == pos.Line && s.preamble.Column > pos.Column {
+			break
+		}
+		seg = s
+	}
+	if seg == nil || seg.preamble.Line != pos.Line {
+		return Position{}, false
+	}
+	return Position{seg.source.Line, seg.source.Column + pos.Column - seg.preamble.Column}, true
+}
+
+// CDeclaration is a name the C code of a preamble declares. Kind is
+// one of func, var, type, struct, union, enum, const (an enumeration
+// constant) or macro, and builtin for the names cgo itself provides.
+type CDeclaration struct {
+	Name string
+	Kind string
+	Pos  Position
+}
+
+// CParser parses the C code of a preamble, calls declare for every
+// name it declares at file scope and report for every syntax error,
+// with positions in src. The signature only has predeclared types, so
+// that ParsePreamble of the Go package of the repository's C grammar,
+// which cannot import this one, is a CParser as it is.
+type CParser func(name, src string, declare func(name, kind string, line, column int), report func(line, column int, msg string))
+
+// Macros returns the object and function-like macros defined by the
+// preamble, which the C grammar skips, with their positions in the Go
+// file.
+func (p *CgoPreamble) Macros() []CDeclaration {
+	var macros []CDeclaration
+	for i, line := range strings.Split(p.Text, "\n") {
+		if m := defineLine.FindStringSubmatchIndex(line); m != nil {
+			pos, _ := p.SourcePos(Position{i + 1, len([]rune(line[:m[2]]))})
+			macros = append(macros, CDeclaration{line[m[2]:m[3]], "macro", pos})
+		}
+	}
+	return macros
+}
+
+var defineLine = regexp.MustCompile(`^\s*#\s*define\s+([A-Za-z_][A-Za-z0-9_]*)`)
+
+// cCode returns the preamble text with every preprocessor line blanked,
+// continuation lines included, for the C grammar which only skips
+// #include, #define and #pragma lines. Both branches of conditionals
+// are parsed.
+func (p *CgoPreamble) cCode() string {
+	lines := strings.Split(p.Text, "\n")
+	directive := false
+	for i, line := range lines {
+		if directive || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			directive = strings.HasSuffix(strings.TrimRight(line, " \t\r"), `\`)
+			lines[i] = ""
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CgoReference is a C.name selector in Go code. Decl is the declaration
+// of the name, nil if neither the preamble nor cgo declare it.
+type CgoReference struct {
+	Name  string
+	Token antlr.Token // the name
+	Decl  *CDeclaration
+}
+
+// Pos returns the position of the name.
+func (r CgoReference) Pos() Position {
+	return Position{r.Token.GetLine(), r.Token.GetColumn()}
+}
+
+// CgoFile is the cross-language view of a Go file using cgo: the C
+// declarations of its preambles and the C names its Go code refers to.
+// The positions of Declarations and Errors are in the Go file.
+type CgoFile struct {
+	Preambles    []*CgoPreamble
+	Declarations []CDeclaration
+	References   []CgoReference
+	Errors       []SyntaxError
+}
+
+// Undeclared returns the references to names that are not declared in
+// the preambles. Names from included headers are among them, since
+// #include lines are not followed.
+func (f *CgoFile) Undeclared() []CgoReference {
+	var refs []CgoReference
+	for _, r := range f.References {
+		if r.Decl == nil {
+			refs = append(refs, r)
+		}
+	}
+	return refs
+}
+
+// CheckCgo parses the preambles of source with parse and binds the C
+// references of the file to their declarations. C stands for the cgo
+// import only where it is not shadowed, as resolved by ResolvePackage.
+// It returns nil if the file does not import "C".
+func CheckCgo(source *GoSource, parse CParser) *CgoFile {
+	var cgoImport bool
+	for _, spec := range ImportSpecs(source.Tree) {
+		cgoImport = cgoImport || ImportPath(spec) == "C"
+	}
+	if !cgoImport {
+		return nil
+	}
+	f := &CgoFile{Preambles: CgoPreambles(source)}
+	for _, p := range f.Preambles {
+		var decls []CDeclaration
+		var errs []SyntaxError
+		parse(source.Name, p.cCode(), func(name, kind string, line, column int) {
+			decls = append(decls, CDeclaration{name, kind, Position{line, column}})
+		}, func(line, column int, msg string) {
+			errs = append(errs, SyntaxError{line, column, msg})
+		})
+		for _, d := range append(decls, p.Macros()...) {
+			if d.Kind != "macro" {
+				d.Pos, _ = p.SourcePos(d.Pos)
+			}
+			f.Declarations = append(f.Declarations, d)
+		}
+		for _, e := range errs {
+			pos, _ := p.SourcePos(Position{e.Line, e.Column})
+			f.Errors = append(f.Errors, SyntaxError{pos.Line, pos.Column, e.Msg})
+		}
+	}
+
+	ordinary := map[string]*CDeclaration{}
+	tags := map[string]*CDeclaration{}
+	for i := range f.Declarations {
+		d := &f.Declarations[i]
+		names := ordinary
+		if d.Kind == "struct" || d.Kind == "union" || d.Kind == "enum" {
+			names = tags
+		}
+		if names[d.Name] == nil {
+			names[d.Name] = d
+		}
+	}
+	lookup := func(name string) *CDeclaration {
+		for _, kind := range []string{"struct", "union", "enum"} {
+			if tag := strings.TrimPrefix(name, kind+"_"); tag != name {
+				if d := tags[tag]; d != nil && d.Kind == kind {
+					return d
+				}
+				return nil
+			}
+		}
+		if d := ordinary[name]; d != nil {
+			return d
+		}
+		if cgoBuiltins[name] {
+			return &CDeclaration{Name: name, Kind: "builtin"}
+		}
+		return nil
+	}
+
+	info := ResolvePackage(source)
+	var tokens []antlr.Token
+	for _, t := range source.Tokens.GetAllTokens() {
+		if t.GetChannel() == antlr.TokenDefaultChannel {
+			tokens = append(tokens, t)
+		}
+	}
+	for i := 0; i+2 < len(tokens); i++ {
+		c, dot, name := tokens[i], tokens[i+1], tokens[i+2]
+		if c.GetTokenType() != GoLexerIDENTIFIER || dot.GetTokenType() != GoLexerDOT || name.GetTokenType() != GoLexerIDENTIFIER {
+			continue
+		}
+		sym := info.Uses[c]
+		if sym == nil || sym.Kind != PackageSymbol {
+			continue
+		}
+		if spec, ok := sym.Decl.(*ImportSpecContext); !ok || ImportPath(spec) != "C" {
+			continue
+		}
+		ref := CgoReference{Name: name.GetText(), Token: name}
+		if sizeof := strings.TrimPrefix(ref.Name, "sizeof_"); sizeof != ref.Name {
+			ref.Decl = lookup(sizeof)
+		} else {
+			ref.Decl = lookup(ref.Name)
+		}
+		f.References = append(f.References, ref)
+	}
+	return f
+}
+
+// cgoBuiltins are the names cgo declares whatever the preamble: the
+// numeric types, the types of <stddef.h> and the conversion helpers.
+var cgoBuiltins = map[string]bool{
+	"char": true, "schar": true, "uchar": true, "short": true, "ushort": true,
+	"int": true, "uint": true, "long": true, "ulong": true,
+	"longlong": true, "ulonglong": true, "float": true, "double": true,
+	"complexfloat": true, "complexdouble": true,
+	"size_t": true, "ptrdiff_t": true,
+	"CString": true, "CBytes": true, "GoString": true, "GoStringN": true,
+	"GoBytes": true, "malloc": true,
+}
@@ -0,0 +1,181 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const cgoSrc = `package p
+
+/*
+#cgo CFLAGS: -DX=1
+#include <stdlib.h>
+
+struct point { int x, y; };
+typedef int count_t;
+*/
+// #define LIMIT 10
+// enum color { RED };
+// int add(int a, int b) { return a + b; }
+import "C"
+
+func f(n C.count_t) C.int {
+	var p C.struct_point
+	_ = C.sizeof_struct_point
+	_ = C.struct_color
+	_ = C.enum_color
+	_ = C.LIMIT + C.RED
+	C.free(nil)
+	s := C.CString("x")
+	_ = s
+	return C.add(p.x, n)
+}
+
+func g(C struct{ x int }) int {
+	return C.x
+}
+`
+
+// stubCParser declares the names of the cgoSrc preamble where they
+// first appear in src, and reports an error at every typedef, which
+// it does not know.
+func stubCParser(name, src string, declare func(name, kind string, line, column int), report func(line, column int, msg string)) {
+	pos := func(offset int) (int, int) {
+		line := strings.Count(src[:offset], "\n") + 1
+		return line, offset - strings.LastIndex(src[:offset], "\n") - 1
+	}
+	for _, d := range []struct{ name, kind string }{
+		{"point", "struct"}, {"count_t", "type"}, {"color", "enum"}, {"RED", "const"}, {"add", "func"},
+	} {
+		if loc := regexp.MustCompile(`\b` + d.name + `\b`).FindStringIndex(src); loc != nil {
+			line, column := pos(loc[0])
+			declare(d.name, d.kind, line, column)
+		}
+	}
+	for _, loc := range regexp.MustCompile(`\btypedef\b`).FindAllStringIndex(src, -1) {
+		line, column := pos(loc[0])
+		report(line, column, "unexpected typedef")
+	}
+}
+
+func TestCgoPreamble(t *testing.T) {
+	source := ParseGoString("p.go", cgoSrc)
+	if len(source.Errors) > 0 {
+		t.Fatal(source.Errors)
+	}
+	preambles := CgoPreambles(source)
+	if len(preambles) != 1 {
+		t.Fatalf("got %d preambles, want 1", len(preambles))
+	}
+	p := preambles[0]
+
+	lines := strings.Split(p.Text, "\n")
+	want := []string{
+		"",
+		"", // #cgo line
+		"#include <stdlib.h>",
+		"",
+		"struct point { int x, y; };",
+		"typedef int count_t;",
+		" #define LIMIT 10",
+		" enum color { RED };",
+		" int add(int a, int b) { return a + b; }",
+		"",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got text\n%s\nwant\n%s", p.Text, strings.Join(want, "\n"))
+	}
+	if len(p.Directives) != 1 || p.Directives[0] != (Directive{Name: "cgo", Args: "CFLAGS: -DX=1", Pos: Position{4, 0}}) {
+		t.Errorf("got directives %v", p.Directives)
+	}
+
+	// The lines of the /* */ comment map one to one, the // comments
+	// map from their start.
+	positions := []struct {
+		preamble, source Position
+		ok               bool
+	}{
+		{Position{1, 0}, Position{3, 2}, true},
+		{Position{3, 1}, Position{5, 1}, true},
+		{Position{5, 7}, Position{7, 7}, true},
+		{Position{7, 0}, Position{10, 2}, true},
+		{Position{7, 9}, Position{10, 11}, true},
+		{Position{8, 15}, Position{11, 17}, true},
+		{Position{9, 5}, Position{12, 7}, true},
+		{Position{11, 0}, Position{}, false},
+		{Position{0, 0}, Position{}, false},
+	}
+	for _, tt := range positions {
+		got, ok := p.SourcePos(tt.preamble)
+		if got != tt.source || ok != tt.ok {
+			t.Errorf("SourcePos(%v) = %v, %v, want %v, %v", tt.preamble, got, ok, tt.source, tt.ok)
+		}
+	}
+}
+
+func TestCheckCgo(t *testing.T) {
+	source := ParseGoString("p.go", cgoSrc)
+	f := CheckCgo(source, stubCParser)
+	if f == nil {
+		t.Fatal("CheckCgo returned nil for a cgo file")
+	}
+
+	var decls []string
+	for _, d := range f.Declarations {
+		decls = append(decls, fmt.Sprintf("%s %s %d:%d", d.Kind, d.Name, d.Pos.Line, d.Pos.Column))
+	}
+	wantDecls := []string{
+		"struct point 7:7",
+		"type count_t 8:12",
+		"enum color 11:8",
+		"const RED 11:16",
+		"func add 12:7",
+		"macro LIMIT 10:11",
+	}
+	if strings.Join(decls, "\n") != strings.Join(wantDecls, "\n") {
+		t.Errorf("got declarations\n%s\nwant\n%s", strings.Join(decls, "\n"), strings.Join(wantDecls, "\n"))
+	}
+	if len(f.Errors) != 1 || f.Errors[0] != (SyntaxError{8, 0, "unexpected typedef"}) {
+		t.Errorf("got errors %v", f.Errors)
+	}
+
+	var refs []string
+	for _, r := range f.References {
+		decl := "undeclared"
+		if r.Decl != nil {
+			decl = r.Decl.Kind + " " + r.Decl.Name
+		}
+		refs = append(refs, fmt.Sprintf("%d:%d %s: %s", r.Pos().Line, r.Pos().Column, r.Name, decl))
+	}
+	// C.x in g is a field of the parameter C.
+	wantRefs := []string{
+		"15:11 count_t: type count_t",
+		"15:22 int: builtin int",
+		"16:9 struct_point: struct point",
+		"17:7 sizeof_struct_point: struct point",
+		"18:7 struct_color: undeclared",
+		"19:7 enum_color: enum color",
+		"20:7 LIMIT: macro LIMIT",
+		"20:17 RED: const RED",
+		"21:3 free: undeclared",
+		"22:8 CString: builtin CString",
+		"24:10 add: func add",
+	}
+	if strings.Join(refs, "\n") != strings.Join(wantRefs, "\n") {
+		t.Errorf("got references\n%s\nwant\n%s", strings.Join(refs, "\n"), strings.Join(wantRefs, "\n"))
+	}
+
+	var undeclared []string
+	for _, r := range f.Undeclared() {
+		undeclared = append(undeclared, r.Name)
+	}
+	if got := strings.Join(undeclared, " "); got != "struct_color free" {
+		t.Errorf("Undeclared() = %s, want struct_color free", got)
+	}
+
+	if f := CheckCgo(ParseGoString("q.go", "package q\n\nimport \"fmt\"\n"), stubCParser); f != nil {
+		t.Errorf("CheckCgo of a file without cgo = %v, want nil", f)
+	}
+}
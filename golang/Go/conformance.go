@@ -0,0 +1,165 @@
+package parser
+
+import (
+	"fmt"
+	"go/build"
+	goparser "go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ConformanceResult compares how GoParser and the standard library's
+// go/parser handled one source file.
+type ConformanceResult struct {
+	Path string
+
+	// Errors reported by GoLexer and GoParser, nil if the file was accepted.
+	AntlrErrors []SyntaxError
+	AntlrTime   time.Duration
+
+	// Error returned by go/parser, nil if the file was accepted.
+	StdlibError error
+	StdlibTime  time.Duration
+}
+
+// AntlrAccepted returns true if GoParser parsed the file without errors.
+func (r *ConformanceResult) AntlrAccepted() bool {
+	return len(r.AntlrErrors) == 0
+}
+
+// StdlibAccepted returns true if go/parser parsed the file without errors.
+func (r *ConformanceResult) StdlibAccepted() bool {
+	return r.StdlibError == nil
+}
+
+// Mismatch returns true if one parser accepted the file and the other
+// rejected it.
+func (r *ConformanceResult) Mismatch() bool {
+	return r.AntlrAccepted() != r.StdlibAccepted()
+}
+
+// TimeRatio is the time GoParser took relative to go/parser.
+func (r *ConformanceResult) TimeRatio() float64 {
+	if r.StdlibTime <= 0 {
+		return 0
+	}
+	return float64(r.AntlrTime) / float64(r.StdlibTime)
+}
+
+// ConformanceReport holds the results for every file of a corpus.
+type ConformanceReport struct {
+	Root    string
+	Results []*ConformanceResult
+}
+
+// Mismatches returns the results where the two parsers disagree.
+func (r *ConformanceReport) Mismatches() []*ConformanceResult {
+	var mismatches []*ConformanceResult
+	for _, result := range r.Results {
+		if result.Mismatch() {
+			mismatches = append(mismatches, result)
+		}
+	}
+	return mismatches
+}
+
+// TimeRatio is the total time GoParser took relative to go/parser.
+func (r *ConformanceReport) TimeRatio() float64 {
+	var antlrTime, stdlibTime time.Duration
+	for _, result := range r.Results {
+		antlrTime += result.AntlrTime
+		stdlibTime += result.StdlibTime
+	}
+	if stdlibTime <= 0 {
+		return 0
+	}
+	return float64(antlrTime) / float64(stdlibTime)
+}
+
+// WriteTo writes a summary followed by one line per mismatch.
+func (r *ConformanceReport) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	mismatches := r.Mismatches()
+	fmt.Fprintf(&b, "%s: %d files, %d mismatches, time ratio %.2f\n",
+		r.Root, len(r.Results), len(mismatches), r.TimeRatio())
+	for _, m := range mismatches {
+		if m.AntlrAccepted() {
+			fmt.Fprintf(&b, "%s: accepted by GoParser, rejected by go/parser: %v\n", m.Path, m.StdlibError)
+		} else {
+			fmt.Fprintf(&b, "%s: rejected by GoParser, accepted by go/parser: %v\n", m.Path, m.AntlrErrors[0])
+		}
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// CompareFile parses the file at path with both parsers.
+func CompareFile(path string) (*ConformanceResult, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	result := &ConformanceResult{Path: path}
+
+	start := time.Now()
+	result.AntlrErrors = parseRecovering(path, string(src))
+	result.AntlrTime = time.Since(start)
+
+	start = time.Now()
+	_, result.StdlibError = goparser.ParseFile(token.NewFileSet(), path, src, goparser.SkipObjectResolution)
+	result.StdlibTime = time.Since(start)
+
+	return result, nil
+}
+
+// parseRecovering parses src with GoParser and turns a runtime panic
+// into a syntax error so that one bad file does not stop a corpus run.
+func parseRecovering(name, src string) (errors []SyntaxError) {
+	defer func() {
+		if r := recover(); r != nil {
+			errors = append(errors, SyntaxError{Msg: fmt.Sprintf("panic: %v", r)})
+		}
+	}()
+	return ParseGoString(name, src).Errors
+}
+
+// CompareCorpus parses every .go file below root with both parsers.
+// Directories named testdata are skipped, they hold intentionally
+// broken sources.
+func CompareCorpus(root string) (*ConformanceReport, error) {
+	report := &ConformanceReport{Root: root}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != root && info.Name() == "testdata" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".go" {
+			return nil
+		}
+		result, err := CompareFile(path)
+		if err != nil {
+			return err
+		}
+		report.Results = append(report.Results, result)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// CompareGoRoot runs CompareCorpus over the standard library sources
+// of the local Go installation.
+func CompareGoRoot() (*ConformanceReport, error) {
+	return CompareCorpus(filepath.Join(build.Default.GOROOT, "src"))
+}
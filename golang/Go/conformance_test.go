@@ -0,0 +1,51 @@
+package parser
+
+import (
+	"flag"
+	"strings"
+	"testing"
+)
+
+var goroot = flag.Bool("goroot", false, "compare GoParser with go/parser on the standard library sources")
+
+func TestCompareCorpus(t *testing.T) {
+	dir := examplesDir(t)
+	report, err := CompareCorpus(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Results) == 0 {
+		t.Fatalf("no files compared in %s", dir)
+	}
+	for _, m := range report.Mismatches() {
+		if m.AntlrAccepted() {
+			t.Errorf("%s: accepted by GoParser, rejected by go/parser: %v", m.Path, m.StdlibError)
+		} else {
+			t.Errorf("%s: rejected by GoParser, accepted by go/parser: %v", m.Path, m.AntlrErrors[0])
+		}
+	}
+	var b strings.Builder
+	if _, err := report.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(b.String(), dir+": ") {
+		t.Errorf("report starts with %q", b.String())
+	}
+}
+
+// TestCompareGoRoot takes minutes, it runs with go test -goroot.
+func TestCompareGoRoot(t *testing.T) {
+	if !*goroot {
+		t.Skip("run with -goroot")
+	}
+	report, err := CompareGoRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	report.WriteTo(&b)
+	t.Log(b.String())
+	if len(report.Mismatches()) > 0 {
+		t.Errorf("%d of %d files parsed differently", len(report.Mismatches()), len(report.Results))
+	}
+}
@@ -0,0 +1,19 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// examplesDir returns the examples directory of the grammar, seen from
+// the Go directory or from Generated/parser, where CI runs the tests.
+func examplesDir(t *testing.T) string {
+	for _, dir := range []string{"../examples", "../../examples"} {
+		if _, err := os.Stat(filepath.Join(dir, "example.go")); err == nil {
+			return dir
+		}
+	}
+	t.Skip("examples not found")
+	return ""
+}
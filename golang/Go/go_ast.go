@@ -0,0 +1,1421 @@
+package parser
+
+import (
+	"go/ast"
+	"go/token"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// ParseGoAST parses src with GoParser and converts the tree into a
+// go/ast file positioned in fset. Syntax errors are returned alongside
+// the tree built from whatever GoParser recovered, so broken code can
+// still be handed to go/types, go/printer and analysis passes.
+func ParseGoAST(fset *token.FileSet, filename, src string) (*ast.File, []SyntaxError) {
+	source := ParseGoString(filename, src)
+	return BuildAST(fset, source, src), source.Errors
+}
+
+// BuildAST converts a parsed Go source file into go/ast. The text
+// the source was parsed from is needed to map ANTLR's character
+// indexes onto go/token byte offsets.
+func BuildAST(fset *token.FileSet, source *GoSource, src string) *ast.File {
+	b := newASTBuilder(fset, source, src)
+	return b.sourceFile(source.Tree)
+}
+
+// astBuilder walks GoParser contexts and builds the equivalent
+// go/ast nodes. Missing children left by error recovery become
+// ast.BadExpr, ast.BadStmt or ast.BadDecl nodes.
+type astBuilder struct {
+	file    *token.File
+	src     string
+	offsets []int // byte offset of each character index
+
+	comments     []*ast.CommentGroup
+	docs         map[int]*ast.CommentGroup // keyed by the token index a comment group documents
+	lineComments map[int]*ast.CommentGroup // keyed by the index of the token a comment group follows
+
+	// verbatim makes bad expressions and statements string literals
+	// holding their source text, for printing code with syntax errors.
+	verbatim       bool
+	verbatimRanges [][2]int // byte offsets of the text kept so far
+}
+
+func newASTBuilder(fset *token.FileSet, source *GoSource, src string) *astBuilder {
+	b := &astBuilder{
+		file:         fset.AddFile(source.Name, -1, len(src)),
+		src:          src,
+		docs:         map[int]*ast.CommentGroup{},
+		lineComments: map[int]*ast.CommentGroup{},
+	}
+	b.file.SetLinesForContent([]byte(src))
+	for offset := range src {
+		b.offsets = append(b.offsets, offset)
+	}
+	b.offsets = append(b.offsets, len(src))
+	b.collectComments(source.Tokens)
+	return b
+}
+
+// Positions
+
+func (b *astBuilder) pos(t antlr.Token) token.Pos {
+	if t == nil || t.GetStart() < 0 || t.GetStart() >= len(b.offsets) {
+		return token.NoPos
+	}
+	return b.file.Pos(b.offsets[t.GetStart()])
+}
+
+func (b *astBuilder) end(t antlr.Token) token.Pos {
+	if t == nil || t.GetStop() < 0 || t.GetStop()+1 >= len(b.offsets) {
+		return token.NoPos
+	}
+	return b.file.Pos(b.offsets[t.GetStop()+1])
+}
+
+func (b *astBuilder) node(n antlr.TerminalNode) token.Pos {
+	if n == nil {
+		return token.NoPos
+	}
+	return b.pos(n.GetSymbol())
+}
+
+func (b *astBuilder) badExpr(ctx antlr.ParserRuleContext) ast.Expr {
+	if ctx == nil {
+		return &ast.BadExpr{}
+	}
+	if lit := b.verbatimText(ctx.GetStart(), ctx.GetStop()); lit != nil {
+		return lit
+	}
+	return &ast.BadExpr{From: b.pos(ctx.GetStart()), To: b.end(ctx.GetStop())}
+}
+
+func (b *astBuilder) badStmt(ctx antlr.ParserRuleContext) ast.Stmt {
+	if ctx == nil {
+		return &ast.BadStmt{}
+	}
+	return b.badTokens(ctx.GetStart(), ctx.GetStop())
+}
+
+func (b *astBuilder) badTokens(start, stop antlr.Token) ast.Stmt {
+	if lit := b.verbatimText(start, stop); lit != nil {
+		return &ast.ExprStmt{X: lit}
+	}
+	return &ast.BadStmt{From: b.pos(start), To: b.end(stop)}
+}
+
+// verbatimText returns the source from start to stop as a literal that
+// go/printer copies as it is, or nil unless the builder was asked to
+// keep unparsed code. Comments inside the text are dropped from the
+// file so that they are not printed twice.
+func (b *astBuilder) verbatimText(start, stop antlr.Token) *ast.BasicLit {
+	if !b.verbatim || start == nil || stop == nil || start.GetStart() < 0 ||
+		stop.GetStop() < start.GetStart() || stop.GetStop()+1 >= len(b.offsets) {
+		return nil
+	}
+	from, to := b.offsets[start.GetStart()], b.offsets[stop.GetStop()+1]
+	b.verbatimRanges = append(b.verbatimRanges, [2]int{from, to})
+	return &ast.BasicLit{ValuePos: b.file.Pos(from), Kind: token.STRING, Value: b.src[from:to]}
+}
+
+// Comments
+
+// collectComments groups comments as go/parser does: comments starting
+// on the line of the preceding token form a group of their own, others
+// are grouped until a blank line. A group ending on the line before a
+// token documents the declaration starting at that token; a group on
+// the line of the last token of a spec or field, with nothing after it
+// on its line, is the line comment of that spec or field.
+func (b *astBuilder) collectComments(tokens *antlr.CommonTokenStream) {
+	var group *ast.CommentGroup
+	sameLine := false // the group starts on the line of the preceding token
+	prevLine, endLine := 0, 0
+	prevIndex := -1 // the preceding token, not counting semicolons
+	endGroup := func(next antlr.Token) {
+		if group != nil && sameLine && (next.GetLine() != endLine ||
+			next.GetTokenType() == GoLexerSEMI || next.GetTokenType() == antlr.TokenEOF) {
+			b.lineComments[prevIndex] = group
+		}
+	}
+	for _, t := range tokens.GetAllTokens() {
+		switch t.GetTokenType() {
+		case GoLexerCOMMENT, GoLexerLINE_COMMENT:
+			line := t.GetLine()
+			if group == nil || sameLine && line > endLine || line > endLine+1 {
+				endGroup(t)
+				sameLine = group == nil && line == prevLine
+				group = &ast.CommentGroup{}
+				b.comments = append(b.comments, group)
+			}
+			group.List = append(group.List, &ast.Comment{Slash: b.pos(t), Text: t.GetText()})
+			endLine = lastLine(t)
+		case GoLexerTERMINATOR, GoLexerWS:
+		default:
+			endGroup(t)
+			if group != nil && !sameLine && endLine+1 == t.GetLine() {
+				b.docs[t.GetTokenIndex()] = group
+			}
+			group = nil
+			prevLine = t.GetLine()
+			if t.GetTokenType() != GoLexerEOS && t.GetTokenType() != GoLexerSEMI {
+				prevIndex = t.GetTokenIndex()
+			}
+		}
+	}
+}
+
+func (b *astBuilder) doc(ctx antlr.ParserRuleContext) *ast.CommentGroup {
+	if ctx == nil || ctx.GetStart() == nil {
+		return nil
+	}
+	return b.docs[ctx.GetStart().GetTokenIndex()]
+}
+
+func (b *astBuilder) lineComment(ctx antlr.ParserRuleContext) *ast.CommentGroup {
+	if ctx == nil || ctx.GetStop() == nil {
+		return nil
+	}
+	return b.lineComments[ctx.GetStop().GetTokenIndex()]
+}
+
+// Source file and declarations
+
+func (b *astBuilder) sourceFile(ctx *SourceFileContext) *ast.File {
+	f := &ast.File{
+		FileStart: b.file.Pos(0),
+		FileEnd:   b.file.Pos(b.file.Size()),
+		Name:      &ast.Ident{Name: "_"},
+	}
+	if pkg, ok := ctx.PackageClause().(*PackageClauseContext); ok {
+		f.Doc = b.doc(pkg)
+		f.Package = b.node(pkg.PACKAGE())
+		f.Name = b.ident(pkg.IDENTIFIER())
+	}
+	for _, child := range ctx.GetChildren() {
+		switch c := child.(type) {
+		case *ImportDeclContext:
+			decl := b.importDecl(c)
+			for _, spec := range decl.Specs {
+				f.Imports = append(f.Imports, spec.(*ast.ImportSpec))
+			}
+			f.Decls = append(f.Decls, decl)
+		case *FunctionDeclContext:
+			f.Decls = append(f.Decls, b.functionDecl(c))
+		case *MethodDeclContext:
+			f.Decls = append(f.Decls, b.methodDecl(c))
+		case *DeclarationContext:
+			f.Decls = append(f.Decls, b.declaration(c))
+		}
+	}
+	f.Comments = b.outsideVerbatim(b.comments)
+	return f
+}
+
+func (b *astBuilder) outsideVerbatim(groups []*ast.CommentGroup) []*ast.CommentGroup {
+	if len(b.verbatimRanges) == 0 {
+		return groups
+	}
+	var kept []*ast.CommentGroup
+	for _, g := range groups {
+		offset := b.file.Offset(g.Pos())
+		inside := false
+		for _, r := range b.verbatimRanges {
+			inside = inside || r[0] <= offset && offset < r[1]
+		}
+		if !inside {
+			kept = append(kept, g)
+		}
+	}
+	return kept
+}
+
+func (b *astBuilder) importDecl(ctx *ImportDeclContext) *ast.GenDecl {
+	decl := &ast.GenDecl{
+		Doc:    b.doc(ctx),
+		TokPos: b.node(ctx.IMPORT()),
+		Tok:    token.IMPORT,
+		Lparen: b.node(ctx.L_PAREN()),
+		Rparen: b.node(ctx.R_PAREN()),
+	}
+	for _, child := range ctx.GetChildren() {
+		spec, ok := child.(*ImportSpecContext)
+		if !ok {
+			continue
+		}
+		is := &ast.ImportSpec{Doc: b.doc(spec), Path: &ast.BasicLit{Kind: token.STRING}, Comment: b.lineComment(spec)}
+		if alias := spec.GetAlias(); alias != nil {
+			is.Name = &ast.Ident{NamePos: b.pos(alias), Name: alias.GetText()}
+		}
+		if path, ok := spec.ImportPath().(*ImportPathContext); ok {
+			is.Path = b.stringLit(path.String_())
+		}
+		decl.Specs = append(decl.Specs, is)
+	}
+	return decl
+}
+
+func (b *astBuilder) declaration(ctx IDeclarationContext) ast.Decl {
+	c, ok := ctx.(*DeclarationContext)
+	if !ok {
+		return &ast.BadDecl{}
+	}
+	switch d := alternative(c).(type) {
+	case *ConstDeclContext:
+		decl := b.genDecl(d, token.CONST, d.CONST(), d.L_PAREN(), d.R_PAREN())
+		for _, child := range d.GetChildren() {
+			if spec, ok := child.(*ConstSpecContext); ok {
+				decl.Specs = append(decl.Specs, b.valueSpec(spec, spec.IdentifierList(), spec.Type_(), spec.ExpressionList()))
+			}
+		}
+		return decl
+	case *VarDeclContext:
+		decl := b.genDecl(d, token.VAR, d.VAR(), d.L_PAREN(), d.R_PAREN())
+		for _, child := range d.GetChildren() {
+			if spec, ok := child.(*VarSpecContext); ok {
+				decl.Specs = append(decl.Specs, b.valueSpec(spec, spec.IdentifierList(), spec.Type_(), spec.ExpressionList()))
+			}
+		}
+		return decl
+	case *TypeDeclContext:
+		decl := b.genDecl(d, token.TYPE, d.TYPE(), d.L_PAREN(), d.R_PAREN())
+		for _, child := range d.GetChildren() {
+			if spec, ok := child.(*TypeSpecContext); ok {
+				decl.Specs = append(decl.Specs, b.typeSpec(spec))
+			}
+		}
+		return decl
+	}
+	return &ast.BadDecl{From: b.pos(c.GetStart()), To: b.end(c.GetStop())}
+}
+
+func (b *astBuilder) genDecl(ctx antlr.ParserRuleContext, tok token.Token, keyword, lparen, rparen antlr.TerminalNode) *ast.GenDecl {
+	return &ast.GenDecl{
+		Doc:    b.doc(ctx),
+		TokPos: b.node(keyword),
+		Tok:    tok,
+		Lparen: b.node(lparen),
+		Rparen: b.node(rparen),
+	}
+}
+
+func (b *astBuilder) valueSpec(ctx antlr.ParserRuleContext, names IIdentifierListContext, typ IType_Context, values IExpressionListContext) *ast.ValueSpec {
+	spec := &ast.ValueSpec{Doc: b.doc(ctx), Names: b.identifierList(names), Comment: b.lineComment(ctx)}
+	if typ != nil {
+		spec.Type = b.type_(typ)
+	}
+	spec.Values = b.expressionList(values)
+	return spec
+}
+
+func (b *astBuilder) typeSpec(ctx *TypeSpecContext) *ast.TypeSpec {
+	return &ast.TypeSpec{
+		Doc:        b.doc(ctx),
+		Name:       b.ident(ctx.IDENTIFIER()),
+		TypeParams: b.typeParameters(ctx.TypeParameters()),
+		Assign:     b.node(ctx.ASSIGN()),
+		Type:       b.type_(ctx.Type_()),
+		Comment:    b.lineComment(ctx),
+	}
+}
+
+func (b *astBuilder) functionDecl(ctx *FunctionDeclContext) *ast.FuncDecl {
+	return &ast.FuncDecl{
+		Doc:  b.doc(ctx),
+		Name: b.ident(ctx.IDENTIFIER()),
+		Type: b.funcType(b.node(ctx.FUNC()), b.typeParameters(ctx.TypeParameters()), ctx.Signature()),
+		Body: b.block(ctx.Block()),
+	}
+}
+
+func (b *astBuilder) methodDecl(ctx *MethodDeclContext) *ast.FuncDecl {
+	decl := &ast.FuncDecl{
+		Doc:  b.doc(ctx),
+		Name: b.ident(ctx.IDENTIFIER()),
+		Type: b.funcType(b.node(ctx.FUNC()), nil, ctx.Signature()),
+		Body: b.block(ctx.Block()),
+	}
+	if recv, ok := ctx.Receiver().(*ReceiverContext); ok {
+		decl.Recv = b.parameters(recv.Parameters())
+	}
+	return decl
+}
+
+// alternative returns the first rule among the children of ctx, which
+// tells the alternative of a rule such as statement or typeLit that was
+// parsed, or nil if error recovery left none.
+func alternative(ctx antlr.ParserRuleContext) antlr.Tree {
+	for _, child := range ctx.GetChildren() {
+		if rule, ok := child.(antlr.ParserRuleContext); ok {
+			return rule
+		}
+	}
+	return nil
+}
+
+// Identifiers and literals
+
+func (b *astBuilder) ident(n antlr.TerminalNode) *ast.Ident {
+	if n == nil {
+		return &ast.Ident{Name: "_"}
+	}
+	return &ast.Ident{NamePos: b.node(n), Name: n.GetText()}
+}
+
+func (b *astBuilder) identifierList(ctx IIdentifierListContext) []*ast.Ident {
+	c, ok := ctx.(*IdentifierListContext)
+	if !ok {
+		return nil
+	}
+	var idents []*ast.Ident
+	for _, n := range c.AllIDENTIFIER() {
+		idents = append(idents, b.ident(n))
+	}
+	return idents
+}
+
+func (b *astBuilder) stringLit(ctx IString_Context) *ast.BasicLit {
+	if ctx == nil {
+		return &ast.BasicLit{Kind: token.STRING}
+	}
+	t := ctx.GetStart()
+	return &ast.BasicLit{ValuePos: b.pos(t), Kind: token.STRING, Value: t.GetText()}
+}
+
+var literalKinds = map[int]token.Token{
+	GoParserDECIMAL_LIT:            token.INT,
+	GoParserBINARY_LIT:             token.INT,
+	GoParserOCTAL_LIT:              token.INT,
+	GoParserHEX_LIT:                token.INT,
+	GoParserFLOAT_LIT:              token.FLOAT,
+	GoParserIMAGINARY_LIT:          token.IMAG,
+	GoParserRUNE_LIT:               token.CHAR,
+	GoParserRAW_STRING_LIT:         token.STRING,
+	GoParserINTERPRETED_STRING_LIT: token.STRING,
+}
+
+func (b *astBuilder) basicLit(ctx IBasicLitContext) ast.Expr {
+	t := ctx.GetStart()
+	if t.GetTokenType() == GoParserNIL_LIT {
+		return &ast.Ident{NamePos: b.pos(t), Name: "nil"}
+	}
+	return &ast.BasicLit{ValuePos: b.pos(t), Kind: literalKinds[t.GetTokenType()], Value: t.GetText()}
+}
+
+// Types
+
+func (b *astBuilder) type_(ctx IType_Context) ast.Expr {
+	c, ok := ctx.(*Type_Context)
+	if !ok {
+		return b.badExpr(nil)
+	}
+	switch t := alternative(c).(type) {
+	case *TypeNameContext:
+		return b.instantiate(b.typeName(t), c.TypeArgs())
+	case *TypeLitContext:
+		return b.typeLit(t)
+	case *Type_Context:
+		return &ast.ParenExpr{Lparen: b.node(c.L_PAREN()), X: b.type_(t), Rparen: b.node(c.R_PAREN())}
+	}
+	return b.badExpr(c)
+}
+
+func (b *astBuilder) typeName(ctx ITypeNameContext) ast.Expr {
+	c, ok := ctx.(*TypeNameContext)
+	if !ok {
+		return b.badExpr(nil)
+	}
+	if q, ok := c.QualifiedIdent().(*QualifiedIdentContext); ok {
+		return &ast.SelectorExpr{X: b.ident(q.IDENTIFIER(0)), Sel: b.ident(q.IDENTIFIER(1))}
+	}
+	return b.ident(c.IDENTIFIER())
+}
+
+// instantiate applies type arguments, if any, to a generic type or function.
+func (b *astBuilder) instantiate(x ast.Expr, ctx ITypeArgsContext) ast.Expr {
+	c, ok := ctx.(*TypeArgsContext)
+	if !ok {
+		return x
+	}
+	args := b.typeList(c.TypeList())
+	if len(args) == 1 {
+		return &ast.IndexExpr{X: x, Lbrack: b.node(c.L_BRACKET()), Index: args[0], Rbrack: b.node(c.R_BRACKET())}
+	}
+	return &ast.IndexListExpr{X: x, Lbrack: b.node(c.L_BRACKET()), Indices: args, Rbrack: b.node(c.R_BRACKET())}
+}
+
+func (b *astBuilder) typeList(ctx ITypeListContext) []ast.Expr {
+	if ctx == nil {
+		return nil
+	}
+	var types []ast.Expr
+	for _, child := range ctx.GetChildren() {
+		switch c := child.(type) {
+		case *Type_Context:
+			types = append(types, b.type_(c))
+		case antlr.TerminalNode:
+			if c.GetSymbol().GetTokenType() == GoParserNIL_LIT {
+				types = append(types, b.ident(c))
+			}
+		}
+	}
+	return types
+}
+
+func (b *astBuilder) elementType(ctx IElementTypeContext) ast.Expr {
+	c, ok := ctx.(*ElementTypeContext)
+	if !ok {
+		return b.badExpr(nil)
+	}
+	return b.type_(c.Type_())
+}
+
+func (b *astBuilder) typeLit(ctx *TypeLitContext) ast.Expr {
+	switch c := alternative(ctx).(type) {
+	case *ArrayTypeContext:
+		return b.arrayType(c)
+	case *StructTypeContext:
+		return b.structType(c)
+	case *PointerTypeContext:
+		return &ast.StarExpr{Star: b.node(c.STAR()), X: b.type_(c.Type_())}
+	case *FunctionTypeContext:
+		return b.funcType(b.node(c.FUNC()), nil, c.Signature())
+	case *InterfaceTypeContext:
+		return b.interfaceType(c)
+	case *SliceTypeContext:
+		return &ast.ArrayType{Lbrack: b.node(c.L_BRACKET()), Elt: b.elementType(c.ElementType())}
+	case *MapTypeContext:
+		return b.mapType(c)
+	case *ChannelTypeContext:
+		return b.channelType(c)
+	}
+	return b.badExpr(ctx)
+}
+
+func (b *astBuilder) arrayType(ctx *ArrayTypeContext) *ast.ArrayType {
+	t := &ast.ArrayType{Lbrack: b.node(ctx.L_BRACKET()), Elt: b.elementType(ctx.ElementType())}
+	if length, ok := ctx.ArrayLength().(*ArrayLengthContext); ok {
+		t.Len = b.expression(length.Expression())
+	} else {
+		t.Len = b.badExpr(nil)
+	}
+	return t
+}
+
+func (b *astBuilder) mapType(ctx *MapTypeContext) *ast.MapType {
+	return &ast.MapType{Map: b.node(ctx.MAP()), Key: b.type_(ctx.Type_()), Value: b.elementType(ctx.ElementType())}
+}
+
+func (b *astBuilder) channelType(ctx *ChannelTypeContext) *ast.ChanType {
+	t := &ast.ChanType{Begin: b.pos(ctx.GetStart()), Dir: ast.SEND | ast.RECV, Value: b.elementType(ctx.ElementType())}
+	if arrow := ctx.RECEIVE(); arrow != nil {
+		t.Arrow = b.node(arrow)
+		if ctx.GetStart().GetTokenType() == GoParserRECEIVE {
+			t.Dir = ast.RECV
+		} else {
+			t.Dir = ast.SEND
+		}
+	}
+	return t
+}
+
+func (b *astBuilder) structType(ctx *StructTypeContext) *ast.StructType {
+	fields := &ast.FieldList{Opening: b.node(ctx.L_CURLY()), Closing: b.node(ctx.R_CURLY())}
+	for _, child := range ctx.GetChildren() {
+		if f, ok := child.(*FieldDeclContext); ok {
+			fields.List = append(fields.List, b.fieldDecl(f))
+		}
+	}
+	return &ast.StructType{Struct: b.node(ctx.STRUCT()), Fields: fields}
+}
+
+func (b *astBuilder) fieldDecl(ctx *FieldDeclContext) *ast.Field {
+	field := &ast.Field{Doc: b.doc(ctx), Comment: b.lineComment(ctx)}
+	if embedded, ok := ctx.EmbeddedField().(*EmbeddedFieldContext); ok {
+		field.Type = b.instantiate(b.typeName(embedded.TypeName()), embedded.TypeArgs())
+		if star := embedded.STAR(); star != nil {
+			field.Type = &ast.StarExpr{Star: b.node(star), X: field.Type}
+		}
+	} else {
+		field.Names = b.identifierList(ctx.IdentifierList())
+		field.Type = b.type_(ctx.Type_())
+	}
+	if tag := ctx.GetTag(); tag != nil {
+		field.Tag = b.stringLit(tag)
+	}
+	return field
+}
+
+func (b *astBuilder) interfaceType(ctx *InterfaceTypeContext) *ast.InterfaceType {
+	methods := &ast.FieldList{Opening: b.node(ctx.L_CURLY()), Closing: b.node(ctx.R_CURLY())}
+	for _, child := range ctx.GetChildren() {
+		switch c := child.(type) {
+		case *MethodSpecContext:
+			params := b.parameters(c.Parameters())
+			methods.List = append(methods.List, &ast.Field{
+				Doc:     b.doc(c),
+				Names:   []*ast.Ident{b.ident(c.IDENTIFIER())},
+				Type:    &ast.FuncType{Func: token.NoPos, Params: params, Results: b.result(c.Result())},
+				Comment: b.lineComment(c),
+			})
+		case *TypeElementContext:
+			methods.List = append(methods.List, &ast.Field{Doc: b.doc(c), Type: b.typeElement(c), Comment: b.lineComment(c)})
+		}
+	}
+	return &ast.InterfaceType{Interface: b.node(ctx.INTERFACE()), Methods: methods}
+}
+
+// typeElement builds a constraint union such as ~int | ~string as a
+// chain of | binary expressions over ~ unary expressions, the way
+// go/parser does.
+func (b *astBuilder) typeElement(ctx ITypeElementContext) ast.Expr {
+	c, ok := ctx.(*TypeElementContext)
+	if !ok {
+		return b.badExpr(nil)
+	}
+	var x ast.Expr
+	var or antlr.TerminalNode
+	for _, child := range c.GetChildren() {
+		switch term := child.(type) {
+		case antlr.TerminalNode:
+			or = term
+		case *TypeTermContext:
+			y := b.type_(term.Type_())
+			if tilde := term.TILDE(); tilde != nil {
+				y = &ast.UnaryExpr{OpPos: b.node(tilde), Op: token.TILDE, X: y}
+			}
+			if x == nil {
+				x = y
+			} else {
+				x = &ast.BinaryExpr{X: x, OpPos: b.node(or), Op: token.OR, Y: y}
+			}
+		}
+	}
+	if x == nil {
+		return b.badExpr(c)
+	}
+	return x
+}
+
+func (b *astBuilder) typeParameters(ctx ITypeParametersContext) *ast.FieldList {
+	c, ok := ctx.(*TypeParametersContext)
+	if !ok {
+		return nil
+	}
+	params := &ast.FieldList{Opening: b.node(c.L_BRACKET()), Closing: b.node(c.R_BRACKET())}
+	for _, child := range c.GetChildren() {
+		decl, ok := child.(*TypeParameterDeclContext)
+		if !ok {
+			continue
+		}
+		params.List = append(params.List, &ast.Field{
+			Names: b.identifierList(decl.IdentifierList()),
+			Type:  b.typeElement(decl.TypeElement()),
+		})
+	}
+	return params
+}
+
+func (b *astBuilder) funcType(funcPos token.Pos, typeParams *ast.FieldList, ctx ISignatureContext) *ast.FuncType {
+	t := &ast.FuncType{Func: funcPos, TypeParams: typeParams, Params: &ast.FieldList{}}
+	if sig, ok := ctx.(*SignatureContext); ok {
+		t.Params = b.parameters(sig.Parameters())
+		t.Results = b.result(sig.Result())
+	}
+	return t
+}
+
+func (b *astBuilder) result(ctx IResultContext) *ast.FieldList {
+	c, ok := ctx.(*ResultContext)
+	if !ok {
+		return nil
+	}
+	if c.Parameters() != nil {
+		return b.parameters(c.Parameters())
+	}
+	return &ast.FieldList{List: []*ast.Field{{Type: b.type_(c.Type_())}}}
+}
+
+// parameters builds a parameter list. GoParser cannot tell whether a
+// lone identifier in (a, b int) is a name or a type, so like go/parser
+// a list with any named parameter turns the preceding lone identifiers
+// into names sharing the next type.
+func (b *astBuilder) parameters(ctx IParametersContext) *ast.FieldList {
+	list := &ast.FieldList{}
+	c, ok := ctx.(*ParametersContext)
+	if !ok {
+		return list
+	}
+	list.Opening = b.node(c.L_PAREN())
+	list.Closing = b.node(c.R_PAREN())
+
+	named := false
+	for _, child := range c.GetChildren() {
+		decl, ok := child.(*ParameterDeclContext)
+		if !ok {
+			continue
+		}
+		field := &ast.Field{}
+		field.Names = b.identifierList(decl.IdentifierList())
+		field.Type = b.type_(decl.Type_())
+		if ellipsis := decl.ELLIPSIS(); ellipsis != nil {
+			field.Type = &ast.Ellipsis{Ellipsis: b.node(ellipsis), Elt: field.Type}
+		}
+		named = named || len(field.Names) > 0
+		list.List = append(list.List, field)
+	}
+	if !named {
+		return list
+	}
+
+	var fields []*ast.Field
+	var pending []*ast.Ident
+	for _, field := range list.List {
+		if ident, ok := field.Type.(*ast.Ident); ok && len(field.Names) == 0 {
+			pending = append(pending, ident)
+			continue
+		}
+		field.Names = append(pending, field.Names...)
+		pending = nil
+		fields = append(fields, field)
+	}
+	for _, ident := range pending {
+		// Trailing lone identifiers: keep them as types.
+		fields = append(fields, &ast.Field{Type: ident})
+	}
+	list.List = fields
+	return list
+}
+
+// Expressions
+
+var binaryOps = map[int]token.Token{
+	GoParserSTAR:              token.MUL,
+	GoParserDIV:               token.QUO,
+	GoParserMOD:               token.REM,
+	GoParserLSHIFT:            token.SHL,
+	GoParserRSHIFT:            token.SHR,
+	GoParserAMPERSAND:         token.AND,
+	GoParserBIT_CLEAR:         token.AND_NOT,
+	GoParserPLUS:              token.ADD,
+	GoParserMINUS:             token.SUB,
+	GoParserOR:                token.OR,
+	GoParserCARET:             token.XOR,
+	GoParserEQUALS:            token.EQL,
+	GoParserNOT_EQUALS:        token.NEQ,
+	GoParserLESS:              token.LSS,
+	GoParserLESS_OR_EQUALS:    token.LEQ,
+	GoParserGREATER:           token.GTR,
+	GoParserGREATER_OR_EQUALS: token.GEQ,
+	GoParserLOGICAL_AND:       token.LAND,
+	GoParserLOGICAL_OR:        token.LOR,
+}
+
+var unaryOps = map[int]token.Token{
+	GoParserPLUS:        token.ADD,
+	GoParserMINUS:       token.SUB,
+	GoParserEXCLAMATION: token.NOT,
+	GoParserCARET:       token.XOR,
+	GoParserAMPERSAND:   token.AND,
+	GoParserRECEIVE:     token.ARROW,
+}
+
+// expression regroups the operators of ctx by Go precedence. The
+// grammar parses the operand of a unary operator as a whole expression,
+// so GoParser reads !a && b as !(a && b); the operands and operators
+// are flattened in source order and grouped again as go/parser does.
+func (b *astBuilder) expression(ctx IExpressionContext) ast.Expr {
+	c, ok := ctx.(*ExpressionContext)
+	if !ok {
+		return b.badExpr(nil)
+	}
+	var items []exprItem
+	flattenExpression(c, &items)
+	i := 0
+	return b.binaryExpr(items, &i, token.LowestPrec+1)
+}
+
+// exprItem is an operator or an operand of a flattened expression.
+type exprItem struct {
+	op      antlr.Token
+	operand antlr.ParserRuleContext // a primary expression, or the bad expression
+}
+
+func flattenExpression(ctx *ExpressionContext, items *[]exprItem) {
+	switch c := alternative(ctx).(type) {
+	case *PrimaryExprContext:
+		*items = append(*items, exprItem{operand: c})
+		return
+	case *UnaryExprContext:
+		x, ok := c.Expression().(*ExpressionContext)
+		switch {
+		case c.PrimaryExpr() != nil:
+			*items = append(*items, exprItem{operand: c.PrimaryExpr()})
+		case c.GetUnary_op() != nil && ok:
+			*items = append(*items, exprItem{op: c.GetUnary_op()})
+			flattenExpression(x, items)
+		default:
+			*items = append(*items, exprItem{operand: c})
+		}
+		return
+	}
+	if ctx.GetChildCount() == 3 {
+		x, okX := ctx.Expression(0).(*ExpressionContext)
+		op, okOp := ctx.GetChild(1).(antlr.TerminalNode)
+		y, okY := ctx.Expression(1).(*ExpressionContext)
+		if okX && okOp && okY {
+			flattenExpression(x, items)
+			*items = append(*items, exprItem{op: op.GetSymbol()})
+			flattenExpression(y, items)
+			return
+		}
+	}
+	*items = append(*items, exprItem{operand: ctx})
+}
+
+func (b *astBuilder) binaryExpr(items []exprItem, i *int, prec int) ast.Expr {
+	x := b.unaryExpr(items, i)
+	for *i < len(items) && items[*i].op != nil {
+		op := items[*i].op
+		tok, ok := binaryOps[op.GetTokenType()]
+		if !ok || tok.Precedence() < prec {
+			break
+		}
+		*i++
+		y := b.binaryExpr(items, i, tok.Precedence()+1)
+		x = &ast.BinaryExpr{X: x, OpPos: b.pos(op), Op: tok, Y: y}
+	}
+	return x
+}
+
+func (b *astBuilder) unaryExpr(items []exprItem, i *int) ast.Expr {
+	if *i >= len(items) {
+		return b.badExpr(nil)
+	}
+	item := items[*i]
+	*i++
+	if item.op == nil {
+		if p, ok := item.operand.(*PrimaryExprContext); ok {
+			return b.primaryExpr(p)
+		}
+		return b.badExpr(item.operand)
+	}
+	x := b.unaryExpr(items, i)
+	if item.op.GetTokenType() == GoParserSTAR {
+		return &ast.StarExpr{Star: b.pos(item.op), X: x}
+	}
+	return &ast.UnaryExpr{OpPos: b.pos(item.op), Op: unaryOps[item.op.GetTokenType()], X: x}
+}
+
+func (b *astBuilder) expressionList(ctx IExpressionListContext) []ast.Expr {
+	c, ok := ctx.(*ExpressionListContext)
+	if !ok {
+		return nil
+	}
+	var exprs []ast.Expr
+	for _, e := range c.AllExpression() {
+		exprs = append(exprs, b.expression(e))
+	}
+	return exprs
+}
+
+func (b *astBuilder) primaryExpr(ctx IPrimaryExprContext) ast.Expr {
+	c, ok := ctx.(*PrimaryExprContext)
+	if !ok {
+		return b.badExpr(nil)
+	}
+	switch alt := alternative(c).(type) {
+	case *OperandContext:
+		return b.operand(alt)
+	case *ConversionContext:
+		return &ast.CallExpr{
+			Fun:    b.type_(alt.Type_()),
+			Lparen: b.node(alt.L_PAREN()),
+			Args:   []ast.Expr{b.expression(alt.Expression())},
+			Rparen: b.node(alt.R_PAREN()),
+		}
+	case *MethodExprContext:
+		var recv ast.Expr
+		if rt, ok := alt.ReceiverType().(*ReceiverTypeContext); ok {
+			recv = b.type_(rt.Type_())
+		} else {
+			recv = b.badExpr(alt)
+		}
+		return &ast.SelectorExpr{X: recv, Sel: b.ident(alt.IDENTIFIER())}
+	case *PrimaryExprContext:
+		return b.primarySuffix(b.primaryExpr(alt), c)
+	}
+	return b.badExpr(c)
+}
+
+// primarySuffix applies the selector, index, slice, type assertion or
+// arguments following the primary expression x in ctx.
+func (b *astBuilder) primarySuffix(x ast.Expr, ctx *PrimaryExprContext) ast.Expr {
+	if id := ctx.IDENTIFIER(); id != nil {
+		return &ast.SelectorExpr{X: x, Sel: b.ident(id)}
+	}
+	for _, child := range ctx.GetChildren() {
+		switch c := child.(type) {
+		case *IndexContext:
+			return &ast.IndexExpr{
+				X:      x,
+				Lbrack: b.node(c.L_BRACKET()),
+				Index:  b.expression(c.Expression()),
+				Rbrack: b.node(c.R_BRACKET()),
+			}
+		case *SliceContext:
+			return b.slice(x, c)
+		case *TypeAssertionContext:
+			return &ast.TypeAssertExpr{
+				X:      x,
+				Lparen: b.node(c.L_PAREN()),
+				Type:   b.type_(c.Type_()),
+				Rparen: b.node(c.R_PAREN()),
+			}
+		case *ArgumentsContext:
+			return b.call(x, c)
+		}
+	}
+	return b.badExpr(ctx)
+}
+
+func (b *astBuilder) slice(x ast.Expr, ctx *SliceContext) *ast.SliceExpr {
+	var index [3]ast.Expr
+	colons := 0
+	for _, child := range ctx.GetChildren() {
+		switch c := child.(type) {
+		case *ExpressionContext:
+			if colons < len(index) {
+				index[colons] = b.expression(c)
+			}
+		case antlr.TerminalNode:
+			if c.GetSymbol().GetTokenType() == GoParserCOLON {
+				colons++
+			}
+		}
+	}
+	return &ast.SliceExpr{
+		X:      x,
+		Lbrack: b.node(ctx.L_BRACKET()),
+		Low:    index[0],
+		High:   index[1],
+		Max:    index[2],
+		Slice3: colons == 2,
+		Rbrack: b.node(ctx.R_BRACKET()),
+	}
+}
+
+func (b *astBuilder) call(fun ast.Expr, ctx *ArgumentsContext) *ast.CallExpr {
+	call := &ast.CallExpr{
+		Fun:      fun,
+		Lparen:   b.node(ctx.L_PAREN()),
+		Ellipsis: b.node(ctx.ELLIPSIS()),
+		Rparen:   b.node(ctx.R_PAREN()),
+	}
+	if t := ctx.Type_(); t != nil {
+		// make(T, ...), new(T) and friends
+		call.Args = append(call.Args, b.type_(t))
+	}
+	call.Args = append(call.Args, b.expressionList(ctx.ExpressionList())...)
+	return call
+}
+
+func (b *astBuilder) operand(ctx *OperandContext) ast.Expr {
+	switch c := alternative(ctx).(type) {
+	case *LiteralContext:
+		return b.literal(c)
+	case *OperandNameContext:
+		var x ast.Expr = b.ident(c.IDENTIFIER(0))
+		if c.DOT() != nil {
+			x = &ast.SelectorExpr{X: x, Sel: b.ident(c.IDENTIFIER(1))}
+		}
+		return b.instantiate(x, ctx.TypeArgs())
+	case *ExpressionContext:
+		return &ast.ParenExpr{
+			Lparen: b.node(ctx.L_PAREN()),
+			X:      b.expression(c),
+			Rparen: b.node(ctx.R_PAREN()),
+		}
+	}
+	return b.badExpr(ctx)
+}
+
+func (b *astBuilder) literal(ctx *LiteralContext) ast.Expr {
+	switch c := alternative(ctx).(type) {
+	case *BasicLitContext:
+		return b.basicLit(c)
+	case *CompositeLitContext:
+		var typ ast.Expr
+		if lt, ok := c.LiteralType().(*LiteralTypeContext); ok {
+			typ = b.literalType(lt)
+		} else {
+			typ = b.badExpr(c)
+		}
+		return b.literalValue(typ, c.LiteralValue())
+	case *FunctionLitContext:
+		return &ast.FuncLit{
+			Type: b.funcType(b.node(c.FUNC()), nil, c.Signature()),
+			Body: b.body(c.Block()),
+		}
+	}
+	return b.badExpr(ctx)
+}
+
+func (b *astBuilder) literalType(ctx *LiteralTypeContext) ast.Expr {
+	switch c := alternative(ctx).(type) {
+	case *StructTypeContext:
+		return b.structType(c)
+	case *ArrayTypeContext:
+		return b.arrayType(c)
+	case *ElementTypeContext: // [...]T
+		return &ast.ArrayType{
+			Lbrack: b.node(ctx.L_BRACKET()),
+			Len:    &ast.Ellipsis{Ellipsis: b.node(ctx.ELLIPSIS())},
+			Elt:    b.elementType(c),
+		}
+	case *SliceTypeContext:
+		return &ast.ArrayType{Lbrack: b.node(c.L_BRACKET()), Elt: b.elementType(c.ElementType())}
+	case *MapTypeContext:
+		return b.mapType(c)
+	case *TypeNameContext:
+		return b.instantiate(b.typeName(c), ctx.TypeArgs())
+	}
+	return b.badExpr(ctx)
+}
+
+// literalValue builds a composite literal; typ is nil for the elided
+// types of nested literals such as the inner braces of [][]int{{1}}.
+func (b *astBuilder) literalValue(typ ast.Expr, ctx ILiteralValueContext) *ast.CompositeLit {
+	lit := &ast.CompositeLit{Type: typ}
+	c, ok := ctx.(*LiteralValueContext)
+	if !ok {
+		return lit
+	}
+	lit.Lbrace = b.node(c.L_CURLY())
+	lit.Rbrace = b.node(c.R_CURLY())
+	if list, ok := c.ElementList().(*ElementListContext); ok {
+		for _, child := range list.GetChildren() {
+			if e, ok := child.(*KeyedElementContext); ok {
+				lit.Elts = append(lit.Elts, b.keyedElement(e))
+			}
+		}
+	}
+	return lit
+}
+
+func (b *astBuilder) keyedElement(ctx *KeyedElementContext) ast.Expr {
+	var value ast.Expr
+	if e, ok := ctx.Element().(*ElementContext); ok {
+		value = b.element(e.Expression(), e.LiteralValue())
+	} else {
+		value = b.badExpr(ctx)
+	}
+	key, ok := ctx.Key().(*KeyContext)
+	if !ok {
+		return value
+	}
+	var k ast.Expr
+	if id := key.IDENTIFIER(); id != nil {
+		k = b.ident(id)
+	} else {
+		k = b.element(key.Expression(), key.LiteralValue())
+	}
+	return &ast.KeyValueExpr{Key: k, Colon: b.node(ctx.COLON()), Value: value}
+}
+
+func (b *astBuilder) element(expr IExpressionContext, value ILiteralValueContext) ast.Expr {
+	if value != nil {
+		return b.literalValue(nil, value)
+	}
+	return b.expression(expr)
+}
+
+// Statements
+
+func (b *astBuilder) block(ctx IBlockContext) *ast.BlockStmt {
+	c, ok := ctx.(*BlockContext)
+	if !ok {
+		return nil
+	}
+	return &ast.BlockStmt{
+		Lbrace: b.node(c.L_CURLY()),
+		List:   b.statements(c),
+		Rbrace: b.node(c.R_CURLY()),
+	}
+}
+
+// body is block for the function literals and statements that cannot
+// leave their block out: a missing one becomes an empty block, as
+// go/ast.Walk expects.
+func (b *astBuilder) body(ctx IBlockContext) *ast.BlockStmt {
+	if block := b.block(ctx); block != nil {
+		return block
+	}
+	return &ast.BlockStmt{}
+}
+
+func (b *astBuilder) statementList(ctx IStatementListContext) []ast.Stmt {
+	if ctx == nil {
+		return nil
+	}
+	return b.statements(ctx)
+}
+
+// statements builds the statements among the children of a block or
+// statement list. A run of tokens skipped by error recovery becomes
+// one bad statement.
+func (b *astBuilder) statements(ctx antlr.ParserRuleContext) []ast.Stmt {
+	var stmts []ast.Stmt
+	var first, last antlr.Token
+	flush := func() {
+		if first != nil {
+			stmts = append(stmts, b.badTokens(first, last))
+			first = nil
+		}
+	}
+	for _, child := range ctx.GetChildren() {
+		switch c := child.(type) {
+		case antlr.ErrorNode:
+			if first == nil {
+				first = c.GetSymbol()
+			}
+			last = c.GetSymbol()
+		case *StatementListContext:
+			flush()
+			stmts = append(stmts, b.statements(c)...)
+		case *StatementContext:
+			flush()
+			stmts = append(stmts, b.statement(c))
+		}
+	}
+	flush()
+	return stmts
+}
+
+func (b *astBuilder) statement(ctx IStatementContext) ast.Stmt {
+	c, ok := ctx.(*StatementContext)
+	if !ok {
+		return b.badStmt(nil)
+	}
+	switch s := alternative(c).(type) {
+	case *DeclarationContext:
+		return &ast.DeclStmt{Decl: b.declaration(s)}
+	case *LabeledStmtContext:
+		return b.labeledStmt(s)
+	case *SimpleStmtContext:
+		return b.simpleStmt(s)
+	case *GoStmtContext:
+		if call, ok := b.expression(s.Expression()).(*ast.CallExpr); ok {
+			return &ast.GoStmt{Go: b.node(s.GO()), Call: call}
+		}
+		return b.badStmt(s)
+	case *ReturnStmtContext:
+		return &ast.ReturnStmt{Return: b.node(s.RETURN()), Results: b.expressionList(s.ExpressionList())}
+	case *BreakStmtContext:
+		return b.branchStmt(token.BREAK, s.BREAK(), s.IDENTIFIER())
+	case *ContinueStmtContext:
+		return b.branchStmt(token.CONTINUE, s.CONTINUE(), s.IDENTIFIER())
+	case *GotoStmtContext:
+		return b.branchStmt(token.GOTO, s.GOTO(), s.IDENTIFIER())
+	case *FallthroughStmtContext:
+		return b.branchStmt(token.FALLTHROUGH, s.FALLTHROUGH(), nil)
+	case *BlockContext:
+		return b.block(s)
+	case *IfStmtContext:
+		return b.ifStmt(s)
+	case *SwitchStmtContext:
+		switch sw := alternative(s).(type) {
+		case *ExprSwitchStmtContext:
+			return b.exprSwitchStmt(sw)
+		case *TypeSwitchStmtContext:
+			return b.typeSwitchStmt(sw)
+		}
+	case *SelectStmtContext:
+		return b.selectStmt(s)
+	case *ForStmtContext:
+		return b.forStmt(s)
+	case *DeferStmtContext:
+		if call, ok := b.expression(s.Expression()).(*ast.CallExpr); ok {
+			return &ast.DeferStmt{Defer: b.node(s.DEFER()), Call: call}
+		}
+		return b.badStmt(s)
+	}
+	return b.badStmt(c)
+}
+
+func (b *astBuilder) branchStmt(tok token.Token, keyword, label antlr.TerminalNode) *ast.BranchStmt {
+	s := &ast.BranchStmt{TokPos: b.node(keyword), Tok: tok}
+	if label != nil {
+		s.Label = b.ident(label)
+	}
+	return s
+}
+
+func (b *astBuilder) labeledStmt(ctx *LabeledStmtContext) *ast.LabeledStmt {
+	s := &ast.LabeledStmt{Label: b.ident(ctx.IDENTIFIER()), Colon: b.node(ctx.COLON())}
+	if ctx.Statement() != nil {
+		s.Stmt = b.statement(ctx.Statement())
+	} else {
+		s.Stmt = &ast.EmptyStmt{Semicolon: s.Colon + 1, Implicit: true}
+	}
+	return s
+}
+
+// simpleStmt builds a SimpleStmtContext or a TerminatedSimpleStmtContext,
+// whose alternatives are the same statements.
+func (b *astBuilder) simpleStmt(ctx antlr.ParserRuleContext) ast.Stmt {
+	switch s := alternative(ctx).(type) {
+	case *SendStmtContext:
+		return b.sendStmt(s)
+	case *IncDecStmtContext:
+		if s.PLUS_PLUS() != nil {
+			return &ast.IncDecStmt{X: b.expression(s.Expression()), TokPos: b.node(s.PLUS_PLUS()), Tok: token.INC}
+		}
+		return &ast.IncDecStmt{X: b.expression(s.Expression()), TokPos: b.node(s.MINUS_MINUS()), Tok: token.DEC}
+	case *AssignmentContext:
+		return b.assignment(s)
+	case *ExpressionStmtContext:
+		return &ast.ExprStmt{X: b.expression(s.Expression())}
+	case *ShortVarDeclContext:
+		var lhs []ast.Expr
+		for _, ident := range b.identifierList(s.IdentifierList()) {
+			lhs = append(lhs, ident)
+		}
+		return &ast.AssignStmt{
+			Lhs:    lhs,
+			TokPos: b.node(s.DECLARE_ASSIGN()),
+			Tok:    token.DEFINE,
+			Rhs:    b.expressionList(s.ExpressionList()),
+		}
+	case *EmptyStmtContext:
+		return &ast.EmptyStmt{Semicolon: b.pos(ctx.GetStart())}
+	}
+	return b.badStmt(ctx)
+}
+
+func (b *astBuilder) sendStmt(ctx *SendStmtContext) *ast.SendStmt {
+	return &ast.SendStmt{
+		Chan:  b.expression(ctx.Expression(0)),
+		Arrow: b.node(ctx.RECEIVE()),
+		Value: b.expression(ctx.Expression(1)),
+	}
+}
+
+// initStmt returns the optional init statement of an if, switch or
+// for header; an empty statement means there is none.
+func (b *astBuilder) initStmt(ctx ITerminatedSimpleStmtContext) ast.Stmt {
+	c, ok := ctx.(*TerminatedSimpleStmtContext)
+	if !ok || c.EmptyStmt() != nil {
+		return nil
+	}
+	return b.simpleStmt(c)
+}
+
+var assignOps = map[int]token.Token{
+	GoParserPLUS:      token.ADD_ASSIGN,
+	GoParserMINUS:     token.SUB_ASSIGN,
+	GoParserOR:        token.OR_ASSIGN,
+	GoParserCARET:     token.XOR_ASSIGN,
+	GoParserSTAR:      token.MUL_ASSIGN,
+	GoParserDIV:       token.QUO_ASSIGN,
+	GoParserMOD:       token.REM_ASSIGN,
+	GoParserLSHIFT:    token.SHL_ASSIGN,
+	GoParserRSHIFT:    token.SHR_ASSIGN,
+	GoParserAMPERSAND: token.AND_ASSIGN,
+	GoParserBIT_CLEAR: token.AND_NOT_ASSIGN,
+}
+
+func (b *astBuilder) assignment(ctx *AssignmentContext) *ast.AssignStmt {
+	s := &ast.AssignStmt{
+		Lhs: b.expressionList(ctx.ExpressionList(0)),
+		Tok: token.ASSIGN,
+		Rhs: b.expressionList(ctx.ExpressionList(1)),
+	}
+	if op := ctx.Assign_op(); op != nil {
+		first := op.GetStart()
+		s.TokPos = b.pos(first)
+		if tok, ok := assignOps[first.GetTokenType()]; ok {
+			s.Tok = tok
+		}
+	}
+	return s
+}
+
+func (b *astBuilder) ifStmt(ctx *IfStmtContext) *ast.IfStmt {
+	s := &ast.IfStmt{
+		If:   b.node(ctx.IF()),
+		Init: b.initStmt(ctx.TerminatedSimpleStmt()),
+		Cond: b.expression(ctx.Expression()),
+		Body: b.body(ctx.Block(0)),
+	}
+	if elseIf, ok := ctx.IfStmt().(*IfStmtContext); ok {
+		s.Else = b.ifStmt(elseIf)
+	} else if ctx.Block(1) != nil {
+		s.Else = b.block(ctx.Block(1))
+	}
+	return s
+}
+
+func (b *astBuilder) caseClause(keyword antlr.Token, list []ast.Expr, colon antlr.TerminalNode, body IStatementListContext) *ast.CaseClause {
+	return &ast.CaseClause{
+		Case:  b.pos(keyword),
+		List:  list,
+		Colon: b.node(colon),
+		Body:  b.statementList(body),
+	}
+}
+
+func (b *astBuilder) exprSwitchStmt(ctx *ExprSwitchStmtContext) *ast.SwitchStmt {
+	s := &ast.SwitchStmt{
+		Switch: b.node(ctx.SWITCH()),
+		Init:   b.initStmt(ctx.TerminatedSimpleStmt()),
+		Body:   &ast.BlockStmt{Lbrace: b.node(ctx.L_CURLY()), Rbrace: b.node(ctx.R_CURLY())},
+	}
+	if ctx.Expression() != nil {
+		s.Tag = b.expression(ctx.Expression())
+	}
+	for _, child := range ctx.GetChildren() {
+		clause, ok := child.(*ExprCaseClauseContext)
+		if !ok {
+			continue
+		}
+		var list []ast.Expr
+		var keyword antlr.Token
+		if sc, ok := clause.ExprSwitchCase().(*ExprSwitchCaseContext); ok {
+			keyword = sc.GetStart()
+			list = b.expressionList(sc.ExpressionList())
+		}
+		s.Body.List = append(s.Body.List, b.caseClause(keyword, list, clause.COLON(), clause.StatementList()))
+	}
+	return s
+}
+
+func (b *astBuilder) typeSwitchStmt(ctx *TypeSwitchStmtContext) *ast.TypeSwitchStmt {
+	s := &ast.TypeSwitchStmt{
+		Switch: b.node(ctx.SWITCH()),
+		Init:   b.initStmt(ctx.TerminatedSimpleStmt()),
+		Body:   &ast.BlockStmt{Lbrace: b.node(ctx.L_CURLY()), Rbrace: b.node(ctx.R_CURLY())},
+	}
+	if guard, ok := ctx.TypeSwitchGuard().(*TypeSwitchGuardContext); ok {
+		x := &ast.TypeAssertExpr{
+			X:      b.primaryExpr(guard.PrimaryExpr()),
+			Lparen: b.node(guard.L_PAREN()),
+			Rparen: b.node(guard.R_PAREN()),
+		}
+		if id := guard.IDENTIFIER(); id != nil {
+			s.Assign = &ast.AssignStmt{
+				Lhs:    []ast.Expr{b.ident(id)},
+				TokPos: b.node(guard.DECLARE_ASSIGN()),
+				Tok:    token.DEFINE,
+				Rhs:    []ast.Expr{x},
+			}
+		} else {
+			s.Assign = &ast.ExprStmt{X: x}
+		}
+	} else {
+		s.Assign = b.badStmt(ctx)
+	}
+	for _, child := range ctx.GetChildren() {
+		clause, ok := child.(*TypeCaseClauseContext)
+		if !ok {
+			continue
+		}
+		var list []ast.Expr
+		var keyword antlr.Token
+		if sc, ok := clause.TypeSwitchCase().(*TypeSwitchCaseContext); ok {
+			keyword = sc.GetStart()
+			list = b.typeList(sc.TypeList())
+		}
+		s.Body.List = append(s.Body.List, b.caseClause(keyword, list, clause.COLON(), clause.StatementList()))
+	}
+	return s
+}
+
+func (b *astBuilder) selectStmt(ctx *SelectStmtContext) *ast.SelectStmt {
+	s := &ast.SelectStmt{
+		Select: b.node(ctx.SELECT()),
+		Body:   &ast.BlockStmt{Lbrace: b.node(ctx.L_CURLY()), Rbrace: b.node(ctx.R_CURLY())},
+	}
+	for _, child := range ctx.GetChildren() {
+		clause, ok := child.(*CommClauseContext)
+		if !ok {
+			continue
+		}
+		cc := &ast.CommClause{Colon: b.node(clause.COLON()), Body: b.statementList(clause.StatementList())}
+		if cs, ok := clause.CommCase().(*CommCaseContext); ok {
+			cc.Case = b.pos(cs.GetStart())
+			if send, ok := cs.SendStmt().(*SendStmtContext); ok {
+				cc.Comm = b.sendStmt(send)
+			}
+			if recv, ok := cs.RecvStmt().(*RecvStmtContext); ok {
+				cc.Comm = b.recvStmt(recv)
+			}
+		}
+		s.Body.List = append(s.Body.List, cc)
+	}
+	return s
+}
+
+func (b *astBuilder) recvStmt(ctx *RecvStmtContext) ast.Stmt {
+	recv := b.expression(ctx.GetRecvExpr())
+	switch {
+	case ctx.ExpressionList() != nil:
+		return &ast.AssignStmt{
+			Lhs:    b.expressionList(ctx.ExpressionList()),
+			TokPos: b.node(ctx.ASSIGN()),
+			Tok:    token.ASSIGN,
+			Rhs:    []ast.Expr{recv},
+		}
+	case ctx.IdentifierList() != nil:
+		var lhs []ast.Expr
+		for _, ident := range b.identifierList(ctx.IdentifierList()) {
+			lhs = append(lhs, ident)
+		}
+		return &ast.AssignStmt{
+			Lhs:    lhs,
+			TokPos: b.node(ctx.DECLARE_ASSIGN()),
+			Tok:    token.DEFINE,
+			Rhs:    []ast.Expr{recv},
+		}
+	}
+	return &ast.ExprStmt{X: recv}
+}
+
+func (b *astBuilder) forStmt(ctx *ForStmtContext) ast.Stmt {
+	forPos := b.node(ctx.FOR())
+	body := b.body(ctx.Block())
+	switch c := alternative(ctx).(type) {
+	case *ExpressionContext:
+		return &ast.ForStmt{For: forPos, Cond: b.expression(c), Body: body}
+	case *ForClauseContext:
+		s := &ast.ForStmt{For: forPos, Init: b.initStmt(c.GetInitStmt()), Body: body}
+		if c.Expression() != nil {
+			s.Cond = b.expression(c.Expression())
+		}
+		if post, ok := c.GetPostStmt().(*SimpleStmtContext); ok && post.EmptyStmt() == nil {
+			s.Post = b.simpleStmt(post)
+		}
+		return s
+	case *RangeClauseContext:
+		s := &ast.RangeStmt{For: forPos, Range: b.node(c.RANGE()), X: b.expression(c.Expression()), Body: body}
+		var lhs []ast.Expr
+		switch {
+		case c.ExpressionList() != nil:
+			lhs = b.expressionList(c.ExpressionList())
+			s.TokPos, s.Tok = b.node(c.ASSIGN()), token.ASSIGN
+		case c.IdentifierList() != nil:
+			for _, ident := range b.identifierList(c.IdentifierList()) {
+				lhs = append(lhs, ident)
+			}
+			s.TokPos, s.Tok = b.node(c.DECLARE_ASSIGN()), token.DEFINE
+		}
+		if len(lhs) > 0 {
+			s.Key = lhs[0]
+		}
+		if len(lhs) > 1 {
+			s.Value = lhs[1]
+		}
+		return s
+	}
+	return &ast.ForStmt{For: forPos, Body: body}
+}
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+)
+
+// TestBuildASTSyntaxErrors builds the AST of files with syntax errors:
+// the builder must not panic on the children error recovery leaves out,
+// and must keep the declarations around the error.
+func TestBuildASTSyntaxErrors(t *testing.T) {
+	srcs := []string{
+		"x := \n\treturn",
+		"g(1, \n",
+		"if { }",
+		"for i := 0; i < ; i++ {}",
+		"switch x. { case 1: }",
+		"select { case <-: }",
+		"_ = a + / b",
+		"_ = []int{1, 2,",
+		"_ = struct{ a int; b }{",
+		"_ = x.(",
+		"_ = m[",
+		"_ = s[1:2:]",
+		"var x [ ]",
+		"type T interface { ~int | }",
+		"defer",
+		"go func() {",
+		"L: goto",
+		"_ = func(a, b ...) {}",
+		"_ = map[string]{}",
+		"_ = chan<-",
+		"_ = (",
+		"var _ = f[int,,](1)",
+	}
+	for _, src := range srcs {
+		file := "package p\n\nfunc before() {}\n\nfunc f() {\n\t" + src + "\n}\n\nfunc after() {}\n"
+		f, errs := ParseGoAST(token.NewFileSet(), "p.go", file)
+		if len(errs) == 0 {
+			t.Errorf("%q: no syntax errors", src)
+		}
+		if f.Name.Name != "p" {
+			t.Errorf("%q: package %s, want p", src, f.Name.Name)
+		}
+		funcs := map[string]bool{}
+		for _, d := range f.Decls {
+			if fn, ok := d.(*ast.FuncDecl); ok {
+				funcs[fn.Name.Name] = true
+			}
+		}
+		if !funcs["before"] {
+			t.Errorf("%q: func before is missing", src)
+		}
+		ast.Inspect(f, func(ast.Node) bool { return true })
+	}
+}
+
+func TestBuildASTBadNodes(t *testing.T) {
+	f, errs := ParseGoAST(token.NewFileSet(), "p.go", "package p\n\nfunc f() {\n\tx := \n\treturn\n}\n\nvar y = 1 +\n")
+	if len(errs) == 0 {
+		t.Fatal("no syntax errors")
+	}
+	bad := 0
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.BadExpr, *ast.BadStmt, *ast.BadDecl:
+			bad++
+		}
+		return true
+	})
+	if bad == 0 {
+		t.Error("no bad nodes")
+	}
+}
@@ -0,0 +1,252 @@
+package parser
+
+import (
+	"go/build/constraint"
+	"regexp"
+	"strings"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// CommentGroup is a sequence of COMMENT and LINE_COMMENT tokens with
+// no blank line or other token between them.
+type CommentGroup struct {
+	List []antlr.Token
+}
+
+// Text returns the text of the comment without comment markers and
+// directives, like go/ast's CommentGroup.Text.
+func (g *CommentGroup) Text() string {
+	if g == nil {
+		return ""
+	}
+	var lines []string
+	for _, c := range g.List {
+		text := c.GetText()
+		if _, ok := parseDirective(c); ok {
+			continue
+		}
+		if strings.HasPrefix(text, "//") {
+			lines = append(lines, strings.TrimPrefix(text[2:], " "))
+			continue
+		}
+		for _, line := range strings.Split(text[2:len(text)-2], "\n") {
+			lines = append(lines, strings.TrimRight(line, " \t\r"))
+		}
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Directives returns the directive comments of the group in order.
+func (g *CommentGroup) Directives() []Directive {
+	if g == nil {
+		return nil
+	}
+	var directives []Directive
+	for _, c := range g.List {
+		if d, ok := parseDirective(c); ok {
+			directives = append(directives, d)
+		}
+	}
+	return directives
+}
+
+// Directive is a line comment addressed to a tool rather than to the
+// reader, such as //go:generate or //go:noinline. Tool is the part
+// before the colon and Name the part after it; Args is the rest of the
+// line. The //line, //export and //extern comments of the compiler and
+// cgo have no tool.
+type Directive struct {
+	Tool string
+	Name string
+	Args string
+	Pos  Position
+}
+
+// Same syntax as go/ast.isDirective.
+var directiveComment = regexp.MustCompile(`^//([a-z0-9]+):([a-z0-9]\S*)(?:[ \t]+(.*))?$`)
+
+func parseDirective(t antlr.Token) (Directive, bool) {
+	text := strings.TrimRight(t.GetText(), " \t\r")
+	pos := Position{t.GetLine(), t.GetColumn()}
+	if m := directiveComment.FindStringSubmatch(text); m != nil {
+		return Directive{Tool: m[1], Name: m[2], Args: strings.TrimSpace(m[3]), Pos: pos}, true
+	}
+	for _, name := range []string{"line", "export", "extern"} {
+		if strings.HasPrefix(text, "//"+name+" ") {
+			return Directive{Name: name, Args: strings.TrimSpace(text[len(name)+3:]), Pos: pos}, true
+		}
+	}
+	return Directive{}, false
+}
+
+// BuildConstraint is a //go:build or legacy // +build line.
+type BuildConstraint struct {
+	Expr constraint.Expr
+	Plus bool // a // +build line
+	Pos  Position
+}
+
+// DeclComments are the comments attached to a declaration: the doc
+// comment on the lines right above it and the comment following it on
+// its last line. Either may be nil.
+type DeclComments struct {
+	Doc  *CommentGroup
+	Line *CommentGroup
+}
+
+// AttachComments returns the comments of every function, method, type
+// spec and struct field of source that has any.
+func AttachComments(source *GoSource) map[antlr.ParserRuleContext]DeclComments {
+	a := &commentAttacher{tokens: source.Tokens, comments: map[antlr.ParserRuleContext]DeclComments{}}
+	antlr.ParseTreeWalkerDefault.Walk(a, source.Tree)
+	return a.comments
+}
+
+type commentAttacher struct {
+	BaseGoParserListener
+
+	tokens   *antlr.CommonTokenStream
+	comments map[antlr.ParserRuleContext]DeclComments
+}
+
+func (a *commentAttacher) attach(ctx, docOf antlr.ParserRuleContext) {
+	c := DeclComments{DocComment(a.tokens, docOf), LineComment(a.tokens, ctx)}
+	if c.Doc != nil || c.Line != nil {
+		a.comments[ctx] = c
+	}
+}
+
+func (a *commentAttacher) EnterFunctionDecl(ctx *FunctionDeclContext) {
+	a.attach(ctx, ctx)
+}
+
+func (a *commentAttacher) EnterMethodDecl(ctx *MethodDeclContext) {
+	a.attach(ctx, ctx)
+}
+
+// A type spec outside parentheses is documented by the comment above
+// the type keyword.
+func (a *commentAttacher) EnterTypeSpec(ctx *TypeSpecContext) {
+	if decl, ok := ctx.GetParent().(*TypeDeclContext); ok && decl.L_PAREN() == nil {
+		a.attach(ctx, decl)
+		return
+	}
+	a.attach(ctx, ctx)
+}
+
+func (a *commentAttacher) EnterFieldDecl(ctx *FieldDeclContext) {
+	a.attach(ctx, ctx)
+}
+
+// DocComment returns the comment group ending on the line right above
+// ctx, if it starts on a line of its own.
+func DocComment(tokens *antlr.CommonTokenStream, ctx antlr.ParserRuleContext) *CommentGroup {
+	groups, adjacent := commentGroupsBefore(tokens, ctx.GetStart().GetTokenIndex())
+	if !adjacent {
+		return nil
+	}
+	return groups[len(groups)-1]
+}
+
+// LineComment returns the comments following ctx on its last line.
+func LineComment(tokens *antlr.CommonTokenStream, ctx antlr.ParserRuleContext) *CommentGroup {
+	stop := ctx.GetStop()
+	if stop == nil || stop.GetTokenIndex() < 0 {
+		return nil
+	}
+	// GoLexerBase puts an EOS before a comment that ends the line, after
+	// the white space that follows the last token.
+	group := &CommentGroup{}
+	eos := false
+tokens:
+	for i := stop.GetTokenIndex() + 1; i < len(tokens.GetAllTokens()); i++ {
+		t := tokens.Get(i)
+		switch t.GetTokenType() {
+		case GoLexerWS:
+		case GoLexerEOS:
+			if eos {
+				break tokens
+			}
+			eos = true
+		case GoLexerCOMMENT:
+			group.List = append(group.List, t)
+		case GoLexerLINE_COMMENT:
+			group.List = append(group.List, t)
+			break tokens
+		default:
+			break tokens
+		}
+	}
+	if len(group.List) == 0 {
+		return nil
+	}
+	return group
+}
+
+// commentGroupsBefore splits the comments between the token at index
+// and the previous default channel token into groups. It also reports
+// whether the last group ends on the line right above the token and
+// starts on a line of its own, which makes it a doc comment.
+func commentGroupsBefore(tokens *antlr.CommonTokenStream, index int) ([]*CommentGroup, bool) {
+	hidden := tokens.GetHiddenTokensToLeft(index, antlr.TokenHiddenChannel)
+	var groups []*CommentGroup
+	var group *CommentGroup
+	newlines := 0
+	ownLine := false
+	for _, t := range hidden {
+		switch t.GetTokenType() {
+		case GoLexerTERMINATOR:
+			newlines += strings.Count(t.GetText(), "\n")
+		case GoLexerCOMMENT, GoLexerLINE_COMMENT:
+			// A comment on the line of the previous token is a group
+			// of its own, like a line comment in go/ast.
+			if group == nil || newlines > 1 || newlines > 0 && !ownLine {
+				group = &CommentGroup{}
+				groups = append(groups, group)
+				ownLine = newlines > 0 || hidden[0].GetTokenIndex() == 0
+			}
+			group.List = append(group.List, t)
+			newlines = 0
+		}
+	}
+	return groups, group != nil && ownLine && newlines <= 1
+}
+
+// FileBuildConstraints returns the build constraints in the header of
+// a source file, before the package clause. As with go build, // +build
+// lines only count when a blank line separates them from the package
+// clause.
+func FileBuildConstraints(source *GoSource) ([]BuildConstraint, error) {
+	pkg, ok := source.Tree.PackageClause().(*PackageClauseContext)
+	if !ok {
+		return nil, nil
+	}
+	groups, adjacent := commentGroupsBefore(source.Tokens, pkg.GetStart().GetTokenIndex())
+	var constraints []BuildConstraint
+	for i, group := range groups {
+		doc := adjacent && i == len(groups)-1
+		for _, c := range group.List {
+			text := c.GetText()
+			plus := constraint.IsPlusBuild(text)
+			if !constraint.IsGoBuild(text) && (!plus || doc) {
+				continue
+			}
+			expr, err := constraint.Parse(text)
+			if err != nil {
+				return constraints, SyntaxError{c.GetLine(), c.GetColumn(), err.Error()}
+			}
+			constraints = append(constraints, BuildConstraint{expr, plus, Position{c.GetLine(), c.GetColumn()}})
+		}
+	}
+	return constraints, nil
+}
@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+func TestAttachComments(t *testing.T) {
+	src := `package p
+
+// T is documented.
+type T struct {
+	// Name is documented.
+	Name string // c
+	Age  int    /*d*/ // e
+	tags []string
+}
+
+// F is documented.
+func F() {} // f
+
+func (T) M() {
+} /*m*/
+
+func G() {}
+
+// H is not documented.
+
+func H() {}
+`
+	source := ParseGoString("p.go", src)
+	if len(source.Errors) > 0 {
+		t.Fatal(source.Errors)
+	}
+	texts := map[string][2]string{}
+	for ctx, c := range AttachComments(source) {
+		texts[declName(ctx)] = [2]string{c.Doc.Text(), c.Line.Text()}
+	}
+	want := map[string][2]string{
+		"T":    {"T is documented.\n", ""},
+		"Name": {"Name is documented.\n", "c\n"},
+		"Age":  {"", "d\ne\n"},
+		"F":    {"F is documented.\n", "f\n"},
+		"M":    {"", "m\n"},
+	}
+	for name, w := range want {
+		if got, ok := texts[name]; !ok || got != w {
+			t.Errorf("%s: comments %q, want %q", name, got, w)
+		}
+	}
+	for name := range texts {
+		if _, ok := want[name]; !ok {
+			t.Errorf("%s: unexpected comments %q", name, texts[name])
+		}
+	}
+}
+
+func declName(ctx antlr.ParserRuleContext) string {
+	switch ctx := ctx.(type) {
+	case *FunctionDeclContext:
+		return ctx.IDENTIFIER().GetText()
+	case *MethodDeclContext:
+		return ctx.IDENTIFIER().GetText()
+	case *TypeSpecContext:
+		return ctx.IDENTIFIER().GetText()
+	case *FieldDeclContext:
+		if ctx.IdentifierList() != nil {
+			return ctx.IdentifierList().GetText()
+		}
+	}
+	return ctx.GetText()
+}
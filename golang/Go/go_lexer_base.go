@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// GoLexerBase state
+type GoLexerBase struct {
+	*antlr.BaseLexer
+
+	lastToken antlr.Token
+	pending   antlr.Token
+}
+
+// NextToken from the character stream. Like go/scanner it inserts an
+// EOS token when a line ends after a token that may end a statement:
+// an identifier, a literal, one of the keywords break, continue,
+// fallthrough or return, one of ++ -- ) ] }. A general comment that
+// spans lines counts as a line end, and so does the end of input.
+func (l *GoLexerBase) NextToken() antlr.Token {
+	if l.pending != nil {
+		next := l.pending
+		l.pending = nil
+		return next
+	}
+
+	next := l.BaseLexer.NextToken() // Get next token
+	if l.lastToken != nil && insertsSemicolon(l.lastToken.GetTokenType()) && endsLine(next) {
+		l.pending = next
+		next = l.eos(next)
+	}
+	if next.GetChannel() == antlr.TokenDefaultChannel {
+		// Keep track of the last token on default channel
+		l.lastToken = next
+	}
+	return next
+}
+
+// eos creates an empty EOS token placed at the start of the token
+// that ends the line.
+func (l *GoLexerBase) eos(at antlr.Token) antlr.Token {
+	return l.GetTokenFactory().Create(l.GetTokenSourceCharStreamPair(), GoLexerEOS, "\n",
+		antlr.TokenDefaultChannel, at.GetStart(), at.GetStart()-1, at.GetLine(), at.GetColumn())
+}
+
+// insertsSemicolon returns true if a line ending after a token of the
+// given type ends the statement.
+func insertsSemicolon(tokenType int) bool {
+	switch tokenType {
+	case GoLexerIDENTIFIER, GoLexerNIL_LIT,
+		GoLexerDECIMAL_LIT, GoLexerBINARY_LIT, GoLexerOCTAL_LIT, GoLexerHEX_LIT,
+		GoLexerFLOAT_LIT, GoLexerIMAGINARY_LIT, GoLexerRUNE_LIT,
+		GoLexerRAW_STRING_LIT, GoLexerINTERPRETED_STRING_LIT,
+		GoLexerBREAK, GoLexerCONTINUE, GoLexerFALLTHROUGH, GoLexerRETURN,
+		GoLexerPLUS_PLUS, GoLexerMINUS_MINUS,
+		GoLexerR_PAREN, GoLexerR_BRACKET, GoLexerR_CURLY:
+		return true
+	default:
+		return false
+	}
+}
+
+// endsLine returns true if t ends the current line. A line comment
+// always runs to the end of its line; the EOS goes before it, as
+// go/scanner does.
+func endsLine(t antlr.Token) bool {
+	switch t.GetTokenType() {
+	case GoLexerTERMINATOR, GoLexerLINE_COMMENT, antlr.TokenEOF:
+		return true
+	case GoLexerCOMMENT:
+		return strings.ContainsAny(t.GetText(), "\r\n")
+	default:
+		return false
+	}
+}
@@ -0,0 +1,182 @@
+package parser
+
+import (
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// GoMetricsListener computes the FunctionMetrics of the functions,
+// methods and function literals of a Go file. Cyclomatic complexity
+// counts if and for statements, the cases of switch and select
+// statements and the && and || operators. Cognitive complexity adds
+// if, for, switch and select statements weighted by their nesting,
+// else branches, sequences of like boolean operators and jumps to
+// labels.
+type GoMetricsListener struct {
+	BaseGoParserListener
+	*MetricsCounter
+}
+
+// NewGoMetricsListener returns a listener for the tree of source.
+func NewGoMetricsListener(source *GoSource) *GoMetricsListener {
+	return &GoMetricsListener{MetricsCounter: NewMetricsCounter(source.Name, source.Tokens)}
+}
+
+// GoFunctionMetrics returns the metrics of the functions of source.
+func GoFunctionMetrics(source *GoSource) []FunctionMetrics {
+	l := NewGoMetricsListener(source)
+	antlr.ParseTreeWalkerDefault.Walk(l, source.Tree)
+	return l.Functions()
+}
+
+func signatureParameters(ctx ISignatureContext) int {
+	if sig, ok := ctx.(*SignatureContext); ok {
+		return len(parameterList(sig.Parameters()))
+	}
+	return 0
+}
+
+// functionName returns the text of name, or a generated name if error
+// recovery left it out.
+func (l *GoMetricsListener) functionName(name antlr.TerminalNode) string {
+	if name == nil {
+		return l.AnonymousName()
+	}
+	return name.GetText()
+}
+
+func (l *GoMetricsListener) EnterFunctionDecl(ctx *FunctionDeclContext) {
+	l.BeginFunction(l.functionName(ctx.IDENTIFIER()), signatureParameters(ctx.Signature()), ctx)
+}
+
+func (l *GoMetricsListener) ExitFunctionDecl(ctx *FunctionDeclContext) {
+	l.EndFunction()
+}
+
+// Methods are named Type.Method.
+func (l *GoMetricsListener) EnterMethodDecl(ctx *MethodDeclContext) {
+	name := l.functionName(ctx.IDENTIFIER())
+	if recv, ok := ctx.Receiver().(*ReceiverContext); ok {
+		if params := parameterList(recv.Parameters()); len(params) > 0 {
+			name = baseTypeName(params[0].typ) + "." + name
+		}
+	}
+	l.BeginFunction(name, signatureParameters(ctx.Signature()), ctx)
+}
+
+func (l *GoMetricsListener) ExitMethodDecl(ctx *MethodDeclContext) {
+	l.EndFunction()
+}
+
+func (l *GoMetricsListener) EnterFunctionLit(ctx *FunctionLitContext) {
+	l.BeginFunction(l.AnonymousName(), signatureParameters(ctx.Signature()), ctx)
+}
+
+func (l *GoMetricsListener) ExitFunctionLit(ctx *FunctionLitContext) {
+	l.EndFunction()
+}
+
+// An if statement in the else branch of another one is an else if: it
+// adds to the cognitive complexity without nesting.
+func (l *GoMetricsListener) EnterIfStmt(ctx *IfStmtContext) {
+	l.Branch()
+	if _, elseIf := ctx.GetParent().(*IfStmtContext); elseIf {
+		l.Flow()
+	} else {
+		l.Structure()
+		l.Nest()
+	}
+	if len(ctx.AllBlock()) == 2 {
+		l.Flow() // else
+	}
+}
+
+func (l *GoMetricsListener) ExitIfStmt(ctx *IfStmtContext) {
+	if _, elseIf := ctx.GetParent().(*IfStmtContext); !elseIf {
+		l.Unnest()
+	}
+}
+
+func (l *GoMetricsListener) EnterForStmt(ctx *ForStmtContext) {
+	l.Branch()
+	l.Structure()
+	l.Nest()
+}
+
+func (l *GoMetricsListener) ExitForStmt(ctx *ForStmtContext) {
+	l.Unnest()
+}
+
+func (l *GoMetricsListener) EnterSwitchStmt(ctx *SwitchStmtContext) {
+	l.Structure()
+	l.Nest()
+}
+
+func (l *GoMetricsListener) ExitSwitchStmt(ctx *SwitchStmtContext) {
+	l.Unnest()
+}
+
+func (l *GoMetricsListener) EnterSelectStmt(ctx *SelectStmtContext) {
+	l.Structure()
+	l.Nest()
+}
+
+func (l *GoMetricsListener) ExitSelectStmt(ctx *SelectStmtContext) {
+	l.Unnest()
+}
+
+func (l *GoMetricsListener) EnterExprCaseClause(ctx *ExprCaseClauseContext) {
+	if c, ok := ctx.ExprSwitchCase().(*ExprSwitchCaseContext); ok && c.DEFAULT() == nil {
+		l.Branch()
+	}
+}
+
+func (l *GoMetricsListener) EnterTypeCaseClause(ctx *TypeCaseClauseContext) {
+	if c, ok := ctx.TypeSwitchCase().(*TypeSwitchCaseContext); ok && c.DEFAULT() == nil {
+		l.Branch()
+	}
+}
+
+func (l *GoMetricsListener) EnterCommClause(ctx *CommClauseContext) {
+	if c, ok := ctx.CommCase().(*CommCaseContext); ok && c.DEFAULT() == nil {
+		l.Branch()
+	}
+}
+
+func (l *GoMetricsListener) EnterGotoStmt(ctx *GotoStmtContext) {
+	l.Flow()
+}
+
+func (l *GoMetricsListener) EnterBreakStmt(ctx *BreakStmtContext) {
+	if ctx.IDENTIFIER() != nil {
+		l.Flow()
+	}
+}
+
+func (l *GoMetricsListener) EnterContinueStmt(ctx *ContinueStmtContext) {
+	if ctx.IDENTIFIER() != nil {
+		l.Flow()
+	}
+}
+
+// Each && and || is a decision; a sequence of the same operator, as in
+// a && b && c, breaks the flow once.
+func (l *GoMetricsListener) EnterExpression(ctx *ExpressionContext) {
+	op := logicalOperator(ctx)
+	if op == 0 {
+		return
+	}
+	l.Branch()
+	if parent, ok := ctx.GetParent().(*ExpressionContext); !ok || logicalOperator(parent) != op {
+		l.Flow()
+	}
+}
+
+func logicalOperator(ctx *ExpressionContext) int {
+	switch {
+	case ctx.LOGICAL_AND() != nil:
+		return GoParserLOGICAL_AND
+	case ctx.LOGICAL_OR() != nil:
+		return GoParserLOGICAL_OR
+	}
+	return 0
+}
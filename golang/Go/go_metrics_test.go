@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const metricsSrc = `package p
+
+// sum adds the positive elements of xs that pass f.
+func sum(xs []int, f func(int) bool) (n int) {
+	for _, x := range xs {
+		if x > 0 && f(x) {
+			n += x
+		} else if x < 0 {
+			continue
+		}
+	}
+	return
+}
+
+func (s *T) Run(a, b int) int {
+	// A labelled loop.
+outer:
+	for {
+		switch {
+		case a > b:
+			break outer
+		case a < b || b == 0:
+			a++
+		default:
+			return 0
+		}
+	}
+	f := func() {
+		if a > 0 {
+			a--
+		} else {
+			a++
+		}
+	}
+	f()
+	return a
+}
+`
+
+func TestGoFunctionMetrics(t *testing.T) {
+	source := ParseGoString("p.go", metricsSrc)
+	if len(source.Errors) > 0 {
+		t.Fatal(source.Errors)
+	}
+	// sum: cyclomatic 1 + for + if + && + else if; cognitive for (1),
+	// if nested once (2), && (1), else if (1).
+	// T.Run: cyclomatic 1 + for + two cases + ||; cognitive for (1),
+	// switch nested once (2), break outer (1), || (1). The comment line
+	// does not count.
+	// T.Run.func1: cyclomatic 1 + if; cognitive if (1), else (1).
+	want := []FunctionMetrics{
+		{File: "p.go", Name: "sum", Line: 4, EndLine: 13, Parameters: 2, Cyclomatic: 5, Cognitive: 5, Lines: 10},
+		{File: "p.go", Name: "T.Run", Line: 15, EndLine: 37, Parameters: 2, Cyclomatic: 5, Cognitive: 5, Lines: 22},
+		{File: "p.go", Name: "T.Run.func1", Line: 28, EndLine: 34, Parameters: 0, Cyclomatic: 2, Cognitive: 2, Lines: 7},
+	}
+	got := GoFunctionMetrics(source)
+	if len(got) != len(want) {
+		t.Fatalf("got %d functions, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %+v\nwant %+v", got[i], want[i])
+		}
+	}
+
+	var b bytes.Buffer
+	if err := WriteMetricsCSV(&b, got[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(b.String(), "\n"); len(lines) != 3 || lines[1] != "p.go,sum,4,13,2,5,5,10" {
+		t.Errorf("got CSV\n%s", b.String())
+	}
+}
@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PrintGo regenerates the source of a GoParser tree the way gofmt
+// formats it: tab indentation, aligned struct fields, values and
+// trailing comments, normalised spacing and sorted import blocks.
+// Comments are taken from the hidden channel of the token stream.
+//
+// Syntax errors do not stop printing. Statements and expressions the
+// parser could not recover are copied from the source unchanged, so
+// partially valid snippets that go/format refuses still come out
+// formatted around the broken parts.
+func PrintGo(w io.Writer, source *GoSource) error {
+	fset := token.NewFileSet()
+	b := newASTBuilder(fset, source, sourceText(source))
+	b.verbatim = true
+	return format.Node(w, fset, b.sourceFile(source.Tree))
+}
+
+// FormatGo parses src with GoParser and returns it printed by PrintGo,
+// along with the syntax errors of the parse.
+func FormatGo(name, src string) ([]byte, []SyntaxError, error) {
+	source := ParseGoString(name, src)
+	var buf bytes.Buffer
+	if err := PrintGo(&buf, source); err != nil {
+		return nil, source.Errors, err
+	}
+	return buf.Bytes(), source.Errors, nil
+}
+
+// sourceText returns the text source was parsed from.
+func sourceText(source *GoSource) string {
+	input := source.Tokens.GetTokenSource().GetInputStream()
+	return input.GetText(0, input.Size()-1)
+}
+
+// FormatResult checks the output of FormatGo for one source file.
+type FormatResult struct {
+	Path string
+
+	// Errors reported by GoLexer and GoParser.
+	SyntaxErrors []SyntaxError
+
+	// Err is set if the file could not be printed, or if gofmt
+	// rejects the printed source.
+	Err error
+
+	// Stable is true if gofmt leaves the printed source unchanged.
+	Stable bool
+
+	// Line is the first line where gofmt changes the printed source.
+	Line int
+}
+
+// FormatReport holds the results for every file of a corpus.
+type FormatReport struct {
+	Root    string
+	Results []*FormatResult
+}
+
+// Unstable returns the results whose output gofmt would change.
+func (r *FormatReport) Unstable() []*FormatResult {
+	var unstable []*FormatResult
+	for _, result := range r.Results {
+		if !result.Stable {
+			unstable = append(unstable, result)
+		}
+	}
+	return unstable
+}
+
+// WriteTo writes a summary followed by one line per unstable file.
+func (r *FormatReport) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	unstable := r.Unstable()
+	fmt.Fprintf(&b, "%s: %d files, %d not stable under gofmt\n", r.Root, len(r.Results), len(unstable))
+	for _, u := range unstable {
+		if u.Err != nil {
+			fmt.Fprintf(&b, "%s: %v\n", u.Path, u.Err)
+		} else {
+			fmt.Fprintf(&b, "%s:%d: changed by gofmt\n", u.Path, u.Line)
+		}
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// CheckFormat prints the file at path with FormatGo and runs gofmt
+// over the output.
+func CheckFormat(path string) (*FormatResult, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	result := &FormatResult{Path: path}
+	var out []byte
+	out, result.SyntaxErrors, result.Err = FormatGo(path, string(src))
+	if result.Err != nil {
+		return result, nil
+	}
+	formatted, err := format.Source(out)
+	if err != nil {
+		result.Err = err
+		return result, nil
+	}
+	result.Stable = bytes.Equal(out, formatted)
+	if !result.Stable {
+		result.Line = firstChangedLine(out, formatted)
+	}
+	return result, nil
+}
+
+func firstChangedLine(a, b []byte) int {
+	line := 1
+	for i := 0; i < len(a) && i < len(b) && a[i] == b[i]; i++ {
+		if a[i] == '\n' {
+			line++
+		}
+	}
+	return line
+}
+
+// CheckFormatCorpus runs CheckFormat over every .go file below root,
+// skipping testdata directories like CompareCorpus.
+func CheckFormatCorpus(root string) (*FormatReport, error) {
+	report := &FormatReport{Root: root}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != root && info.Name() == "testdata" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".go" {
+			return nil
+		}
+		result, err := CheckFormat(path)
+		if err != nil {
+			return err
+		}
+		report.Results = append(report.Results, result)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"go/format"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFormatGo(t *testing.T) {
+	src := `package p
+import ("os"; "fmt")
+const x = 0X1P-2 + 0XABC
+func f( a int ) { fmt.Println( a ,os.Args ) }
+`
+	want := `package p
+
+import (
+	"fmt"
+	"os"
+)
+
+const x = 0x1p-2 + 0xABC
+
+func f(a int) { fmt.Println(a, os.Args) }
+`
+	out, errors, err := FormatGo("p.go", src)
+	if err != nil || len(errors) > 0 {
+		t.Fatal(err, errors)
+	}
+	if string(out) != want {
+		t.Errorf("got\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestCheckFormatCorpus(t *testing.T) {
+	report, err := CheckFormatCorpus(examplesDir(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Unstable()) > 0 {
+		var b strings.Builder
+		report.WriteTo(&b)
+		t.Error(b.String())
+	}
+
+	// Where gofmt accepts the original, the printed source must be
+	// what gofmt makes of it.
+	for _, result := range report.Results {
+		src, err := os.ReadFile(result.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := format.Source(src)
+		if err != nil {
+			continue
+		}
+		out, _, err := FormatGo(result.Path, string(src))
+		if err != nil {
+			t.Errorf("%s: %v", result.Path, err)
+		} else if string(out) != string(want) {
+			t.Errorf("%s:%d: printed source differs from gofmt", result.Path, firstChangedLine(out, want))
+		}
+	}
+}
@@ -0,0 +1,1077 @@
+package parser
+
+import (
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// SymbolKind is the kind of a declared name.
+type SymbolKind int
+
+const (
+	PackageSymbol SymbolKind = iota // imported package
+	ConstSymbol
+	TypeSymbol
+	VarSymbol
+	FuncSymbol
+	MethodSymbol
+	FieldSymbol
+	LabelSymbol
+)
+
+var symbolKinds = [...]string{"package", "const", "type", "var", "func", "method", "field", "label"}
+
+func (k SymbolKind) String() string {
+	return symbolKinds[k]
+}
+
+// Symbol is a declared name. Predeclared symbols such as int or len
+// have no File, Ident or Decl. For fields and methods Scope is the
+// scope of their type, although they are not part of it.
+type Symbol struct {
+	Name  string
+	Kind  SymbolKind
+	File  string
+	Ident antlr.Token
+	Decl  antlr.ParserRuleContext
+	Scope *Scope
+
+	typ      antlr.Tree         // declared type, or the result of a function
+	value    IExpressionContext // initializer, when there is no declared type
+	members  map[string]*Symbol // fields and interface methods of a type
+	embedded []antlr.Tree       // embedded fields and interfaces of a type
+	refs     []Reference
+}
+
+// Reference is an identifier in a file.
+type Reference struct {
+	File  string
+	Token antlr.Token
+}
+
+// Pos returns the position of the identifier.
+func (r Reference) Pos() Position {
+	return Position{r.Token.GetLine(), r.Token.GetColumn()}
+}
+
+// ScopeKind tells which construct opened a scope.
+type ScopeKind int
+
+const (
+	UniverseScope ScopeKind = iota
+	PackageScope
+	FileScope
+	FuncScope
+	BlockScope
+)
+
+// Scope maps names to the symbols declared in a block. Node is the
+// construct that opened it: a source file, function, type spec with
+// type parameters, block, statement or case clause.
+type Scope struct {
+	Kind     ScopeKind
+	Node     antlr.Tree
+	Parent   *Scope
+	Children []*Scope
+	Symbols  map[string]*Symbol
+
+	labels map[string]*Symbol // function scopes only
+}
+
+func newScope(kind ScopeKind, node antlr.Tree, parent *Scope) *Scope {
+	s := &Scope{Kind: kind, Node: node, Parent: parent, Symbols: map[string]*Symbol{}}
+	if parent != nil {
+		parent.Children = append(parent.Children, s)
+	}
+	return s
+}
+
+// Lookup returns the symbol name refers to in s, looking through the
+// enclosing scopes.
+func (s *Scope) Lookup(name string) *Symbol {
+	for ; s != nil; s = s.Parent {
+		if sym, ok := s.Symbols[name]; ok {
+			return sym
+		}
+	}
+	return nil
+}
+
+func (s *Scope) function() *Scope {
+	for ; s != nil; s = s.Parent {
+		if s.Kind == FuncScope {
+			return s
+		}
+	}
+	return nil
+}
+
+// PackageInfo is the result of resolving the files of a package. Like
+// go/types.Info, Defs maps declaring identifiers to their symbol and
+// Uses every other resolved identifier. Selections are resolved on a
+// best effort basis: the type of a variable is known when it is
+// declared, or initialized with a composite literal, a conversion, a
+// call of a declared function or another variable of known type.
+type PackageInfo struct {
+	Universe *Scope
+	Package  *Scope
+	Files    map[string]*Scope
+	Defs     map[antlr.Token]*Symbol
+	Uses     map[antlr.Token]*Symbol
+
+	sources []*GoSource
+	methods map[string][]*Symbol // by receiver base type name
+	typing  map[*Symbol]bool
+}
+
+// ResolvePackage binds the identifiers of the files of a package to
+// their declarations.
+func ResolvePackage(sources ...*GoSource) *PackageInfo {
+	p := &PackageInfo{
+		Universe: universe(),
+		Files:    map[string]*Scope{},
+		Defs:     map[antlr.Token]*Symbol{},
+		Uses:     map[antlr.Token]*Symbol{},
+		sources:  sources,
+		methods:  map[string][]*Symbol{},
+		typing:   map[*Symbol]bool{},
+	}
+	p.Package = newScope(PackageScope, nil, p.Universe)
+	for _, source := range sources {
+		p.declarePackage(source)
+	}
+	for _, source := range sources {
+		antlr.ParseTreeWalkerDefault.Walk(&resolver{info: p, file: source.Name}, source.Tree)
+	}
+	return p
+}
+
+// SymbolAt returns the symbol the identifier at pos in file declares or
+// refers to.
+func (p *PackageInfo) SymbolAt(file string, pos Position) *Symbol {
+	for _, source := range p.sources {
+		if source.Name != file {
+			continue
+		}
+		for _, t := range source.Tokens.GetAllTokens() {
+			if t.GetTokenType() != GoLexerIDENTIFIER || t.GetLine() != pos.Line {
+				continue
+			}
+			if pos.Column >= t.GetColumn() && pos.Column < tokenEnd(t).Column {
+				if sym := p.Defs[t]; sym != nil {
+					return sym
+				}
+				return p.Uses[t]
+			}
+		}
+	}
+	return nil
+}
+
+// Definition returns where the symbol of the identifier at pos in file
+// is declared.
+func (p *PackageInfo) Definition(file string, pos Position) (Reference, bool) {
+	sym := p.SymbolAt(file, pos)
+	if sym == nil || sym.Ident == nil {
+		return Reference{}, false
+	}
+	return Reference{sym.File, sym.Ident}, true
+}
+
+// References returns the uses of the symbol of the identifier at pos
+// in file, in file order.
+func (p *PackageInfo) References(file string, pos Position) []Reference {
+	if sym := p.SymbolAt(file, pos); sym != nil {
+		return sym.refs
+	}
+	return nil
+}
+
+func (p *PackageInfo) define(scope *Scope, sym *Symbol) {
+	if sym.Name == "_" {
+		return
+	}
+	p.Defs[sym.Ident] = sym
+	if scope != nil {
+		scope.Symbols[sym.Name] = sym
+		sym.Scope = scope
+	}
+}
+
+func (p *PackageInfo) use(file string, t antlr.Token, sym *Symbol) {
+	if sym == nil {
+		return
+	}
+	p.Uses[t] = sym
+	sym.refs = append(sym.refs, Reference{file, t})
+}
+
+// Declarations
+
+// declarePackage declares the package level names of a file, so that
+// they can be used before their declaration and from other files.
+func (p *PackageInfo) declarePackage(source *GoSource) {
+	for _, child := range source.Tree.GetChildren() {
+		switch d := child.(type) {
+		case *FunctionDeclContext:
+			if d.IDENTIFIER() == nil {
+				continue // left out by error recovery
+			}
+			sym := &Symbol{Name: d.IDENTIFIER().GetText(), Kind: FuncSymbol, File: source.Name, Ident: d.IDENTIFIER().GetSymbol(), Decl: d}
+			sym.typ = resultOf(d.Signature())
+			if sym.Name == "init" {
+				sym.Scope = p.Package
+				p.define(nil, sym)
+				continue
+			}
+			p.define(p.Package, sym)
+		case *MethodDeclContext:
+			if d.IDENTIFIER() == nil {
+				continue
+			}
+			sym := &Symbol{Name: d.IDENTIFIER().GetText(), Kind: MethodSymbol, File: source.Name, Ident: d.IDENTIFIER().GetSymbol(), Decl: d, Scope: p.Package}
+			sym.typ = resultOf(d.Signature())
+			p.define(nil, sym)
+			if recv, ok := d.Receiver().(*ReceiverContext); ok {
+				if params := parameterList(recv.Parameters()); len(params) > 0 {
+					if base := baseTypeName(params[0].typ); base != "" {
+						p.methods[base] = append(p.methods[base], sym)
+					}
+				}
+			}
+		case *DeclarationContext:
+			p.declareDeclaration(d, p.Package, source.Name)
+		}
+	}
+}
+
+func (p *PackageInfo) declareDeclaration(d *DeclarationContext, scope *Scope, file string) {
+	decl := alternative(d)
+	if decl == nil {
+		return
+	}
+	for _, child := range decl.GetChildren() {
+		switch spec := child.(type) {
+		case *ConstSpecContext:
+			p.declareConst(spec, scope, file)
+		case *VarSpecContext:
+			p.declareVar(spec, scope, file)
+		case *TypeSpecContext:
+			p.declareType(spec, scope, file)
+		}
+	}
+}
+
+func (p *PackageInfo) declareConst(spec *ConstSpecContext, scope *Scope, file string) {
+	p.declareValues(ConstSymbol, spec, spec.IdentifierList(), spec.Type_(), spec.ExpressionList(), scope, file)
+}
+
+func (p *PackageInfo) declareVar(spec *VarSpecContext, scope *Scope, file string) {
+	p.declareValues(VarSymbol, spec, spec.IdentifierList(), spec.Type_(), spec.ExpressionList(), scope, file)
+}
+
+func (p *PackageInfo) declareValues(kind SymbolKind, decl antlr.ParserRuleContext, names IIdentifierListContext, typ IType_Context, values IExpressionListContext, scope *Scope, file string) {
+	ids := identifiers(names)
+	var exprs []IExpressionContext
+	if list, ok := values.(*ExpressionListContext); ok && len(list.AllExpression()) == len(ids) {
+		exprs = list.AllExpression()
+	}
+	for i, id := range ids {
+		sym := &Symbol{Name: id.GetText(), Kind: kind, File: file, Ident: id.GetSymbol(), Decl: decl}
+		if typ != nil {
+			sym.typ = typ
+		} else if exprs != nil {
+			sym.value = exprs[i]
+		}
+		p.define(scope, sym)
+	}
+}
+
+// declareType declares a type and the fields or methods of its struct
+// or interface type literal.
+func (p *PackageInfo) declareType(spec *TypeSpecContext, scope *Scope, file string) {
+	if spec.IDENTIFIER() == nil {
+		return
+	}
+	t := &Symbol{Name: spec.IDENTIFIER().GetText(), Kind: TypeSymbol, File: file, Ident: spec.IDENTIFIER().GetSymbol(), Decl: spec, typ: spec.Type_()}
+	p.define(scope, t)
+	t.Scope = scope
+	t.members = map[string]*Symbol{}
+
+	lit := typeLitOf(spec.Type_())
+	if lit == nil {
+		return
+	}
+	member := func(kind SymbolKind, id antlr.TerminalNode, decl antlr.ParserRuleContext, typ antlr.Tree) {
+		if id == nil {
+			return
+		}
+		sym := &Symbol{Name: id.GetText(), Kind: kind, File: file, Ident: id.GetSymbol(), Decl: decl, Scope: scope, typ: typ}
+		p.define(nil, sym)
+		if sym.Name != "_" {
+			t.members[sym.Name] = sym
+		}
+	}
+	if st, ok := lit.StructType().(*StructTypeContext); ok {
+		for _, child := range st.GetChildren() {
+			field, ok := child.(*FieldDeclContext)
+			if !ok {
+				continue
+			}
+			if field.IdentifierList() != nil {
+				for _, id := range identifiers(field.IdentifierList()) {
+					member(FieldSymbol, id, field, field.Type_())
+				}
+			} else if e, ok := field.EmbeddedField().(*EmbeddedFieldContext); ok {
+				if id := lastIdentifier(e.TypeName()); id != nil {
+					member(FieldSymbol, id, field, e)
+				}
+				t.embedded = append(t.embedded, e)
+			}
+		}
+	}
+	if it, ok := lit.InterfaceType().(*InterfaceTypeContext); ok {
+		for _, child := range it.GetChildren() {
+			switch c := child.(type) {
+			case *MethodSpecContext:
+				member(MethodSymbol, c.IDENTIFIER(), c, resultOf(c))
+			case *TypeElementContext:
+				if terms := c.AllTypeTerm(); len(terms) == 1 {
+					if term, ok := terms[0].(*TypeTermContext); ok {
+						t.embedded = append(t.embedded, term.Type_())
+					}
+				}
+			}
+		}
+	}
+}
+
+// declareTypeParameters declares the type parameters of a generic
+// function or type in scope.
+func (p *PackageInfo) declareTypeParameters(ctx ITypeParametersContext, scope *Scope, file string) {
+	params, ok := ctx.(*TypeParametersContext)
+	if !ok {
+		return
+	}
+	for _, child := range params.GetChildren() {
+		decl, ok := child.(*TypeParameterDeclContext)
+		if !ok {
+			continue
+		}
+		for _, id := range identifiers(decl.IdentifierList()) {
+			p.define(scope, &Symbol{Name: id.GetText(), Kind: TypeSymbol, File: file, Ident: id.GetSymbol(), Decl: decl})
+		}
+	}
+}
+
+// declareParameters declares the named parameters of a signature or
+// receiver in scope.
+func (p *PackageInfo) declareParameters(ctx IParametersContext, scope *Scope, file string) {
+	for _, param := range parameterList(ctx) {
+		if param.name != nil {
+			p.define(scope, &Symbol{Name: param.name.GetText(), Kind: VarSymbol, File: file, Ident: param.name.GetSymbol(), Decl: param.decl, typ: param.typ})
+		}
+	}
+}
+
+func (p *PackageInfo) declareSignature(ctx ISignatureContext, scope *Scope, file string) {
+	sig, ok := ctx.(*SignatureContext)
+	if !ok {
+		return
+	}
+	p.declareParameters(sig.Parameters(), scope, file)
+	if result, ok := sig.Result().(*ResultContext); ok {
+		p.declareParameters(result.Parameters(), scope, file)
+	}
+}
+
+// parameter is a parameter with the name grouping of go_ast.go: with
+// named parameters, (a, b int) is parsed as a type a followed by b int.
+type parameter struct {
+	name antlr.TerminalNode
+	typ  IType_Context
+	decl *ParameterDeclContext
+}
+
+func parameterList(ctx IParametersContext) []parameter {
+	c, ok := ctx.(*ParametersContext)
+	if !ok {
+		return nil
+	}
+	var params []parameter
+	named := false
+	for _, child := range c.GetChildren() {
+		decl, ok := child.(*ParameterDeclContext)
+		if !ok {
+			continue
+		}
+		ids, ok := decl.IdentifierList().(*IdentifierListContext)
+		if !ok {
+			params = append(params, parameter{nil, decl.Type_(), decl})
+			continue
+		}
+		named = true
+		for _, id := range ids.AllIDENTIFIER() {
+			params = append(params, parameter{id, decl.Type_(), decl})
+		}
+	}
+	if !named {
+		return params
+	}
+	var grouped []parameter
+	var pending []parameter
+	for _, param := range params {
+		if param.name == nil {
+			if id := bareTypeName(param.typ); id != nil {
+				pending = append(pending, parameter{id, nil, param.decl})
+				continue
+			}
+		}
+		for _, name := range pending {
+			grouped = append(grouped, parameter{name.name, param.typ, name.decl})
+		}
+		pending = nil
+		grouped = append(grouped, param)
+	}
+	return grouped
+}
+
+// Type syntax helpers
+
+func identifiers(ctx IIdentifierListContext) []antlr.TerminalNode {
+	if ids, ok := ctx.(*IdentifierListContext); ok {
+		return ids.AllIDENTIFIER()
+	}
+	return nil
+}
+
+// resultOf returns the type of a single result of a signature or
+// method spec, if it has exactly one.
+func resultOf(ctx antlr.Tree) antlr.Tree {
+	var result *ResultContext
+	switch c := ctx.(type) {
+	case *SignatureContext:
+		result, _ = c.Result().(*ResultContext)
+	case *MethodSpecContext:
+		result, _ = c.Result().(*ResultContext)
+	}
+	if result == nil {
+		return nil
+	}
+	if result.Type_() != nil {
+		return result.Type_()
+	}
+	if params := parameterList(result.Parameters()); len(params) == 1 {
+		return params[0].typ
+	}
+	return nil
+}
+
+func typeLitOf(ctx IType_Context) *TypeLitContext {
+	t, ok := ctx.(*Type_Context)
+	if !ok {
+		return nil
+	}
+	if t.Type_() != nil {
+		return typeLitOf(t.Type_())
+	}
+	lit, _ := t.TypeLit().(*TypeLitContext)
+	return lit
+}
+
+// bareTypeName returns the identifier of a type that is a single
+// unqualified name.
+func bareTypeName(ctx IType_Context) antlr.TerminalNode {
+	t, ok := ctx.(*Type_Context)
+	if !ok || t.TypeArgs() != nil {
+		return nil
+	}
+	if name, ok := t.TypeName().(*TypeNameContext); ok {
+		return name.IDENTIFIER()
+	}
+	return nil
+}
+
+// baseTypeName returns the name of T in T, *T or T[P].
+func baseTypeName(ctx IType_Context) string {
+	t, ok := ctx.(*Type_Context)
+	if !ok {
+		return ""
+	}
+	if t.Type_() != nil {
+		return baseTypeName(t.Type_())
+	}
+	if lit, ok := t.TypeLit().(*TypeLitContext); ok {
+		if ptr, ok := lit.PointerType().(*PointerTypeContext); ok {
+			return baseTypeName(ptr.Type_())
+		}
+		return ""
+	}
+	if id := lastIdentifier(t.TypeName()); id != nil {
+		return id.GetText()
+	}
+	return ""
+}
+
+func lastIdentifier(ctx ITypeNameContext) antlr.TerminalNode {
+	name, ok := ctx.(*TypeNameContext)
+	if !ok {
+		return nil
+	}
+	if q, ok := name.QualifiedIdent().(*QualifiedIdentContext); ok {
+		return q.IDENTIFIER(1)
+	}
+	return name.IDENTIFIER()
+}
+
+// Types
+
+// namedType returns the declared type a type expression names, looking
+// through pointers and parentheses.
+func (p *PackageInfo) namedType(node antlr.Tree, scope *Scope) *Symbol {
+	switch n := node.(type) {
+	case *Type_Context:
+		if n.TypeName() != nil {
+			return p.namedType(n.TypeName(), scope)
+		}
+		if n.Type_() != nil {
+			return p.namedType(n.Type_(), scope)
+		}
+		if lit, ok := n.TypeLit().(*TypeLitContext); ok {
+			if ptr, ok := lit.PointerType().(*PointerTypeContext); ok {
+				return p.namedType(ptr.Type_(), scope)
+			}
+		}
+	case *EmbeddedFieldContext:
+		return p.namedType(n.TypeName(), scope)
+	case *TypeNameContext:
+		id := n.IDENTIFIER()
+		if id == nil {
+			return nil // declared in another package
+		}
+		sym := p.Uses[id.GetSymbol()]
+		if sym == nil && scope != nil {
+			sym = scope.Lookup(id.GetText())
+		}
+		if sym != nil && sym.Kind == TypeSymbol {
+			return sym
+		}
+	}
+	return nil
+}
+
+// typeOf returns the declared type of the value of sym. The type of a
+// function is the type of its single result.
+func (p *PackageInfo) typeOf(sym *Symbol) *Symbol {
+	if sym == nil || p.typing[sym] {
+		return nil
+	}
+	if sym.Kind == TypeSymbol {
+		return sym
+	}
+	p.typing[sym] = true
+	defer delete(p.typing, sym)
+	if sym.typ != nil {
+		return p.namedType(sym.typ, sym.Scope)
+	}
+	if sym.value != nil {
+		return p.exprType(sym.value)
+	}
+	return nil
+}
+
+func (p *PackageInfo) exprType(ctx IExpressionContext) *Symbol {
+	e, ok := ctx.(*ExpressionContext)
+	if !ok {
+		return nil
+	}
+	if e.PrimaryExpr() != nil {
+		return p.primaryType(e.PrimaryExpr())
+	}
+	if u, ok := e.UnaryExpr().(*UnaryExprContext); ok {
+		if u.PrimaryExpr() != nil {
+			return p.primaryType(u.PrimaryExpr())
+		}
+		if op := u.GetUnary_op(); op != nil && (op.GetTokenType() == GoParserAMPERSAND || op.GetTokenType() == GoParserSTAR) {
+			return p.exprType(u.Expression())
+		}
+	}
+	return nil
+}
+
+func (p *PackageInfo) primaryType(ctx IPrimaryExprContext) *Symbol {
+	pe, ok := ctx.(*PrimaryExprContext)
+	if !ok {
+		return nil
+	}
+	switch alt := alternative(pe).(type) {
+	case *OperandContext:
+		if alt.Expression() != nil {
+			return p.exprType(alt.Expression())
+		}
+		if lit, ok := alt.Literal().(*LiteralContext); ok {
+			if c, ok := lit.CompositeLit().(*CompositeLitContext); ok {
+				if lt, ok := c.LiteralType().(*LiteralTypeContext); ok && lt.TypeName() != nil {
+					return p.namedType(lt.TypeName(), nil)
+				}
+			}
+			return nil
+		}
+		return p.typeOf(p.primarySymbol(pe))
+	case *ConversionContext:
+		return p.namedType(alt.Type_(), nil)
+	case *PrimaryExprContext:
+		if id := pe.IDENTIFIER(); id != nil {
+			return p.typeOf(p.Uses[id.GetSymbol()])
+		}
+		if pe.Arguments() == nil {
+			return nil
+		}
+		if sym := p.primarySymbol(alt); sym != nil && sym.Kind != VarSymbol && sym.Kind != FieldSymbol {
+			return p.typeOf(sym) // conversion to a named type, or call of a declared function
+		}
+	}
+	return nil
+}
+
+// primarySymbol returns the symbol a name or selector refers to.
+func (p *PackageInfo) primarySymbol(pe *PrimaryExprContext) *Symbol {
+	if pe.IDENTIFIER() != nil {
+		return p.Uses[pe.IDENTIFIER().GetSymbol()]
+	}
+	if op, ok := pe.Operand().(*OperandContext); ok {
+		if name, ok := op.OperandName().(*OperandNameContext); ok {
+			if ids := name.AllIDENTIFIER(); len(ids) > 0 {
+				return p.Uses[ids[len(ids)-1].GetSymbol()]
+			}
+		}
+	}
+	return nil
+}
+
+// lookupMember finds a field or method of the declared type t, looking
+// through embedded fields breadth first as selectors do.
+func (p *PackageInfo) lookupMember(t *Symbol, name string) *Symbol {
+	seen := map[*Symbol]bool{}
+	for level := []*Symbol{t}; len(level) > 0; {
+		var next []*Symbol
+		for _, t := range level {
+			if t == nil || seen[t] {
+				continue
+			}
+			seen[t] = true
+			if m := t.members[name]; m != nil {
+				return m
+			}
+			if t.Scope == p.Package {
+				for _, m := range p.methods[t.Name] {
+					if m.Name == name {
+						return m
+					}
+				}
+			}
+			for _, e := range t.embedded {
+				next = append(next, p.namedType(e, t.Scope))
+			}
+			if typ, ok := t.typ.(IType_Context); ok && t.members != nil && typeLitOf(typ) == nil {
+				// A type defined as another declared type has its fields.
+				next = append(next, p.namedType(t.typ, t.Scope))
+			}
+		}
+		level = next
+	}
+	return nil
+}
+
+// Predeclared names
+
+func universe() *Scope {
+	s := newScope(UniverseScope, nil, nil)
+	predeclare := func(kind SymbolKind, names ...string) {
+		for _, name := range names {
+			s.Symbols[name] = &Symbol{Name: name, Kind: kind, Scope: s}
+		}
+	}
+	predeclare(TypeSymbol, "any", "bool", "byte", "comparable", "complex64", "complex128", "error",
+		"float32", "float64", "int", "int8", "int16", "int32", "int64", "rune", "string",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr")
+	predeclare(ConstSymbol, "true", "false", "iota")
+	predeclare(VarSymbol, "nil")
+	predeclare(FuncSymbol, "append", "cap", "clear", "close", "complex", "copy", "delete", "imag",
+		"len", "make", "max", "min", "new", "panic", "print", "println", "real", "recover")
+	return s
+}
+
+// resolver walks a file with a stack of scopes, declaring local names
+// where their scope begins and binding every other identifier.
+type resolver struct {
+	BaseGoParserListener
+
+	info  *PackageInfo
+	file  string
+	scope *Scope
+	rule  antlr.ParserRuleContext // the innermost rule walked
+}
+
+// The parent of a terminal node is the BaseParserRuleContext embedded
+// in its rule rather than the rule, so the resolver keeps track of the
+// rule it is in.
+
+func (r *resolver) EnterEveryRule(ctx antlr.ParserRuleContext) {
+	r.rule = ctx
+}
+
+func (r *resolver) ExitEveryRule(ctx antlr.ParserRuleContext) {
+	r.rule, _ = ctx.GetParent().(antlr.ParserRuleContext)
+}
+
+func (r *resolver) push(kind ScopeKind, node antlr.Tree) *Scope {
+	r.scope = newScope(kind, node, r.scope)
+	return r.scope
+}
+
+func (r *resolver) pop() {
+	r.scope = r.scope.Parent
+}
+
+func (r *resolver) local() bool {
+	return r.scope.Kind != FileScope
+}
+
+func (r *resolver) EnterSourceFile(ctx *SourceFileContext) {
+	r.scope = newScope(FileScope, ctx, r.info.Package)
+	r.info.Files[r.file] = r.scope
+	for _, spec := range ImportSpecs(ctx) {
+		ident := spec.GetAlias()
+		if ident == nil {
+			ident = spec.ImportPath().GetStart()
+		}
+		name := ImportName(spec)
+		if name == "." {
+			continue // the names of the package are not known
+		}
+		r.info.define(r.scope, &Symbol{Name: name, Kind: PackageSymbol, File: r.file, Ident: ident, Decl: spec})
+	}
+}
+
+// Functions
+
+func (r *resolver) enterFunction(ctx antlr.ParserRuleContext, typeParams ITypeParametersContext, recv IReceiverContext, sig ISignatureContext, body IBlockContext) {
+	scope := r.push(FuncScope, ctx)
+	r.info.declareTypeParameters(typeParams, scope, r.file)
+	if recv, ok := recv.(*ReceiverContext); ok {
+		r.declareReceiverTypeParameters(recv, scope)
+		r.info.declareParameters(recv.Parameters(), scope, r.file)
+	}
+	r.info.declareSignature(sig, scope, r.file)
+	scope.labels = map[string]*Symbol{}
+	if body != nil {
+		r.declareLabels(body, scope)
+	}
+}
+
+// declareReceiverTypeParameters declares the names of the type
+// arguments of a receiver such as (l *List[T]) as type parameters.
+func (r *resolver) declareReceiverTypeParameters(recv *ReceiverContext, scope *Scope) {
+	params := parameterList(recv.Parameters())
+	if len(params) == 0 {
+		return
+	}
+	t, ok := params[0].typ.(*Type_Context)
+	for ok && t.TypeName() == nil {
+		var ptr *PointerTypeContext
+		if lit, isLit := t.TypeLit().(*TypeLitContext); isLit {
+			ptr, _ = lit.PointerType().(*PointerTypeContext)
+		}
+		if ptr != nil {
+			t, ok = ptr.Type_().(*Type_Context)
+		} else {
+			t, ok = t.Type_().(*Type_Context)
+		}
+	}
+	if !ok {
+		return
+	}
+	args, ok := t.TypeArgs().(*TypeArgsContext)
+	if !ok {
+		return
+	}
+	list, ok := args.TypeList().(*TypeListContext)
+	if !ok {
+		return
+	}
+	for _, arg := range list.AllType_() {
+		if id := bareTypeName(arg); id != nil {
+			r.info.define(scope, &Symbol{Name: id.GetText(), Kind: TypeSymbol, File: r.file, Ident: id.GetSymbol(), Decl: recv})
+		}
+	}
+}
+
+// declareLabels declares the labels of a function body up front, since
+// goto can jump forward.
+func (r *resolver) declareLabels(node antlr.Tree, fn *Scope) {
+	for _, child := range node.GetChildren() {
+		switch c := child.(type) {
+		case *FunctionLitContext:
+			continue
+		case *LabeledStmtContext:
+			if c.IDENTIFIER() == nil {
+				break
+			}
+			sym := &Symbol{Name: c.IDENTIFIER().GetText(), Kind: LabelSymbol, File: r.file, Ident: c.IDENTIFIER().GetSymbol(), Decl: c, Scope: fn}
+			r.info.define(nil, sym)
+			fn.labels[sym.Name] = sym
+		}
+		r.declareLabels(child, fn)
+	}
+}
+
+func (r *resolver) EnterFunctionDecl(ctx *FunctionDeclContext) {
+	r.enterFunction(ctx, ctx.TypeParameters(), nil, ctx.Signature(), ctx.Block())
+}
+
+func (r *resolver) ExitFunctionDecl(ctx *FunctionDeclContext) {
+	r.pop()
+}
+
+func (r *resolver) EnterMethodDecl(ctx *MethodDeclContext) {
+	r.enterFunction(ctx, nil, ctx.Receiver(), ctx.Signature(), ctx.Block())
+}
+
+func (r *resolver) ExitMethodDecl(ctx *MethodDeclContext) {
+	r.pop()
+}
+
+func (r *resolver) EnterFunctionLit(ctx *FunctionLitContext) {
+	r.enterFunction(ctx, nil, nil, ctx.Signature(), ctx.Block())
+}
+
+func (r *resolver) ExitFunctionLit(ctx *FunctionLitContext) {
+	r.pop()
+}
+
+// Blocks and statements with implicit blocks
+
+// The body of a function shares the scope of its parameters.
+func functionBody(ctx *BlockContext) bool {
+	switch ctx.GetParent().(type) {
+	case *FunctionDeclContext, *MethodDeclContext, *FunctionLitContext:
+		return true
+	}
+	return false
+}
+
+func (r *resolver) EnterBlock(ctx *BlockContext) {
+	if !functionBody(ctx) {
+		r.push(BlockScope, ctx)
+	}
+}
+
+func (r *resolver) ExitBlock(ctx *BlockContext) {
+	if !functionBody(ctx) {
+		r.pop()
+	}
+}
+
+func (r *resolver) EnterIfStmt(ctx *IfStmtContext)                 { r.push(BlockScope, ctx) }
+func (r *resolver) ExitIfStmt(ctx *IfStmtContext)                  { r.pop() }
+func (r *resolver) EnterForStmt(ctx *ForStmtContext)               { r.push(BlockScope, ctx) }
+func (r *resolver) ExitForStmt(ctx *ForStmtContext)                { r.pop() }
+func (r *resolver) EnterExprSwitchStmt(ctx *ExprSwitchStmtContext) { r.push(BlockScope, ctx) }
+func (r *resolver) ExitExprSwitchStmt(ctx *ExprSwitchStmtContext)  { r.pop() }
+func (r *resolver) EnterTypeSwitchStmt(ctx *TypeSwitchStmtContext) { r.push(BlockScope, ctx) }
+func (r *resolver) ExitTypeSwitchStmt(ctx *TypeSwitchStmtContext)  { r.pop() }
+func (r *resolver) EnterExprCaseClause(ctx *ExprCaseClauseContext) { r.push(BlockScope, ctx) }
+func (r *resolver) ExitExprCaseClause(ctx *ExprCaseClauseContext)  { r.pop() }
+func (r *resolver) EnterTypeCaseClause(ctx *TypeCaseClauseContext) { r.push(BlockScope, ctx) }
+func (r *resolver) ExitTypeCaseClause(ctx *TypeCaseClauseContext)  { r.pop() }
+func (r *resolver) EnterCommClause(ctx *CommClauseContext)         { r.push(BlockScope, ctx) }
+func (r *resolver) ExitCommClause(ctx *CommClauseContext)          { r.pop() }
+
+// Local declarations. Package level ones are declared by
+// declarePackage. The scope of a constant or variable begins after its
+// spec, the scope of a type at its name.
+
+func (r *resolver) EnterTypeSpec(ctx *TypeSpecContext) {
+	if r.local() {
+		r.info.declareType(ctx, r.scope, r.file)
+	}
+	if ctx.TypeParameters() != nil {
+		r.info.declareTypeParameters(ctx.TypeParameters(), r.push(BlockScope, ctx), r.file)
+	}
+}
+
+func (r *resolver) ExitTypeSpec(ctx *TypeSpecContext) {
+	if ctx.TypeParameters() != nil {
+		r.pop()
+	}
+}
+
+func (r *resolver) ExitConstSpec(ctx *ConstSpecContext) {
+	if r.local() {
+		r.info.declareConst(ctx, r.scope, r.file)
+	}
+}
+
+func (r *resolver) ExitVarSpec(ctx *VarSpecContext) {
+	if r.local() {
+		r.info.declareVar(ctx, r.scope, r.file)
+	}
+}
+
+// ExitShortVarDecl declares the new names on the left; names already
+// declared in the same scope are assigned to instead.
+func (r *resolver) ExitShortVarDecl(ctx *ShortVarDeclContext) {
+	r.declareShort(ctx, ctx.IdentifierList(), ctx.ExpressionList())
+}
+
+func (r *resolver) declareShort(decl antlr.ParserRuleContext, names IIdentifierListContext, values IExpressionListContext) {
+	ids := identifiers(names)
+	var exprs []IExpressionContext
+	if list, ok := values.(*ExpressionListContext); ok && len(list.AllExpression()) == len(ids) {
+		exprs = list.AllExpression()
+	}
+	for i, id := range ids {
+		if sym, ok := r.scope.Symbols[id.GetText()]; ok {
+			r.info.use(r.file, id.GetSymbol(), sym)
+			continue
+		}
+		sym := &Symbol{Name: id.GetText(), Kind: VarSymbol, File: r.file, Ident: id.GetSymbol(), Decl: decl}
+		if exprs != nil {
+			sym.value = exprs[i]
+		}
+		r.info.define(r.scope, sym)
+	}
+}
+
+func (r *resolver) ExitRangeClause(ctx *RangeClauseContext) {
+	if ctx.DECLARE_ASSIGN() != nil {
+		r.declareShort(ctx, ctx.IdentifierList(), nil)
+	}
+}
+
+func (r *resolver) ExitRecvStmt(ctx *RecvStmtContext) {
+	if ctx.DECLARE_ASSIGN() != nil {
+		r.declareShort(ctx, ctx.IdentifierList(), nil)
+	}
+}
+
+// The variable of a type switch guard is declared in the scope of the
+// switch, with no type since it has a different one in each clause.
+func (r *resolver) ExitTypeSwitchGuard(ctx *TypeSwitchGuardContext) {
+	if id := ctx.IDENTIFIER(); id != nil {
+		r.info.define(r.scope, &Symbol{Name: id.GetText(), Kind: VarSymbol, File: r.file, Ident: id.GetSymbol(), Decl: ctx})
+	}
+}
+
+// Uses
+
+func (r *resolver) VisitTerminal(node antlr.TerminalNode) {
+	t := node.GetSymbol()
+	if t.GetTokenType() != GoParserIDENTIFIER {
+		return
+	}
+	if sym, ok := r.info.Defs[t]; ok && !(sym.Kind == FieldSymbol && embeddedFieldName(r.rule)) {
+		return
+	}
+	name := t.GetText()
+	switch parent := r.rule.(type) {
+	case *IdentifierListContext, *TypeSpecContext, *FunctionDeclContext, *MethodDeclContext,
+		*PackageClauseContext, *ImportSpecContext, *LabeledStmtContext, *MethodSpecContext,
+		*TypeSwitchGuardContext:
+		// Declarations
+	case *BreakStmtContext, *ContinueStmtContext, *GotoStmtContext:
+		if fn := r.scope.function(); fn != nil {
+			r.info.use(r.file, t, fn.labels[name])
+		}
+	case *QualifiedIdentContext:
+		if parent.IDENTIFIER(0).GetSymbol() == t {
+			r.info.use(r.file, t, r.scope.Lookup(name))
+		}
+	case *OperandNameContext:
+		if first := parent.IDENTIFIER(0).GetSymbol(); first != t {
+			r.info.use(r.file, t, r.selection(r.info.Uses[first], name))
+		} else if key := compositeKey(parent); key != nil {
+			r.info.use(r.file, t, r.fieldKey(key, name))
+		} else {
+			r.info.use(r.file, t, r.scope.Lookup(name))
+		}
+	case *PrimaryExprContext:
+		if t := r.info.primaryType(parent.PrimaryExpr()); t != nil {
+			r.info.use(r.file, node.GetSymbol(), r.info.lookupMember(t, name))
+		}
+	case *KeyContext:
+		r.info.use(r.file, t, r.fieldKey(parent, name))
+	case *MethodExprContext:
+		if recv, ok := parent.ReceiverType().(*ReceiverTypeContext); ok {
+			if t := r.info.namedType(recv.Type_(), r.scope); t != nil {
+				r.info.use(r.file, node.GetSymbol(), r.info.lookupMember(t, name))
+			}
+		}
+	default:
+		r.info.use(r.file, t, r.scope.Lookup(name))
+	}
+}
+
+// selection returns the member name of the type of sym.
+func (r *resolver) selection(sym *Symbol, name string) *Symbol {
+	if sym == nil || sym.Kind == PackageSymbol {
+		return nil // declared in another package
+	}
+	if t := r.info.typeOf(sym); t != nil {
+		return r.info.lookupMember(t, name)
+	}
+	return nil
+}
+
+// fieldKey resolves the key of an element of a composite literal: a
+// field name for a struct type, otherwise an ordinary expression.
+func (r *resolver) fieldKey(key *KeyContext, name string) *Symbol {
+	if lit, ok := key.GetParent().GetParent().GetParent().GetParent().(*CompositeLitContext); ok {
+		if lt, ok := lit.LiteralType().(*LiteralTypeContext); ok && lt.TypeName() != nil {
+			if t := r.info.namedType(lt.TypeName(), r.scope); t != nil {
+				if field := r.info.lookupMember(t, name); field != nil && field.Kind == FieldSymbol {
+					return field
+				}
+			}
+		}
+	}
+	return r.scope.Lookup(name)
+}
+
+// compositeKey returns the key of a composite literal element if the
+// operand name is that whole key.
+func compositeKey(ctx *OperandNameContext) *KeyContext {
+	if len(ctx.AllIDENTIFIER()) != 1 {
+		return nil
+	}
+	operand, ok := ctx.GetParent().(*OperandContext)
+	if !ok || operand.TypeArgs() != nil {
+		return nil
+	}
+	primary, ok := operand.GetParent().(*PrimaryExprContext)
+	if !ok {
+		return nil
+	}
+	expr, ok := primary.GetParent().(*ExpressionContext)
+	if !ok {
+		return nil
+	}
+	key, _ := expr.GetParent().(*KeyContext)
+	return key
+}
+
+// embeddedFieldName returns true for the type name of an embedded
+// field, which both declares the field and uses the type. rule is the
+// rule of the name.
+func embeddedFieldName(rule antlr.Tree) bool {
+	for p := rule; p != nil; p = p.GetParent() {
+		switch p.(type) {
+		case *TypeNameContext, *QualifiedIdentContext:
+			continue
+		case *EmbeddedFieldContext:
+			return true
+		}
+		return false
+	}
+	return false
+}
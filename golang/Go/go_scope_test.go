@@ -0,0 +1,120 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const resolverSrc = `package p
+
+type Base struct {
+	ID int
+}
+
+func (b *Base) Name() string { return "" }
+
+type User struct {
+	Base
+	Email string
+}
+
+func (u *User) Describe() string {
+	return u.Email + u.Name()
+}
+
+func f(u User) int {
+	x := 1
+	if x := 2; x > 1 {
+		_ = x
+	}
+outer:
+	for i := 0; i < x; i++ {
+		for {
+			continue outer
+		}
+		break outer
+	}
+	return u.ID + x
+}
+`
+
+// identAt returns the position of the n-th occurrence, from 1, of the
+// identifier name in src.
+func identAt(src, name string, n int) Position {
+	word := regexp.MustCompile(`\b` + name + `\b`)
+	for i, line := range strings.Split(src, "\n") {
+		for _, m := range word.FindAllStringIndex(line, -1) {
+			if n--; n == 0 {
+				return Position{i + 1, m[0]}
+			}
+		}
+	}
+	panic(fmt.Sprintf("no occurrence of %s", name))
+}
+
+func TestDefinition(t *testing.T) {
+	info := ResolvePackage(ParseGoString("p.go", resolverSrc))
+	tests := []struct {
+		name     string
+		use, def int // occurrences of name
+	}{
+		{"x", 3, 2}, // shadowed in the if statement
+		{"x", 4, 2},
+		{"x", 5, 1},
+		{"x", 6, 1},
+		{"outer", 2, 1}, // labels
+		{"outer", 3, 1},
+		{"u", 2, 1}, // receivers and parameters
+		{"u", 3, 1},
+		{"u", 5, 4},
+		{"Email", 2, 1},
+		{"ID", 2, 1},   // promoted field
+		{"Name", 2, 1}, // promoted method
+		{"Base", 2, 1}, // receiver type
+	}
+	for _, test := range tests {
+		use := identAt(resolverSrc, test.name, test.use)
+		ref, ok := info.Definition("p.go", use)
+		if !ok {
+			t.Errorf("%s#%d: no definition", test.name, test.use)
+			continue
+		}
+		if want := identAt(resolverSrc, test.name, test.def); ref.Pos() != want {
+			t.Errorf("%s#%d: defined at %v, want %v", test.name, test.use, ref.Pos(), want)
+		}
+	}
+	if sym := info.SymbolAt("p.go", identAt(resolverSrc, "outer", 1)); sym == nil || sym.Kind != LabelSymbol {
+		t.Errorf("outer is %v, want a label", sym)
+	}
+}
+
+func TestReferences(t *testing.T) {
+	info := ResolvePackage(ParseGoString("p.go", resolverSrc))
+	tests := []struct {
+		name string
+		decl int
+		refs []int
+	}{
+		{"x", 1, []int{5, 6}},
+		{"x", 2, []int{3, 4}},
+		{"outer", 1, []int{2, 3}},
+		{"u", 1, []int{2, 3}},
+		{"ID", 1, []int{2}},
+		{"Name", 1, []int{2}},
+		{"Base", 1, []int{2, 3}}, // the embedded field uses the type
+	}
+	for _, test := range tests {
+		var got, want []Position
+		for _, ref := range info.References("p.go", identAt(resolverSrc, test.name, test.decl)) {
+			got = append(got, ref.Pos())
+		}
+		for _, n := range test.refs {
+			want = append(want, identAt(resolverSrc, test.name, n))
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%s#%d: references %v, want %v", test.name, test.decl, got, want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// SyntaxError is a lexer or parser error reported while parsing
+// a Go source file.
+type SyntaxError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e SyntaxError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Msg)
+}
+
+// syntaxErrorCollector records syntax errors instead of printing
+// them to the console.
+type syntaxErrorCollector struct {
+	*antlr.DefaultErrorListener
+
+	errors []SyntaxError
+}
+
+func (c *syntaxErrorCollector) SyntaxError(recognizer antlr.Recognizer, offendingSymbol interface{}, line, column int, msg string, e antlr.RecognitionException) {
+	c.errors = append(c.errors, SyntaxError{line, column, msg})
+}
+
+// GoSource is a Go source file parsed with GoParser. The token
+// stream is kept so that hidden channel tokens (comments, white
+// space) can be queried alongside the tree.
+type GoSource struct {
+	Name   string
+	Tokens *antlr.CommonTokenStream
+	Tree   *SourceFileContext
+	Errors []SyntaxError
+}
+
+// ParseGoSource parses input as a Go source file. Syntax errors do
+// not stop the parse, they are collected in Errors and the tree
+// holds whatever the parser recovered.
+func ParseGoSource(name string, input antlr.CharStream) *GoSource {
+	errors := &syntaxErrorCollector{DefaultErrorListener: antlr.NewDefaultErrorListener()}
+
+	lexer := NewGoLexer(input)
+	lexer.RemoveErrorListeners()
+	lexer.AddErrorListener(errors)
+
+	tokens := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
+	p := NewGoParser(tokens)
+	p.RemoveErrorListeners()
+	p.AddErrorListener(errors)
+
+	tree := p.SourceFile().(*SourceFileContext)
+	return &GoSource{name, tokens, tree, errors.errors}
+}
+
+// ParseGoFile reads and parses the Go source file at path.
+func ParseGoFile(path string) (*GoSource, error) {
+	input, err := antlr.NewFileStream(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGoSource(path, input), nil
+}
+
+// ParseGoString parses src as a Go source file called name.
+func ParseGoString(name, src string) *GoSource {
+	return ParseGoSource(name, antlr.NewInputStream(src))
+}
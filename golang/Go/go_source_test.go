@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// typeArgsCounter counts the operands with type arguments and the
+// index expressions of a tree.
+type typeArgsCounter struct {
+	BaseGoParserListener
+
+	typeArgs, indexes int
+}
+
+func (c *typeArgsCounter) EnterOperand(ctx *OperandContext) {
+	if ctx.TypeArgs() != nil {
+		c.typeArgs++
+	}
+}
+
+func (c *typeArgsCounter) EnterIndex(ctx *IndexContext) {
+	c.indexes++
+}
+
+func TestParseIndexOrTypeArgs(t *testing.T) {
+	tests := []struct {
+		expr              string
+		typeArgs, indexes int
+	}{
+		{"a[i]", 0, 1},
+		{"m[k]", 0, 1},
+		{"m[pkg.K]", 0, 1},
+		{"a[i][j]", 0, 2},
+		{"a[i+1]", 0, 1},
+		{"f[int](x)", 0, 1},
+		{"f[int, string](x)", 1, 0},
+		{"f[[]int](x)", 1, 0},
+		{"f[map[string]int]", 1, 0},
+		{"m[[2]int{1, 2}]", 0, 1},
+		{"Pair[string, T]{}", 0, 0},
+	}
+	for _, test := range tests {
+		source := ParseGoString("p.go", "package p\n\nvar _ = "+test.expr+"\n")
+		if len(source.Errors) > 0 {
+			t.Errorf("%s: %v", test.expr, source.Errors)
+			continue
+		}
+		c := &typeArgsCounter{}
+		antlr.ParseTreeWalkerDefault.Walk(c, source.Tree)
+		if c.typeArgs != test.typeArgs || c.indexes != test.indexes {
+			t.Errorf("%s: %d operands with type arguments and %d indexes, want %d and %d",
+				test.expr, c.typeArgs, c.indexes, test.typeArgs, test.indexes)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package parser
+
+import (
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// Position is a 1-based line and a 0-based column, as reported by
+// ANTLR tokens.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// StartOf returns the position of the first character of ctx.
+func StartOf(ctx antlr.ParserRuleContext) Position {
+	t := ctx.GetStart()
+	return Position{t.GetLine(), t.GetColumn()}
+}
+
+// EndOf returns the position just past the last character of ctx.
+func EndOf(ctx antlr.ParserRuleContext) Position {
+	t := ctx.GetStop()
+	if t == nil || t.GetTokenIndex() < ctx.GetStart().GetTokenIndex() {
+		return StartOf(ctx)
+	}
+	return tokenEnd(t)
+}
+
+func tokenEnd(t antlr.Token) Position {
+	text := t.GetText()
+	if i := strings.LastIndexAny(text, "\r\n"); i >= 0 {
+		return Position{t.GetLine() + strings.Count(text, "\n"), len([]rune(text[i+1:]))}
+	}
+	return Position{t.GetLine(), t.GetColumn() + len([]rune(text))}
+}
+
+// StringValue returns the value of a raw or interpreted string literal.
+func StringValue(ctx IString_Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	s, err := strconv.Unquote(ctx.GetText())
+	return s, err == nil
+}
+
+// ImportPath returns the unquoted path of an import spec.
+func ImportPath(spec *ImportSpecContext) string {
+	if p, ok := spec.ImportPath().(*ImportPathContext); ok {
+		if s, ok := StringValue(p.String_()); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// ImportName returns the name an import spec binds in the file: the
+// alias if there is one ("." and "_" included), otherwise the last
+// element of the import path.
+func ImportName(spec *ImportSpecContext) string {
+	if alias := spec.GetAlias(); alias != nil {
+		return alias.GetText()
+	}
+	name := path.Base(ImportPath(spec))
+	if strings.HasPrefix(name, "v") {
+		// Major version suffix, as in math/rand/v2.
+		if _, err := strconv.Atoi(name[1:]); err == nil {
+			name = path.Base(path.Dir(ImportPath(spec)))
+		}
+	}
+	return name
+}
+
+// ImportSpecs returns every import spec of a source file in order.
+func ImportSpecs(tree *SourceFileContext) []*ImportSpecContext {
+	var specs []*ImportSpecContext
+	for _, decl := range tree.AllImportDecl() {
+		for _, spec := range decl.(*ImportDeclContext).AllImportSpec() {
+			specs = append(specs, spec.(*ImportSpecContext))
+		}
+	}
+	return specs
+}
+
+// StringLiteral returns the value of expr if it is a string literal.
+func StringLiteral(expr IExpressionContext) (string, bool) {
+	lit := basicLitOf(expr)
+	if lit == nil {
+		return "", false
+	}
+	return StringValue(lit.String_())
+}
+
+func basicLitOf(expr IExpressionContext) *BasicLitContext {
+	e, ok := expr.(*ExpressionContext)
+	if !ok {
+		return nil
+	}
+	primary, ok := e.PrimaryExpr().(*PrimaryExprContext)
+	if !ok {
+		return nil
+	}
+	operand, ok := primary.Operand().(*OperandContext)
+	if !ok {
+		return nil
+	}
+	if operand.Expression() != nil {
+		return basicLitOf(operand.Expression())
+	}
+	literal, ok := operand.Literal().(*LiteralContext)
+	if !ok {
+		return nil
+	}
+	lit, _ := literal.BasicLit().(*BasicLitContext)
+	return lit
+}
+
+// CallOf returns the callee and arguments of expr if it is a call.
+func CallOf(expr IExpressionContext) (*PrimaryExprContext, *ArgumentsContext) {
+	e, ok := expr.(*ExpressionContext)
+	if !ok {
+		return nil, nil
+	}
+	primary, ok := e.PrimaryExpr().(*PrimaryExprContext)
+	if !ok {
+		return nil, nil
+	}
+	args, ok := primary.Arguments().(*ArgumentsContext)
+	if !ok {
+		return nil, nil
+	}
+	callee, _ := primary.PrimaryExpr().(*PrimaryExprContext)
+	return callee, args
+}
+
+// QualifiedName splits a callee such as md5.New into its package
+// (or receiver) name and selector. Unqualified names return an empty
+// qualifier.
+func QualifiedName(callee *PrimaryExprContext) (string, string) {
+	if callee == nil {
+		return "", ""
+	}
+	text := callee.GetText()
+	if i := strings.LastIndex(text, "."); i >= 0 {
+		return text[:i], text[i+1:]
+	}
+	return "", text
+}
+
+// EnclosingFunction returns the name of the function or method
+// declaration containing ctx, or "" at package level.
+func EnclosingFunction(ctx antlr.Tree) string {
+	for ; ctx != nil; ctx = ctx.GetParent() {
+		var name antlr.TerminalNode
+		switch f := ctx.(type) {
+		case *FunctionDeclContext:
+			name = f.IDENTIFIER()
+		case *MethodDeclContext:
+			name = f.IDENTIFIER()
+		default:
+			continue
+		}
+		if name == nil {
+			return ""
+		}
+		return name.GetText()
+	}
+	return ""
+}
@@ -0,0 +1,312 @@
+package parser
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GoImport is an import spec of a file. Kind is "default", "alias",
+// "dot" or "blank".
+type GoImport struct {
+	Path  string `json:"path"`
+	Name  string `json:"name"`
+	Kind  string `json:"kind"`
+	File  string `json:"file"`
+	Line  int    `json:"line"`
+	Alias string `json:"alias,omitempty"`
+}
+
+// GoPackage is the set of files of a directory with the same package
+// clause. External test packages get the import path of their
+// directory followed by _test, like go list shows them.
+type GoPackage struct {
+	ImportPath string     `json:"importPath"`
+	Name       string     `json:"name"`
+	Dir        string     `json:"dir"`
+	Files      []string   `json:"files"`
+	Imports    []GoImport `json:"imports"`
+	Incomplete bool       `json:"incomplete,omitempty"` // some files have syntax errors
+}
+
+// PackageConflict is a directory whose files declare different
+// packages, such as a package main generator next to package foo,
+// which the go tool refuses to build. The package with the most files
+// gets the import path.
+type PackageConflict struct {
+	ImportPath string   `json:"importPath"`
+	Dir        string   `json:"dir"`
+	Names      []string `json:"names"`
+}
+
+// ImportGraph is the import graph of the packages below Root. Module
+// is the module path from Root/go.mod, if there is one.
+type ImportGraph struct {
+	Root      string             `json:"root"`
+	Module    string             `json:"module,omitempty"`
+	Packages  []*GoPackage       `json:"packages"`
+	Conflicts []*PackageConflict `json:"conflicts,omitempty"`
+
+	byDir  map[packageKey]*GoPackage
+	byPath map[string]*GoPackage
+}
+
+// packageKey identifies a package by its directory and package clause.
+type packageKey struct {
+	dir, name string
+}
+
+// LoadImportGraph parses every .go file below root with GoParser, so
+// files the go tool rejects still contribute their imports. Like the
+// go tool it skips testdata and vendor directories and directories
+// whose name starts with . or _.
+func LoadImportGraph(root string) (*ImportGraph, error) {
+	g := &ImportGraph{Root: root, Module: modulePath(filepath.Join(root, "go.mod")), byDir: map[packageKey]*GoPackage{}}
+	err := filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name := info.Name()
+		if info.IsDir() {
+			if file != root && (name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(name) != ".go" {
+			return nil
+		}
+		source, err := ParseGoFile(file)
+		if err != nil {
+			return err
+		}
+		g.addFile(file, source)
+		return nil
+	})
+	g.indexPaths()
+	return g, err
+}
+
+func (g *ImportGraph) addFile(file string, source *GoSource) {
+	clause, ok := source.Tree.PackageClause().(*PackageClauseContext)
+	if !ok || clause.GetPackageName() == nil {
+		return
+	}
+	name := clause.GetPackageName().GetText()
+	dir, _ := filepath.Rel(g.Root, filepath.Dir(file))
+	importPath := g.importPath(filepath.ToSlash(dir))
+	if strings.HasSuffix(name, "_test") {
+		importPath += "_test"
+	}
+	key := packageKey{filepath.Dir(file), name}
+	pkg := g.byDir[key]
+	if pkg == nil {
+		pkg = &GoPackage{ImportPath: importPath, Name: name, Dir: key.dir}
+		g.byDir[key] = pkg
+		g.Packages = append(g.Packages, pkg)
+	}
+	pkg.Files = append(pkg.Files, filepath.Base(file))
+	pkg.Incomplete = pkg.Incomplete || len(source.Errors) > 0
+	for _, spec := range ImportSpecs(source.Tree) {
+		imp := GoImport{Path: ImportPath(spec), Name: ImportName(spec), Kind: "default", File: file, Line: spec.GetStart().GetLine()}
+		if alias := spec.GetAlias(); alias != nil {
+			imp.Alias = alias.GetText()
+			switch imp.Alias {
+			case ".":
+				imp.Kind = "dot"
+			case "_":
+				imp.Kind = "blank"
+			default:
+				imp.Kind = "alias"
+			}
+		}
+		pkg.Imports = append(pkg.Imports, imp)
+	}
+}
+
+// indexPaths maps the import paths to the packages and reports the
+// directories with more than one package.
+func (g *ImportGraph) indexPaths() {
+	g.byPath = map[string]*GoPackage{}
+	conflicts := map[string]*PackageConflict{}
+	for _, pkg := range g.Packages {
+		current := g.byPath[pkg.ImportPath]
+		if current == nil {
+			g.byPath[pkg.ImportPath] = pkg
+			continue
+		}
+		c := conflicts[pkg.ImportPath]
+		if c == nil {
+			c = &PackageConflict{ImportPath: pkg.ImportPath, Dir: pkg.Dir, Names: []string{current.Name}}
+			conflicts[pkg.ImportPath] = c
+			g.Conflicts = append(g.Conflicts, c)
+		}
+		c.Names = append(c.Names, pkg.Name)
+		if len(pkg.Files) > len(current.Files) {
+			g.byPath[pkg.ImportPath] = pkg
+		}
+	}
+}
+
+func (g *ImportGraph) importPath(dir string) string {
+	switch {
+	case g.Module == "":
+		return dir
+	case dir == ".":
+		return g.Module
+	default:
+		return path.Join(g.Module, dir)
+	}
+}
+
+func modulePath(gomod string) string {
+	f, err := os.Open(gomod)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) == 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
+
+// Package returns the package with the given import path, or nil if it
+// is not below Root. See PackageConflict for a directory with more than
+// one package.
+func (g *ImportGraph) Package(importPath string) *GoPackage {
+	return g.byPath[importPath]
+}
+
+// Dependencies returns the distinct import paths of pkg in order.
+func (pkg *GoPackage) Dependencies() []string {
+	seen := map[string]bool{}
+	var deps []string
+	for _, imp := range pkg.Imports {
+		if !seen[imp.Path] {
+			seen[imp.Path] = true
+			deps = append(deps, imp.Path)
+		}
+	}
+	return deps
+}
+
+// Cycles returns the import cycles between packages of the graph, as
+// the strongly connected components with more than one package or a
+// package importing itself.
+func (g *ImportGraph) Cycles() [][]string {
+	// Tarjan's algorithm.
+	index := map[string]int{}
+	low := map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+	var cycles [][]string
+	var visit func(p string)
+	visit = func(p string) {
+		index[p] = len(index)
+		low[p] = index[p]
+		stack = append(stack, p)
+		onStack[p] = true
+		self := false
+		for _, dep := range g.byPath[p].Dependencies() {
+			if g.byPath[dep] == nil {
+				continue
+			}
+			self = self || dep == p
+			if _, seen := index[dep]; !seen {
+				visit(dep)
+				if low[dep] < low[p] {
+					low[p] = low[dep]
+				}
+			} else if onStack[dep] && index[dep] < low[p] {
+				low[p] = index[dep]
+			}
+		}
+		if low[p] != index[p] {
+			return
+		}
+		var component []string
+		for {
+			q := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[q] = false
+			component = append(component, q)
+			if q == p {
+				break
+			}
+		}
+		if len(component) > 1 || self {
+			sort.Strings(component)
+			cycles = append(cycles, component)
+		}
+	}
+	for _, pkg := range g.Packages {
+		if _, seen := index[pkg.ImportPath]; !seen {
+			visit(pkg.ImportPath)
+		}
+	}
+	return cycles
+}
+
+// WriteJSON writes the packages of the graph with their imports, the
+// package conflicts and the import cycles.
+func (g *ImportGraph) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		*ImportGraph
+		Cycles [][]string `json:"cycles"`
+	}{g, g.Cycles()})
+}
+
+// WriteDOT writes the graph in Graphviz format. Packages outside the
+// graph are drawn dashed and edges on import cycles red.
+func (g *ImportGraph) WriteDOT(w io.Writer) error {
+	cycleOf := map[string]int{}
+	for i, cycle := range g.Cycles() {
+		for _, p := range cycle {
+			cycleOf[p] = i + 1
+		}
+	}
+	b := &strings.Builder{}
+	fmt.Fprintln(b, "digraph imports {")
+	fmt.Fprintln(b, "\tnode [shape=box];")
+	external := map[string]bool{}
+	for _, pkg := range g.Packages {
+		fmt.Fprintf(b, "\t%q;\n", pkg.ImportPath)
+		for _, dep := range pkg.Dependencies() {
+			if g.byPath[dep] == nil {
+				external[dep] = true
+			}
+		}
+	}
+	var externals []string
+	for dep := range external {
+		externals = append(externals, dep)
+	}
+	sort.Strings(externals)
+	for _, dep := range externals {
+		fmt.Fprintf(b, "\t%q [style=dashed];\n", dep)
+	}
+	for _, pkg := range g.Packages {
+		for _, dep := range pkg.Dependencies() {
+			if c := cycleOf[dep]; c != 0 && c == cycleOf[pkg.ImportPath] {
+				fmt.Fprintf(b, "\t%q -> %q [color=red];\n", pkg.ImportPath, dep)
+			} else {
+				fmt.Fprintf(b, "\t%q -> %q;\n", pkg.ImportPath, dep)
+			}
+		}
+	}
+	fmt.Fprintln(b, "}")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadImportGraph(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":          "module example.com/m\n",
+		"foo.go":          "package foo\n\nimport \"example.com/m/bar\"\n",
+		"foo2.go":         "package foo\n\nimport \"fmt\"\n",
+		"gen.go":          "//go:build ignore\n\npackage main\n\nimport \"os\"\n",
+		"foo_test.go":     "package foo_test\n\nimport \"example.com/m\"\n",
+		"bar/bar.go":      "package bar\n\nimport \"example.com/m\"\n",
+		"testdata/bad.go": "package bad\n",
+	}
+	for name, src := range files {
+		file := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g, err := LoadImportGraph(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packages := map[string][]string{}
+	for _, pkg := range g.Packages {
+		packages[pkg.ImportPath+" "+pkg.Name] = pkg.Files
+	}
+	want := map[string][]string{
+		"example.com/m foo":           {"foo.go", "foo2.go"},
+		"example.com/m main":          {"gen.go"},
+		"example.com/m_test foo_test": {"foo_test.go"},
+		"example.com/m/bar bar":       {"bar.go"},
+	}
+	if !reflect.DeepEqual(packages, want) {
+		t.Errorf("packages %v, want %v", packages, want)
+	}
+	if pkg := g.Package("example.com/m"); pkg == nil || pkg.Name != "foo" {
+		t.Errorf("example.com/m is %+v, want package foo", pkg)
+	}
+	if len(g.Conflicts) != 1 || !reflect.DeepEqual(g.Conflicts[0].Names, []string{"foo", "main"}) {
+		t.Errorf("conflicts %+v, want foo and main in example.com/m", g.Conflicts)
+	}
+	if cycles := g.Cycles(); !reflect.DeepEqual(cycles, [][]string{{"example.com/m", "example.com/m/bar"}}) {
+		t.Errorf("cycles %v", cycles)
+	}
+}
@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+)
+
+// SARIF 2.1.0, only the parts needed to report findings.
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string       `json:"id"`
+	Name                 string       `json:"name"`
+	ShortDescription     sarifMessage `json:"shortDescription"`
+	DefaultConfiguration struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region sarifRegion `json:"region"`
+	} `json:"physicalLocation"`
+}
+
+// sarifRegion columns are 1-based, unlike Position.
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+// WriteSARIF writes findings of the scanner's rules to w as a SARIF
+// 2.1.0 log with a single run.
+func (s *SecurityScanner) WriteSARIF(w io.Writer, findings []Finding) error {
+	levels := map[string]string{}
+	run := sarifRun{
+		Tool:    sarifTool{sarifDriver{Name: "antlr-go-sast", Rules: []sarifRule{}}},
+		Results: []sarifResult{},
+	}
+	for _, rule := range s.Rules {
+		info := rule.Info()
+		r := sarifRule{ID: info.ID, Name: info.Name, ShortDescription: sarifMessage{info.Description}}
+		r.DefaultConfiguration.Level = info.Level
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, r)
+		levels[info.ID] = info.Level
+	}
+	for _, f := range findings {
+		var loc sarifLocation
+		loc.PhysicalLocation.ArtifactLocation.URI = filepath.ToSlash(f.File)
+		loc.PhysicalLocation.Region = sarifRegion{f.Start.Line, f.Start.Column + 1, f.End.Line, f.End.Column + 1}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    f.RuleID,
+			Level:     levels[f.RuleID],
+			Message:   sarifMessage{f.Message},
+			Locations: []sarifLocation{loc},
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Schema: sarifSchema, Version: "2.1.0", Runs: []sarifRun{run}})
+}
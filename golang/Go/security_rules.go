@@ -0,0 +1,481 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// Finding is a security issue reported by a SecurityRule.
+type Finding struct {
+	RuleID  string
+	Message string
+	File    string
+	Start   Position
+	End     Position
+}
+
+// RuleInfo describes a SecurityRule. Level is a SARIF level: "error",
+// "warning" or "note".
+type RuleInfo struct {
+	ID          string
+	Name        string
+	Description string
+	Level       string
+}
+
+// SecurityRule is a GoParserListener that looks for one kind of
+// security issue while the tree of a file is walked.
+type SecurityRule interface {
+	GoParserListener
+	Info() RuleInfo
+	// Begin is called before the tree of each file is walked; the rule
+	// passes its findings for that file to report.
+	Begin(source *GoSource, report func(Finding))
+}
+
+// DefaultSecurityRules returns a new instance of every built-in rule.
+func DefaultSecurityRules() []SecurityRule {
+	return []SecurityRule{
+		newHardcodedCredentialRule(),
+		newWeakHashRule(),
+		newInsecureRandomRule(),
+		newCommandInjectionRule(),
+	}
+}
+
+// SecurityScanner runs a set of rules over Go source files.
+type SecurityScanner struct {
+	Rules []SecurityRule
+}
+
+// NewSecurityScanner returns a scanner running DefaultSecurityRules.
+func NewSecurityScanner() *SecurityScanner {
+	return &SecurityScanner{DefaultSecurityRules()}
+}
+
+// Scan walks the tree of source once per rule and returns the findings
+// in rule order.
+func (s *SecurityScanner) Scan(source *GoSource) []Finding {
+	var findings []Finding
+	report := func(f Finding) {
+		findings = append(findings, f)
+	}
+	for _, rule := range s.Rules {
+		rule.Begin(source, report)
+		antlr.ParseTreeWalkerDefault.Walk(rule, source.Tree)
+	}
+	return findings
+}
+
+// ScanFile parses and scans the Go source file at path.
+func (s *SecurityScanner) ScanFile(path string) ([]Finding, error) {
+	source, err := ParseGoFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return s.Scan(source), nil
+}
+
+// ScanDir scans every .go file below root.
+func (s *SecurityScanner) ScanDir(root string) ([]Finding, error) {
+	var findings []Finding
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(path) != ".go" {
+			return err
+		}
+		f, err := s.ScanFile(path)
+		findings = append(findings, f...)
+		return err
+	})
+	return findings, err
+}
+
+// securityRule holds what every rule needs: its description, the
+// file being walked with its import names, and the report callback.
+type securityRule struct {
+	BaseGoParserListener
+
+	info    RuleInfo
+	source  *GoSource
+	imports map[string]string // name bound in the file -> import path
+	report  func(Finding)
+}
+
+func (r *securityRule) Info() RuleInfo {
+	return r.info
+}
+
+func (r *securityRule) Begin(source *GoSource, report func(Finding)) {
+	r.source = source
+	r.report = report
+	r.imports = map[string]string{}
+	for _, spec := range ImportSpecs(source.Tree) {
+		r.imports[ImportName(spec)] = ImportPath(spec)
+	}
+}
+
+func (r *securityRule) reportAt(ctx antlr.ParserRuleContext, format string, args ...interface{}) {
+	r.report(Finding{
+		RuleID:  r.info.ID,
+		Message: fmt.Sprintf(format, args...),
+		File:    r.source.Name,
+		Start:   StartOf(ctx),
+		End:     EndOf(ctx),
+	})
+}
+
+// calls returns true if callee is a function of one of the packages
+// at paths, and the selected name of the call.
+func (r *securityRule) calls(callee *PrimaryExprContext, paths ...string) (string, bool) {
+	pkg, name := QualifiedName(callee)
+	if pkg == "" {
+		return "", false
+	}
+	for _, p := range paths {
+		if r.imports[pkg] == p {
+			return pkg + "." + name, true
+		}
+	}
+	return "", false
+}
+
+// enterCall is shared by the rules that look at calls: it returns the
+// callee and arguments if ctx is a call expression.
+func enterCall(ctx *PrimaryExprContext) (*PrimaryExprContext, *ArgumentsContext) {
+	args, ok := ctx.Arguments().(*ArgumentsContext)
+	if !ok {
+		return nil, nil
+	}
+	callee, _ := ctx.PrimaryExpr().(*PrimaryExprContext)
+	return callee, args
+}
+
+func argumentList(args *ArgumentsContext) []IExpressionContext {
+	if list, ok := args.ExpressionList().(*ExpressionListContext); ok {
+		return list.AllExpression()
+	}
+	return nil
+}
+
+// Hardcoded credentials
+
+// credentialWords are the words that end the name of a credential,
+// and keyWords the words that make one of a key before it: authToken
+// and api_key are credentials, tokenType and keyCount are not.
+var (
+	credentialWords = map[string]bool{
+		"pass": true, "passwd": true, "password": true, "pwd": true,
+		"secret": true, "token": true, "credential": true,
+		"apikey": true, "accesskey": true, "privatekey": true,
+	}
+	keyWords = map[string]bool{"api": true, "access": true, "private": true, "secret": true}
+)
+
+func credentialName(name string) bool {
+	words := nameWords(name)
+	n := len(words)
+	if n == 0 {
+		return false
+	}
+	last := words[n-1]
+	if credentialWords[last] || credentialWords[strings.TrimSuffix(last, "s")] {
+		return true
+	}
+	return (last == "key" || last == "keys") && n > 1 && keyWords[words[n-2]]
+}
+
+// nameWords splits a name into lower case words at underscores and
+// case changes: apiKeyID is api, key, id and HTTPToken http, token.
+func nameWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 0; i <= len(runes); i++ {
+		boundary := i == len(runes) || runes[i] == '_'
+		if !boundary && i > start && unicode.IsUpper(runes[i]) {
+			// the B of aB, or of ABc
+			boundary = !unicode.IsUpper(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		}
+		if !boundary {
+			continue
+		}
+		if i > start {
+			words = append(words, strings.ToLower(string(runes[start:i])))
+		}
+		start = i
+		if i < len(runes) && runes[i] == '_' {
+			start++
+		}
+	}
+	return words
+}
+
+type hardcodedCredentialRule struct {
+	securityRule
+}
+
+func newHardcodedCredentialRule() *hardcodedCredentialRule {
+	return &hardcodedCredentialRule{securityRule{info: RuleInfo{
+		ID:          "G101",
+		Name:        "HardcodedCredential",
+		Description: "A string literal is assigned to a password, secret, token or key.",
+		Level:       "error",
+	}}}
+}
+
+func (r *hardcodedCredentialRule) check(name string, value IExpressionContext) {
+	if !credentialName(name) || value == nil {
+		return
+	}
+	if s, ok := StringLiteral(value); ok && s != "" {
+		r.reportAt(value.(antlr.ParserRuleContext), "hardcoded credential assigned to %s", name)
+	}
+}
+
+func (r *hardcodedCredentialRule) checkLists(names IIdentifierListContext, values IExpressionListContext) {
+	if names == nil || values == nil {
+		return
+	}
+	ids := names.(*IdentifierListContext).AllIDENTIFIER()
+	exprs := values.(*ExpressionListContext).AllExpression()
+	for i := 0; i < len(ids) && i < len(exprs); i++ {
+		r.check(ids[i].GetText(), exprs[i])
+	}
+}
+
+func (r *hardcodedCredentialRule) EnterShortVarDecl(ctx *ShortVarDeclContext) {
+	r.checkLists(ctx.IdentifierList(), ctx.ExpressionList())
+}
+
+func (r *hardcodedCredentialRule) EnterVarSpec(ctx *VarSpecContext) {
+	r.checkLists(ctx.IdentifierList(), ctx.ExpressionList())
+}
+
+func (r *hardcodedCredentialRule) EnterConstSpec(ctx *ConstSpecContext) {
+	r.checkLists(ctx.IdentifierList(), ctx.ExpressionList())
+}
+
+func (r *hardcodedCredentialRule) EnterAssignment(ctx *AssignmentContext) {
+	lhs, ok := ctx.ExpressionList(0).(*ExpressionListContext)
+	if !ok || ctx.ExpressionList(1) == nil {
+		return
+	}
+	rhs := ctx.ExpressionList(1).(*ExpressionListContext).AllExpression()
+	for i, target := range lhs.AllExpression() {
+		if i < len(rhs) {
+			// Only the selected name matters for c.password = "..."
+			_, name := splitSelector(target.GetText())
+			r.check(name, rhs[i])
+		}
+	}
+}
+
+func (r *hardcodedCredentialRule) EnterKeyedElement(ctx *KeyedElementContext) {
+	key, ok := ctx.Key().(*KeyContext)
+	if !ok {
+		return
+	}
+	if e, ok := ctx.Element().(*ElementContext); ok && e.Expression() != nil {
+		r.check(key.GetText(), e.Expression())
+	}
+}
+
+func splitSelector(text string) (string, string) {
+	for i := len(text) - 1; i >= 0; i-- {
+		if text[i] == '.' {
+			return text[:i], text[i+1:]
+		}
+	}
+	return "", text
+}
+
+// Weak hashes
+
+var weakHashPackages = []string{"crypto/md5", "crypto/sha1"}
+
+type weakHashRule struct {
+	securityRule
+}
+
+func newWeakHashRule() *weakHashRule {
+	return &weakHashRule{securityRule{info: RuleInfo{
+		ID:          "G401",
+		Name:        "WeakHash",
+		Description: "MD5 and SHA-1 are broken and must not be used for security.",
+		Level:       "warning",
+	}}}
+}
+
+func (r *weakHashRule) EnterImportSpec(ctx *ImportSpecContext) {
+	path := ImportPath(ctx)
+	for _, weak := range weakHashPackages {
+		if path == weak {
+			r.reportAt(ctx, "import of weak hash package %s", path)
+		}
+	}
+}
+
+func (r *weakHashRule) EnterPrimaryExpr(ctx *PrimaryExprContext) {
+	callee, _ := enterCall(ctx)
+	if name, ok := r.calls(callee, weakHashPackages...); ok {
+		r.reportAt(ctx, "use of weak hash function %s", name)
+	}
+}
+
+// Insecure random numbers
+
+// secretWords are the words that end the name of a secret which is
+// not a credential, also joined with the word before them: aesKey,
+// nonce and sessionID are secrets, keyCount and tokenizer are not.
+var secretWords = map[string]bool{
+	"key": true, "nonce": true, "salt": true, "otp": true, "csrf": true,
+	"session": true, "sessionid": true, "sessionkey": true,
+}
+
+func secretName(name string) bool {
+	if credentialName(name) {
+		return true
+	}
+	words := nameWords(name)
+	n := len(words)
+	if n == 0 {
+		return false
+	}
+	for _, last := range []string{words[n-1], strings.TrimSuffix(words[n-1], "s")} {
+		if secretWords[last] || n > 1 && secretWords[words[n-2]+last] {
+			return true
+		}
+	}
+	return false
+}
+
+type insecureRandomRule struct {
+	securityRule
+}
+
+func newInsecureRandomRule() *insecureRandomRule {
+	return &insecureRandomRule{securityRule{info: RuleInfo{
+		ID:          "G404",
+		Name:        "InsecureRandom",
+		Description: "math/rand is predictable; use crypto/rand for tokens, keys and other secrets.",
+		Level:       "error",
+	}}}
+}
+
+func (r *insecureRandomRule) EnterPrimaryExpr(ctx *PrimaryExprContext) {
+	callee, _ := enterCall(ctx)
+	name, ok := r.calls(callee, "math/rand", "math/rand/v2")
+	if !ok {
+		return
+	}
+	if target := secretTarget(ctx); target != "" {
+		r.reportAt(ctx, "%s used to generate %s", name, target)
+	}
+}
+
+// secretTarget returns the name a value computed at ctx ends up in if
+// that name looks like a secret: the variables of an enclosing
+// assignment, the key of a composite literal element, or the function
+// a returned value comes from.
+func secretTarget(ctx antlr.Tree) string {
+	for p := ctx.GetParent(); p != nil; p = p.GetParent() {
+		var names []string
+		switch c := p.(type) {
+		case *ShortVarDeclContext:
+			names = identifierNames(c.IdentifierList())
+		case *VarSpecContext:
+			names = identifierNames(c.IdentifierList())
+		case *AssignmentContext:
+			if lhs, ok := c.ExpressionList(0).(*ExpressionListContext); ok {
+				for _, e := range lhs.AllExpression() {
+					_, name := splitSelector(e.GetText())
+					names = append(names, name)
+				}
+			}
+		case *KeyedElementContext:
+			if c.Key() != nil {
+				names = []string{c.Key().GetText()}
+			}
+		case *ReturnStmtContext:
+			names = []string{EnclosingFunction(c)}
+		case *StatementContext, *FunctionLitContext:
+			return ""
+		default:
+			continue
+		}
+		for _, name := range names {
+			if secretName(name) {
+				return name
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
+func identifierNames(ctx IIdentifierListContext) []string {
+	var names []string
+	if list, ok := ctx.(*IdentifierListContext); ok {
+		for _, id := range list.AllIDENTIFIER() {
+			names = append(names, id.GetText())
+		}
+	}
+	return names
+}
+
+// Command injection
+
+type commandInjectionRule struct {
+	securityRule
+}
+
+func newCommandInjectionRule() *commandInjectionRule {
+	return &commandInjectionRule{securityRule{info: RuleInfo{
+		ID:          "G204",
+		Name:        "CommandInjection",
+		Description: "A subprocess is launched with arguments built by string concatenation or formatting.",
+		Level:       "error",
+	}}}
+}
+
+func (r *commandInjectionRule) EnterPrimaryExpr(ctx *PrimaryExprContext) {
+	callee, args := enterCall(ctx)
+	name, ok := r.calls(callee, "os/exec")
+	if _, fn := splitSelector(name); !ok || (fn != "Command" && fn != "CommandContext") {
+		return
+	}
+	for _, arg := range argumentList(args) {
+		if r.builtString(arg) {
+			r.reportAt(arg.(antlr.ParserRuleContext), "%s called with an argument built from strings", name)
+		}
+	}
+}
+
+// builtString returns true if expr concatenates something other than
+// string literals, or formats a string with fmt.Sprintf.
+func (r *commandInjectionRule) builtString(expr IExpressionContext) bool {
+	e, ok := expr.(*ExpressionContext)
+	if !ok {
+		return false
+	}
+	if op := e.GetAdd_op(); op != nil && op.GetTokenType() == GoParserPLUS {
+		for _, operand := range e.AllExpression() {
+			if _, ok := StringLiteral(operand); !ok {
+				return true
+			}
+		}
+		return false
+	}
+	if callee, _ := CallOf(expr); callee != nil {
+		if name, ok := r.calls(callee, "fmt"); ok {
+			_, fn := splitSelector(name)
+			return fn == "Sprintf" || fn == "Sprint" || fn == "Sprintln"
+		}
+	}
+	return false
+}
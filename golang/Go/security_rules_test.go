@@ -0,0 +1,137 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The findings of the default rules on the examples.
+var exampleFindings = `
+G101 hardcodedPassword.go:9:13 hardcoded credential assigned to password
+G401 weakHash.go:5:7 import of weak hash package crypto/md5
+G401 weakHash.go:12:9 use of weak hash function md5.New
+`
+
+func TestSecurityScannerExamples(t *testing.T) {
+	findings, err := NewSecurityScanner().ScanDir(examplesDir(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got strings.Builder
+	got.WriteString("\n")
+	for _, f := range findings {
+		fmt.Fprintf(&got, "%s %s:%d:%d %s\n", f.RuleID, filepath.Base(f.File), f.Start.Line, f.Start.Column, f.Message)
+	}
+	if got.String() != exampleFindings {
+		t.Errorf("findings:%s\nwant:%s", got.String(), exampleFindings)
+	}
+}
+
+func TestHardcodedCredentialNames(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"password", true},
+		{"pass", true},
+		{"dbPassword", true},
+		{"DB_PASSWORD", true},
+		{"authToken", true},
+		{"HTTPTokens", true},
+		{"api_key", true},
+		{"apiKey", true},
+		{"APIKEY", true},
+		{"clientSecret", true},
+		{"tokenType", false},
+		{"tokenCount", false},
+		{"passwordLength", false},
+		{"keyCount", false},
+		{"monkey", false},
+		{"compass", false},
+		{"apiKeyID", false},
+	}
+	for _, test := range tests {
+		if got := credentialName(test.name); got != test.want {
+			t.Errorf("credentialName(%q) = %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	src := `package p
+
+const tokenType = "bearer"
+
+func f() {
+	tokenCount := "3"
+	authToken := "s3cr3t"
+	var apiKeyID = "id"
+	_, _, _ = tokenCount, authToken, apiKeyID
+}
+`
+	var got []string
+	for _, f := range NewSecurityScanner().Scan(ParseGoString("p.go", src)) {
+		got = append(got, fmt.Sprintf("%s %d: %s", f.RuleID, f.Start.Line, f.Message))
+	}
+	want := []string{"G101 7: hardcoded credential assigned to authToken"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("findings %q, want %q", got, want)
+	}
+}
+
+func TestInsecureRandomAndCommandInjection(t *testing.T) {
+	for name, want := range map[string]bool{
+		"key": true, "aesKey": true, "keys": true, "nonce": true, "salt": true,
+		"sessionID": true, "csrfToken": true, "otp": true, "apiKey": true,
+		"keyCount": false, "monkey": false, "tokenizer": false, "keySize": false,
+		"passwordLength": false, "sessionCount": false,
+	} {
+		if got := secretName(name); got != want {
+			t.Errorf("secretName(%q) = %v, want %v", name, got, want)
+		}
+	}
+
+	src := `package p
+
+import (
+	"fmt"
+	"math/rand"
+	"os/exec"
+)
+
+func newToken() string {
+	return fmt.Sprint(rand.Int())
+}
+
+func f(s *state, user, dir string) {
+	key := rand.Int63()
+	keyCount := rand.Intn(10)
+	monkey := rand.Intn(10)
+	var tokenizer = rand.Intn(2)
+	s.sessionID = rand.Int()
+	cfg := config{Salt: rand.Int(), Size: rand.Int()}
+	_, _, _, _, _ = key, keyCount, monkey, tokenizer, cfg
+
+	exec.Command("ls", "-l", dir)
+	exec.Command("sh", "-c", "ls "+dir)
+	exec.Command("sh", "-c", "ls "+"-l")
+	exec.CommandContext(ctx, "git", fmt.Sprintf("--author=%s", user))
+	exec.LookPath("x" + dir)
+}
+`
+	var got []string
+	for _, f := range NewSecurityScanner().Scan(ParseGoString("p.go", src)) {
+		got = append(got, fmt.Sprintf("%s %d:%d %s", f.RuleID, f.Start.Line, f.Start.Column, f.Message))
+	}
+	want := []string{
+		"G404 10:19 rand.Int used to generate newToken",
+		"G404 14:8 rand.Int63 used to generate key",
+		"G404 18:15 rand.Int used to generate sessionID",
+		"G404 19:21 rand.Int used to generate Salt",
+		"G204 23:26 exec.Command called with an argument built from strings",
+		"G204 25:33 exec.CommandContext called with an argument built from strings",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got findings\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
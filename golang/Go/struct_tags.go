@@ -0,0 +1,263 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// Diagnostic is a problem found in otherwise well-formed source.
+type Diagnostic struct {
+	Pos Position
+	Msg string
+}
+
+func (d Diagnostic) Error() string {
+	return fmt.Sprintf("%d:%d: %s", d.Pos.Line, d.Pos.Column, d.Msg)
+}
+
+// StructTag is the decoded tag of a struct field.
+type StructTag struct {
+	Value string
+	Pairs []TagPair
+
+	lit     antlr.Token
+	offsets []int // rune offset in lit of every byte of Value, and of the end
+}
+
+// TagPair is one key:"value" pair of a struct tag. Pos is the position
+// of the key in the source.
+type TagPair struct {
+	Key   string
+	Value string
+	Pos   Position
+}
+
+// Name returns the part of the value before the first comma, e.g. the
+// field name of a json tag.
+func (p TagPair) Name() string {
+	name, _, _ := strings.Cut(p.Value, ",")
+	return name
+}
+
+// Options returns the comma separated parts of the value after Name.
+func (p TagPair) Options() []string {
+	_, opts, ok := strings.Cut(p.Value, ",")
+	if !ok {
+		return nil
+	}
+	return strings.Split(opts, ",")
+}
+
+// Lookup returns the value of the first pair with key, like
+// reflect.StructTag.Lookup.
+func (t *StructTag) Lookup(key string) (string, bool) {
+	for _, p := range t.Pairs {
+		if p.Key == key {
+			return p.Value, true
+		}
+	}
+	return "", false
+}
+
+// ParseStructTag decodes the tag of a field, raw or interpreted, into
+// its key:"value" pairs. It returns nil if the field has no tag. The
+// tag is checked with the rules of reflect.StructTag and go vet's
+// structtag analyzer; parsing stops at the first malformed pair.
+func ParseStructTag(field *FieldDeclContext) (*StructTag, []Diagnostic) {
+	if field.GetTag() == nil {
+		return nil, nil
+	}
+	t := &StructTag{lit: field.GetTag().GetStart()}
+	t.decode(t.lit.GetText())
+	return t, t.parse()
+}
+
+// decode unquotes lit, remembering where each byte came from so that
+// diagnostics point into the source even after escape sequences.
+func (t *StructTag) decode(lit string) {
+	var value []byte
+	body := lit[1 : len(lit)-1]
+	for i := 0; i < len(body); {
+		start := utf8.RuneCountInString(lit[:i+1])
+		var decoded []byte
+		if lit[0] == '`' {
+			r, size := utf8.DecodeRuneInString(body[i:])
+			i += size
+			if r == '\r' {
+				continue
+			}
+			decoded = []byte(body[i-size : i])
+		} else {
+			r, multibyte, tail, err := strconv.UnquoteChar(body[i:], '"')
+			if err != nil {
+				break
+			}
+			i = len(body) - len(tail)
+			if multibyte {
+				decoded = []byte(string(r))
+			} else {
+				decoded = []byte{byte(r)}
+			}
+		}
+		for range decoded {
+			t.offsets = append(t.offsets, start)
+		}
+		value = append(value, decoded...)
+	}
+	t.offsets = append(t.offsets, utf8.RuneCountInString(lit)-1)
+	t.Value = string(value)
+}
+
+// pos returns the source position of byte i of Value.
+func (t *StructTag) pos(i int) Position {
+	line, column := t.lit.GetLine(), t.lit.GetColumn()
+	for _, r := range []rune(t.lit.GetText())[:t.offsets[i]] {
+		if r == '\n' {
+			line, column = line+1, 0
+		} else {
+			column++
+		}
+	}
+	return Position{line, column}
+}
+
+const (
+	errTagSyntax      = "bad syntax for struct tag pair"
+	errTagKeySyntax   = "bad syntax for struct tag key"
+	errTagValueSyntax = "bad syntax for struct tag value"
+	errTagValueSpace  = "suspicious space in struct tag value"
+	errTagSpace       = "key:\"value\" pairs not separated by spaces"
+)
+
+// parse follows validateStructTag of go vet's structtag analyzer.
+func (t *StructTag) parse() []Diagnostic {
+	var diagnostics []Diagnostic
+	report := func(at int, msg string) []Diagnostic {
+		return append(diagnostics, Diagnostic{t.pos(at), msg})
+	}
+	tag := t.Value
+	for at := 0; at < len(tag); {
+		if at > 0 && tag[at] != ' ' {
+			return report(at, errTagSpace)
+		}
+		for at < len(tag) && tag[at] == ' ' {
+			at++
+		}
+		if at == len(tag) {
+			break
+		}
+		start := at
+		for at < len(tag) && tag[at] > ' ' && tag[at] != ':' && tag[at] != '"' && tag[at] != 0x7f {
+			at++
+		}
+		if at == start {
+			return report(start, errTagKeySyntax)
+		}
+		if at+1 >= len(tag) || tag[at] != ':' {
+			return report(start, errTagSyntax)
+		}
+		if tag[at+1] != '"' {
+			return report(at+1, errTagValueSyntax)
+		}
+		key := tag[start:at]
+		at++
+		quote := at
+		for at++; at < len(tag) && tag[at] != '"'; at++ {
+			if tag[at] == '\\' {
+				at++
+			}
+		}
+		if at >= len(tag) {
+			return report(quote, errTagValueSyntax)
+		}
+		at++
+		value, err := strconv.Unquote(tag[quote:at])
+		if err != nil {
+			return report(quote, errTagValueSyntax)
+		}
+		t.Pairs = append(t.Pairs, TagPair{key, value, t.pos(start)})
+		if len(diagnostics) == 0 && suspiciousSpace(key, value) {
+			diagnostics = report(quote, errTagValueSpace)
+		}
+	}
+	return diagnostics
+}
+
+// suspiciousSpace follows checkTagSpaces of go vet's structtag
+// analyzer: a json name may contain spaces but not its options, an
+// xml value may have one space, between a namespace and a name, and an
+// asn1 value none.
+func suspiciousSpace(key, value string) bool {
+	switch key {
+	case "json":
+		_, opts, ok := strings.Cut(value, ",")
+		if !ok {
+			return false
+		}
+		value = opts
+	case "xml":
+		if strings.Trim(value, " ") != value || strings.Count(value, " ") > 1 {
+			return true
+		}
+		name, opts, ok := strings.Cut(value, ",")
+		if !ok {
+			return false
+		}
+		if strings.HasSuffix(name, " ") {
+			return true
+		}
+		value = opts
+	case "asn1":
+	default:
+		return false
+	}
+	return strings.Contains(value, " ")
+}
+
+// CheckStructTags checks the tag of every struct field in source and
+// reports, per struct, json and xml names used by more than one field.
+func CheckStructTags(source *GoSource) []Diagnostic {
+	c := &structTagChecker{}
+	antlr.ParseTreeWalkerDefault.Walk(c, source.Tree)
+	return c.diagnostics
+}
+
+type structTagChecker struct {
+	BaseGoParserListener
+
+	diagnostics []Diagnostic
+}
+
+func (c *structTagChecker) EnterStructType(ctx *StructTypeContext) {
+	seen := map[string]TagPair{}
+	for _, decl := range ctx.AllFieldDecl() {
+		tag, diagnostics := ParseStructTag(decl.(*FieldDeclContext))
+		c.diagnostics = append(c.diagnostics, diagnostics...)
+		if tag == nil {
+			continue
+		}
+		for _, p := range tag.Pairs {
+			if p.Key != "json" && p.Key != "xml" || p.Name() == "" || p.Name() == "-" {
+				continue
+			}
+			id := p.Key + ":" + p.Name()
+			if p.Key == "xml" {
+				for _, opt := range p.Options() {
+					if opt == "attr" {
+						id += ",attr"
+					}
+				}
+			}
+			if prev, ok := seen[id]; ok {
+				c.diagnostics = append(c.diagnostics, Diagnostic{p.Pos,
+					fmt.Sprintf("struct field repeats %s tag %q also at %d:%d", p.Key, p.Name(), prev.Pos.Line, prev.Pos.Column)})
+				continue
+			}
+			seen[id] = p
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+// The fields and expected diagnostics follow the testdata of go vet's
+// structtag analyzer.
+func TestCheckStructTags(t *testing.T) {
+	fields := []struct{ decl, want string }{
+		{"A int \"hello\"", errTagSyntax},
+		{"B int \"\\tx:\\\"y\\\"\"", errTagKeySyntax},
+		{"C int \"x:\\\"y\\\"\\tx:\\\"y\\\"\"", errTagSpace},
+		{"D int \"x:`y`\"", errTagValueSyntax},
+		{"E int \"ct\\brl:\\\"char\\\"\"", errTagSyntax},
+		{"F int `:\"emptykey\"`", errTagKeySyntax},
+		{"G int `x:\"noEndQuote`", errTagValueSyntax},
+		{"H int `x:\"trunc\\x0\"`", errTagValueSyntax},
+		{"I int `x:\"foo\",y:\"bar\"`", errTagSpace},
+		{"J int `x:\"foo\"y:\"bar\"`", errTagSpace},
+		{"OK0 int `x:\"y\" u:\"v\" w:\"\"`", ""},
+		{"OK1 int `x:\"y:z\" u:\"v\" w:\"\"`", ""},
+		{"OK2 int \"k0:\\\"values contain spaces\\\" k1:\\\"literal\\ttabs\\\" k2:\\\"and\\\\\\\\tescaped\\\\\\\\tabs\\\"\"", ""},
+		{"OK3 int `under_scores:\"and\" CAPS:\"ARE_OK\"`", ""},
+
+		{"Sa int `json:\"a,omitempty\"`", ""},
+		{"Sb int `json:\"b, omitempty\"`", errTagValueSpace},
+		{"Sc int `json:\"c ,omitempty\"`", ""},
+		{"Sd int `json:\"d,omitempty, string\"`", errTagValueSpace},
+		{"Se int `json:\"e name\"`", ""},
+		{"Xe int `xml:\"e local\"`", ""},
+		{"Xf int `xml:\"f \"`", errTagValueSpace},
+		{"Xg int `xml:\" g\"`", errTagValueSpace},
+		{"Xh int `xml:\"h ,omitempty\"`", errTagValueSpace},
+		{"Xi int `xml:\"i, omitempty\"`", errTagValueSpace},
+		{"Xj int `xml:\"j local ,omitempty\"`", errTagValueSpace},
+		{"Xk int `xml:\"k local, omitempty\"`", errTagValueSpace},
+		{"Xl int `xml:\" l local,omitempty\"`", errTagValueSpace},
+		{"Xm int `xml:\"m  local,omitempty\"`", errTagValueSpace},
+		{"Xn int `xml:\"n local,omitempty\"`", ""},
+		{"Ao int `asn1:\"explicit,tag:1\"`", ""},
+		{"Ap int `asn1:\"explicit, tag:1\"`", errTagValueSpace},
+		{"Aq int `asn1:\"optional \"`", errTagValueSpace},
+		{"O int `foo:\" doesn't care \"`", ""},
+		{"P int `json:\"p, b\" xml:\" c\"`", errTagValueSpace},
+	}
+	var src strings.Builder
+	src.WriteString("package p\n\ntype T struct {\n")
+	for _, f := range fields {
+		src.WriteString("\t" + f.decl + "\n")
+	}
+	src.WriteString("}\n")
+
+	source := ParseGoString("p.go", src.String())
+	if len(source.Errors) > 0 {
+		t.Fatal(source.Errors)
+	}
+	got := map[int][]string{}
+	for _, d := range CheckStructTags(source) {
+		got[d.Pos.Line] = append(got[d.Pos.Line], d.Msg)
+	}
+	for i, f := range fields {
+		var want []string
+		if f.want != "" {
+			want = []string{f.want}
+		}
+		if line := i + 4; strings.Join(got[line], "; ") != strings.Join(want, "; ") {
+			t.Errorf("%s: got %q, want %q", f.decl, got[line], want)
+		}
+	}
+}
+
+func TestCheckStructTagsDuplicates(t *testing.T) {
+	src := `package p
+
+type T struct {
+	A int ` + "`json:\"a\" xml:\"a\"`" + `
+	B int ` + "`json:\"a\"`" + `
+	C int ` + "`json:\"-\"`" + `
+	D int ` + "`json:\"-\"`" + `
+	E int ` + "`xml:\"a,attr\"`" + `
+	F int ` + "`xml:\"a,attr\"`" + `
+	G int ` + "`json:\"g,omitempty\"`" + `
+	H int ` + "`json:\",omitempty\"`" + `
+	I int ` + "`json:\",omitempty\"`" + `
+}
+`
+	source := ParseGoString("p.go", src)
+	if len(source.Errors) > 0 {
+		t.Fatal(source.Errors)
+	}
+	var got []string
+	for _, d := range CheckStructTags(source) {
+		got = append(got, d.Error())
+	}
+	want := []string{
+		`5:8: struct field repeats json tag "a" also at 4:8`,
+		`9:8: struct field repeats xml tag "a" also at 8:8`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -112,6 +112,7 @@ BIT_CLEAR              : '&^';
 // Unary operators
 
 EXCLAMATION            : '!';
+TILDE                  : '~';
 
 // Mixed operators
 
@@ -0,0 +1,191 @@
+package samples
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~float32 | ~float64
+}
+
+type Stringer interface {
+	comparable
+	String() string
+}
+
+type Ordered interface {
+	Integer | Float | ~string
+}
+
+type Integer interface {
+	~int | ~int64 | ~uint
+}
+
+type Float interface {
+	~float32 | ~float64
+}
+
+type MyInt int
+
+func (i MyInt) String() string {
+	return strconv.Itoa(int(i))
+}
+
+// Generic types
+
+type List[T any] struct {
+	head *node[T]
+	size int
+}
+
+type node[T any] struct {
+	value T
+	next  *node[T]
+}
+
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+type Tree[T interface{ Less(T) bool }] struct {
+	Left, Right *Tree[T]
+	Value       T
+}
+
+type Set[T comparable] map[T]struct{}
+
+type Numbers[N Number] []N
+
+type Embedding[T any] struct {
+	List[T]
+	*Pair[string, T]
+}
+
+type Matrix[T Number] [3][3]T
+
+// Array types declared with a constant length are not type parameters.
+const N = 4
+
+type Vector [N]int
+
+// Methods on generic types
+
+func (l *List[T]) Push(v T) {
+	l.head = &node[T]{value: v, next: l.head}
+	l.size++
+}
+
+func (l *List[T]) Each(f func(T)) {
+	for n := l.head; n != nil; n = n.next {
+		f(n.value)
+	}
+}
+
+func (l *List[_]) Len() int {
+	return l.size
+}
+
+func (s Set[T]) Add(v T) {
+	s[v] = struct{}{}
+}
+
+func (p Pair[K, V]) String() string {
+	return fmt.Sprintf("%v=%v", p.Key, p.Value)
+}
+
+// Generic functions
+
+func Map[T, U any](s []T, f func(T) U) []U {
+	r := make([]U, 0, len(s))
+	for _, v := range s {
+		r = append(r, f(v))
+	}
+	return r
+}
+
+func Sum[N Number](s ...N) N {
+	var total N
+	for _, v := range s {
+		total += v
+	}
+	return total
+}
+
+func Max[T Ordered](a, b T) T {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func Keys[M ~map[K]V, K comparable, V any](m M) []K {
+	r := make([]K, 0, len(m))
+	for k := range m {
+		r = append(r, k)
+	}
+	return r
+}
+
+func Filter[S ~[]E, E any](s S, keep func(E) bool) S {
+	var r S
+	for _, v := range s {
+		if keep(v) {
+			r = append(r, v)
+		}
+	}
+	return r
+}
+
+func Join[T Stringer](s []T) string {
+	var r string
+	for i, v := range s {
+		if i > 0 {
+			r += ","
+		}
+		r += v.String()
+	}
+	return r
+}
+
+func Zero[T any]() T {
+	var zero T
+	return zero
+}
+
+// Instantiations
+
+func Generics() {
+	ints := []int{1, 2, 3}
+	strs := Map[int, string](ints, strconv.Itoa)
+	lens := Map(strs, func(s string) int { return len(s) })
+
+	fmt.Println(Sum(ints...), Sum[float64](1.5, 2.5), lens)
+	fmt.Println(Max[string]("a", "b"), Max(3, 4))
+
+	var l List[int]
+	l.Push(1)
+	l.Push(2)
+	l.Each(func(v int) { fmt.Println(v) })
+
+	s := Set[string]{}
+	s.Add("x")
+	fmt.Println(Keys(s), Keys[Set[string]](s))
+
+	p := Pair[string, []int]{Key: "k", Value: ints}
+	pairs := []Pair[string, int]{{"a", 1}, {Key: "b", Value: 2}}
+	m := map[string]List[int]{}
+	fmt.Println(p, pairs, m, len(ints))
+
+	even := Filter(ints, func(v int) bool { return v%2 == 0 })
+	fmt.Println(even, Join([]MyInt{1, 2}), Zero[int](), Zero[*List[int]]())
+
+	f := Map[int, int]
+	var g func([]int, func(int) int) []int = Map[int, int]
+	fmt.Println(f(ints, func(v int) int { return v * 2 }), g != nil)
+
+	var v Vector
+	v[0] = ints[1]
+	fmt.Println(v[0], Numbers[int]{1, 2}[1])
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+func Map[T, U any](s []T, f func(T) U) []U {
+	r := make([]U, len(s))
+	for i, v := range s {
+		r[i] = f(v)
+	}
+	return r
+}
+
+func main() {
+	a := []int{1, 2, 3}
+	m := map[string]int{"a": 1}
+	k := "a"
+	i, j := 0, 1
+	grid := [][]int{{1, 2}, {3, 4}}
+	seen := map[[2]int]bool{}
+
+	// Plain index expressions.
+	fmt.Println(a[i], m[k], grid[i][j], a[i+1])
+	seen[[2]int{i, j}] = true
+
+	// Instantiations.
+	fmt.Println(Map[int, string](a, strconv.Itoa))
+	fmt.Println(Map[int](a, func(v int) int { return v * 2 }))
+}
@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ChangeKind says how an entry differs between two go.mod files.
+type ChangeKind int
+
+const (
+	Added ChangeKind = iota
+	Removed
+	Changed
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+// Change is one difference between two go.mod files. Directive is the
+// verb of the entry and Key identifies it within the directive:
+//
+//	module, go, toolchain  empty
+//	godebug                the setting
+//	require                the module path
+//	exclude                the module path and version
+//	replace                the replaced module, with its version if any
+//	retract                the version or interval
+//	tool, ignore           the path
+//
+// Old and New are the values in the two files, empty for an added or
+// removed entry without a value: the version and // indirect mark of
+// a requirement, the replacement of a replace, the rationale of a
+// retraction, the setting of the other directives.
+type Change struct {
+	Directive string
+	Kind      ChangeKind
+	Key       string
+	Old       string
+	New       string
+}
+
+func (c Change) String() string {
+	var b strings.Builder
+	b.WriteString(c.Directive)
+	if c.Key != "" {
+		b.WriteString(" " + c.Key)
+	}
+	switch {
+	case c.Kind == Changed:
+		fmt.Fprintf(&b, ": %s -> %s", c.Old, c.New)
+	case c.Kind == Added && c.New != "":
+		fmt.Fprintf(&b, ": added %s", c.New)
+	case c.Kind == Removed && c.Old != "":
+		fmt.Fprintf(&b, ": removed %s", c.Old)
+	default:
+		fmt.Fprintf(&b, ": %s", c.Kind)
+	}
+	return b.String()
+}
+
+// DiffModFiles compares two go.mod files. The changes are ordered by
+// directive, in the order of the fields of ModFile, then by key.
+func DiffModFiles(old, new *ModFile) []Change {
+	var changes []Change
+	diff := func(directive string, old, new map[string]string) {
+		var keys []string
+		for k := range old {
+			keys = append(keys, k)
+		}
+		for k := range new {
+			if _, ok := old[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			o, inOld := old[k]
+			n, inNew := new[k]
+			switch {
+			case !inOld:
+				changes = append(changes, Change{directive, Added, k, "", n})
+			case !inNew:
+				changes = append(changes, Change{directive, Removed, k, o, ""})
+			case o != n:
+				changes = append(changes, Change{directive, Changed, k, o, n})
+			}
+		}
+	}
+	for _, directive := range modDirectives {
+		diff(directive, modEntries(old, directive), modEntries(new, directive))
+	}
+	return changes
+}
+
+// WriteModDiff writes one line per change.
+func WriteModDiff(w io.Writer, changes []Change) error {
+	for _, c := range changes {
+		if _, err := fmt.Fprintln(w, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var modDirectives = []string{"module", "go", "toolchain", "godebug", "require", "exclude", "replace", "retract", "tool", "ignore"}
+
+// modEntries maps the keys of the entries of a directive to their
+// values, as described by Change.
+func modEntries(f *ModFile, directive string) map[string]string {
+	entries := map[string]string{}
+	switch directive {
+	case "module":
+		if f.Module != nil {
+			entries[""] = f.Module.Path
+		}
+	case "go":
+		if f.Go != nil {
+			entries[""] = f.Go.Version
+		}
+	case "toolchain":
+		if f.Toolchain != nil {
+			entries[""] = f.Toolchain.Name
+		}
+	case "godebug":
+		for _, g := range f.Godebug {
+			entries[g.Key] = g.Value
+		}
+	case "require":
+		for _, r := range f.Require {
+			v := r.Mod.Version
+			if r.Indirect {
+				v += " // indirect"
+			}
+			entries[r.Mod.Path] = v
+		}
+	case "exclude":
+		for _, e := range f.Exclude {
+			entries[e.Mod.String()] = ""
+		}
+	case "replace":
+		for _, r := range f.Replace {
+			entries[r.Old.String()] = r.New.String()
+		}
+	case "retract":
+		for _, r := range f.Retract {
+			key := r.Low
+			if r.High != r.Low {
+				key = "[" + r.Low + ", " + r.High + "]"
+			}
+			entries[key] = r.Rationale
+		}
+	case "tool":
+		for _, t := range f.Tool {
+			entries[t.Path] = ""
+		}
+	case "ignore":
+		for _, i := range f.Ignore {
+			entries[i.Path] = ""
+		}
+	}
+	return entries
+}
@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+const diffOld = `module example.com/m
+
+go 1.21
+
+godebug (
+	panicnil=1
+	asynctimerchan=0
+)
+
+require (
+	example.com/a v1.0.0
+	example.com/b v1.2.0 // indirect
+	example.com/c v0.1.0 // indirect
+	example.com/d v1.0.0
+)
+
+exclude example.com/a v0.9.0
+
+replace example.com/b => ../b
+replace example.com/c v0.1.0 => example.com/fork/c v0.1.1
+
+retract v1.0.1 // Broken.
+retract [v1.1.0, v1.1.5]
+
+tool example.com/a/cmd/gen
+`
+
+const diffNew = `module example.com/m
+
+go 1.22
+toolchain go1.22.3
+
+godebug (
+	panicnil=0
+	httpmuxgo121=1
+)
+
+require (
+	example.com/a v1.1
+	example.com/b v1.2.0
+	example.com/c v0.1.0 // indirect
+	example.com/e v2.0.0+incompatible
+)
+
+exclude example.com/a v0.9.0
+exclude example.com/d v1.0.0
+
+replace example.com/b => ../b2
+replace example.com/c v0.1.0 => example.com/fork/c v0.1.1
+
+retract v1.0.1 // Broken on Windows.
+retract [v1.1.0, v1.1.5]
+retract v1.2.0
+
+ignore ./testdata
+`
+
+// The golden diff lists the changes by directive, in the order of
+// the fields of ModFile, then by key.
+const diffGolden = `go: 1.21 -> 1.22
+toolchain: added go1.22.3
+godebug asynctimerchan: removed 0
+godebug httpmuxgo121: added 1
+godebug panicnil: 1 -> 0
+require example.com/a: v1.0.0 -> v1.1.0
+require example.com/b: v1.2.0 // indirect -> v1.2.0
+require example.com/d: removed v1.0.0
+require example.com/e: added v2.0.0+incompatible
+exclude example.com/d@v1.0.0: added
+replace example.com/b: ../b -> ../b2
+retract v1.0.1: Broken. -> Broken on Windows.
+retract v1.2.0: added
+tool example.com/a/cmd/gen: removed
+ignore ./testdata: added
+`
+
+func TestDiffModFiles(t *testing.T) {
+	old, err := ParseGoMod("old/go.mod", diffOld)
+	if err != nil {
+		t.Fatal(err)
+	}
+	new, err := ParseGoMod("new/go.mod", diffNew)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := WriteModDiff(&b, DiffModFiles(old, new)); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != diffGolden {
+		t.Errorf("got diff\n%s\nwant\n%s", b.String(), diffGolden)
+	}
+	if changes := DiffModFiles(new, new); len(changes) != 0 {
+		t.Errorf("diff of a file with itself: %v", changes)
+	}
+}
@@ -0,0 +1,544 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// ModuleVersion is a module path and version. Version is empty in a
+// replace of every version of a module and for a directory
+// replacement.
+type ModuleVersion struct {
+	Path    string
+	Version string
+}
+
+func (m ModuleVersion) String() string {
+	if m.Version == "" {
+		return m.Path
+	}
+	return m.Path + "@" + m.Version
+}
+
+// Module is the module directive. Deprecated holds the message of a
+// "Deprecated:" paragraph in the comments of the directive.
+type Module struct {
+	Path       string
+	Deprecated string
+	Pos        Position
+}
+
+// GoVersion is the go directive.
+type GoVersion struct {
+	Version string
+	Pos     Position
+}
+
+// Toolchain is the toolchain directive.
+type Toolchain struct {
+	Name string
+	Pos  Position
+}
+
+// Godebug is one key=value setting of a godebug directive.
+type Godebug struct {
+	Key   string
+	Value string
+	Pos   Position
+}
+
+// Require is one requirement. Indirect is set by a "// indirect"
+// comment.
+type Require struct {
+	Mod      ModuleVersion
+	Indirect bool
+	Pos      Position
+}
+
+// Exclude is one excluded module version.
+type Exclude struct {
+	Mod ModuleVersion
+	Pos Position
+}
+
+// Replace is one replacement. New.Version is empty if New.Path is a
+// directory.
+type Replace struct {
+	Old ModuleVersion
+	New ModuleVersion
+	Pos Position
+}
+
+// Retract is one retracted version or closed interval of versions.
+// Low and High are equal for a single version. Rationale is the text
+// of the comments of the retraction.
+type Retract struct {
+	Low       string
+	High      string
+	Rationale string
+	Pos       Position
+}
+
+// Tool is one tool directive.
+type Tool struct {
+	Path string
+	Pos  Position
+}
+
+// Ignore is one directory of an ignore directive.
+type Ignore struct {
+	Path string
+	Pos  Position
+}
+
+// ModFile is the typed model of a go.mod file, with the directives of
+// blocks flattened in file order.
+type ModFile struct {
+	Name      string
+	Module    *Module
+	Go        *GoVersion
+	Toolchain *Toolchain
+	Godebug   []*Godebug
+	Require   []*Require
+	Exclude   []*Exclude
+	Replace   []*Replace
+	Retract   []*Retract
+	Tool      []*Tool
+	Ignore    []*Ignore
+}
+
+// ModError is a syntax error or an invalid directive in a go.mod file.
+type ModError struct {
+	File string
+	Pos  Position
+	Msg  string
+}
+
+func (e ModError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Pos.Line, e.Pos.Column, e.Msg)
+}
+
+// ModErrors is the list of errors of a go.mod file.
+type ModErrors []ModError
+
+func (e ModErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// ParseGoMod parses src as the go.mod file called name and builds its
+// model. If the file has syntax errors or invalid directives, the
+// error is a ModErrors and the model holds the valid directives.
+func ParseGoMod(name, src string) (*ModFile, error) {
+	return modFile(ParseModString(name, src))
+}
+
+// ReadGoMod reads the go.mod file at path and builds its model like
+// ParseGoMod.
+func ReadGoMod(path string) (*ModFile, error) {
+	source, err := ParseModFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return modFile(source)
+}
+
+func modFile(source *ModSource) (*ModFile, error) {
+	var errs ModErrors
+	for _, e := range source.Errors {
+		errs = append(errs, ModError{source.Name, Position{e.Line, e.Column}, e.Msg})
+	}
+	file, invalid := BuildModFile(source)
+	errs = append(errs, invalid...)
+	if len(errs) > 0 {
+		return file, errs
+	}
+	return file, nil
+}
+
+// BuildModFile builds the model of a parsed go.mod file. Directives
+// the go command would reject, such as a second go directive or a non
+// canonical version, are left out and reported.
+func BuildModFile(source *ModSource) (*ModFile, ModErrors) {
+	b := &modBuilder{source: source, file: &ModFile{Name: source.Name}}
+	for _, d := range source.Tree.AllDirective() {
+		switch d := d.GetChild(0).(type) {
+		case *ModuleDirectiveContext:
+			for _, spec := range d.AllModuleSpec() {
+				b.module(d, spec.(*ModuleSpecContext))
+			}
+		case *GoDirectiveContext:
+			b.goVersion(d)
+		case *ToolchainDirectiveContext:
+			b.toolchain(d)
+		case *GodebugDirectiveContext:
+			for _, spec := range d.AllGodebugSpec() {
+				b.godebug(spec.(*GodebugSpecContext))
+			}
+		case *RequireDirectiveContext:
+			for _, spec := range d.AllRequireSpec() {
+				b.require(spec.(*RequireSpecContext))
+			}
+		case *ExcludeDirectiveContext:
+			for _, spec := range d.AllExcludeSpec() {
+				b.exclude(spec.(*ExcludeSpecContext))
+			}
+		case *ReplaceDirectiveContext:
+			for _, spec := range d.AllReplaceSpec() {
+				b.replace(spec.(*ReplaceSpecContext))
+			}
+		case *RetractDirectiveContext:
+			for _, spec := range d.AllRetractSpec() {
+				b.retract(d, spec.(*RetractSpecContext))
+			}
+		case *ToolDirectiveContext:
+			for _, spec := range d.AllToolSpec() {
+				if path, ok := b.path(spec.(*ToolSpecContext).ModulePath()); ok {
+					b.file.Tool = append(b.file.Tool, &Tool{path, StartOf(spec)})
+				}
+			}
+		case *IgnoreDirectiveContext:
+			for _, spec := range d.AllIgnoreSpec() {
+				if path, ok := b.path(spec.(*IgnoreSpecContext).ModulePath()); ok {
+					b.file.Ignore = append(b.file.Ignore, &Ignore{path, StartOf(spec)})
+				}
+			}
+		}
+	}
+	return b.file, b.errs
+}
+
+// StartOf returns the position of the first character of ctx.
+func StartOf(ctx antlr.ParserRuleContext) Position {
+	return tokenPos(ctx.GetStart())
+}
+
+type modBuilder struct {
+	source *ModSource
+	file   *ModFile
+	errs   ModErrors
+}
+
+func (b *modBuilder) errorf(ctx antlr.ParserRuleContext, format string, args ...interface{}) {
+	b.errs = append(b.errs, ModError{b.source.Name, StartOf(ctx), fmt.Sprintf(format, args...)})
+}
+
+// semverIdent is a prerelease identifier: a number without leading
+// zeros or alphanumerics with at least one non-digit.
+const semverIdent = `(?:0|[1-9][0-9]*|[0-9]*[A-Za-z-][0-9A-Za-z-]*)`
+
+// Patterns of the go command for go and toolchain versions, and the
+// semantic versions of require, exclude, replace and retract.
+var (
+	goVersionPattern  = regexp.MustCompile(`^([1-9][0-9]*)\.(0|[1-9][0-9]*)(\.(0|[1-9][0-9]*))?([a-z]+[0-9]+)?$`)
+	toolchainPattern  = regexp.MustCompile(`^default$|^go1($|\.)`)
+	semverPattern     = regexp.MustCompile(`^v(0|[1-9][0-9]*)(?:\.(0|[1-9][0-9]*)(?:\.(0|[1-9][0-9]*)(-` + semverIdent + `(?:\.` + semverIdent + `)*)?(\+[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?)?)?$`)
+	deprecatedPattern = regexp.MustCompile(`(?s)(?:^|\n\n)Deprecated: *(.*?)(?:$|\n\n)`)
+)
+
+// path returns the unquoted text of a module path or directory.
+func (b *modBuilder) path(ctx IModulePathContext) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	return b.unquote(ctx.(antlr.ParserRuleContext))
+}
+
+// version returns a semantic version in canonical form, the way the
+// go command rewrites it: v1.2 becomes v1.2.0 and build metadata other
+// than +incompatible is dropped.
+func (b *modBuilder) version(ctx IVersionContext) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	v, ok := b.unquote(ctx.(antlr.ParserRuleContext))
+	if !ok {
+		return "", false
+	}
+	m := semverPattern.FindStringSubmatch(v)
+	if m == nil {
+		b.errorf(ctx.(antlr.ParserRuleContext), "invalid version %q: must be of the form v1.2.3", v)
+		return "", false
+	}
+	minor, patch := m[2], m[3]
+	if minor == "" {
+		minor = "0"
+	}
+	if patch == "" {
+		patch = "0"
+	}
+	canonical := "v" + m[1] + "." + minor + "." + patch + m[4]
+	if m[5] == "+incompatible" {
+		canonical += m[5]
+	}
+	return canonical, true
+}
+
+func (b *modBuilder) unquote(ctx antlr.ParserRuleContext) (string, bool) {
+	text := ctx.GetText()
+	if strings.HasPrefix(text, `"`) {
+		s, err := strconv.Unquote(text)
+		if err != nil {
+			b.errorf(ctx, "invalid quoted string %s", text)
+			return "", false
+		}
+		return s, true
+	}
+	if strings.ContainsAny(text, "\"'`") {
+		b.errorf(ctx, "unquoted string %s cannot contain quote", text)
+		return "", false
+	}
+	return text, true
+}
+
+func (b *modBuilder) module(d *ModuleDirectiveContext, spec *ModuleSpecContext) {
+	if b.file.Module != nil {
+		b.errorf(spec, "repeated module statement")
+		return
+	}
+	path, ok := b.path(spec.ModulePath())
+	if !ok {
+		return
+	}
+	deprecated := ""
+	if m := deprecatedPattern.FindStringSubmatch(b.comment(d, d.L_PAREN(), spec)); m != nil {
+		deprecated = m[1]
+	}
+	b.file.Module = &Module{path, deprecated, StartOf(d)}
+}
+
+func (b *modBuilder) goVersion(d *GoDirectiveContext) {
+	if b.file.Go != nil {
+		b.errorf(d, "repeated go statement")
+		return
+	}
+	v := d.Version()
+	if v == nil {
+		return
+	}
+	if !goVersionPattern.MatchString(v.GetText()) {
+		b.errorf(d, "invalid go version '%s': must match format 1.23.0", v.GetText())
+		return
+	}
+	b.file.Go = &GoVersion{v.GetText(), StartOf(d)}
+}
+
+func (b *modBuilder) toolchain(d *ToolchainDirectiveContext) {
+	if b.file.Toolchain != nil {
+		b.errorf(d, "repeated toolchain statement")
+		return
+	}
+	name := d.ToolchainName()
+	if name == nil {
+		return
+	}
+	if !toolchainPattern.MatchString(name.GetText()) {
+		b.errorf(d, "invalid toolchain version '%s': must match format go1.23.0 or default", name.GetText())
+		return
+	}
+	b.file.Toolchain = &Toolchain{name.GetText(), StartOf(d)}
+}
+
+func (b *modBuilder) godebug(spec *GodebugSpecContext) {
+	setting := spec.IDENT()
+	if setting == nil {
+		return
+	}
+	text := setting.GetText()
+	eq := strings.Index(text, "=")
+	if eq < 0 || strings.ContainsAny(text, "\"`',") {
+		b.errorf(spec, "usage: godebug key=value")
+		return
+	}
+	b.file.Godebug = append(b.file.Godebug, &Godebug{text[:eq], text[eq+1:], StartOf(spec)})
+}
+
+func (b *modBuilder) require(spec *RequireSpecContext) {
+	path, ok := b.path(spec.ModulePath())
+	if !ok {
+		return
+	}
+	v, ok := b.version(spec.Version())
+	if !ok {
+		return
+	}
+	indirect := spec.Eol().(*EolContext).INDIRECT_COMMENT() != nil
+	b.file.Require = append(b.file.Require, &Require{ModuleVersion{path, v}, indirect, StartOf(spec)})
+}
+
+func (b *modBuilder) exclude(spec *ExcludeSpecContext) {
+	path, ok := b.path(spec.ModulePath())
+	if !ok {
+		return
+	}
+	v, ok := b.version(spec.Version())
+	if !ok {
+		return
+	}
+	b.file.Exclude = append(b.file.Exclude, &Exclude{ModuleVersion{path, v}, StartOf(spec)})
+}
+
+func (b *modBuilder) replace(spec *ReplaceSpecContext) {
+	arrow := spec.ARROW()
+	if arrow == nil || len(spec.AllModulePath()) != 2 {
+		return
+	}
+	var old, new ModuleVersion
+	var ok bool
+	if old.Path, ok = b.path(spec.ModulePath(0)); !ok {
+		return
+	}
+	if new.Path, ok = b.path(spec.ModulePath(1)); !ok {
+		return
+	}
+	for _, v := range spec.AllVersion() {
+		version, ok := b.version(v)
+		if !ok {
+			return
+		}
+		if v.GetStart().GetTokenIndex() < arrow.GetSymbol().GetTokenIndex() {
+			old.Version = version
+		} else {
+			new.Version = version
+		}
+	}
+	switch {
+	case new.Version == "" && !isDirectoryPath(new.Path):
+		b.errorf(spec, "replacement module without version must be directory path (rooted or starting with . or ..)")
+		return
+	case new.Version != "" && isDirectoryPath(new.Path):
+		b.errorf(spec, "replacement module directory path %q cannot have version", new.Path)
+		return
+	}
+	b.file.Replace = append(b.file.Replace, &Replace{old, new, StartOf(spec)})
+}
+
+// isDirectoryPath reports whether a replacement is a directory rather
+// than a module path: a rooted path or one starting with . or .., as
+// on the go command line.
+func isDirectoryPath(path string) bool {
+	return path == "." || path == ".." ||
+		strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../") ||
+		strings.HasPrefix(path, `.\`) || strings.HasPrefix(path, `..\`) ||
+		strings.HasPrefix(path, "/") ||
+		len(path) >= 2 && path[1] == ':' // Windows drive letter
+}
+
+func (b *modBuilder) retract(d *RetractDirectiveContext, spec *RetractSpecContext) {
+	interval, ok := spec.VersionInterval().(*VersionIntervalContext)
+	if !ok {
+		return
+	}
+	var versions []string
+	for _, v := range interval.AllVersion() {
+		version, ok := b.version(v)
+		if !ok {
+			return
+		}
+		versions = append(versions, version)
+	}
+	if len(versions) == 0 {
+		return
+	}
+	b.file.Retract = append(b.file.Retract, &Retract{
+		Low:       versions[0],
+		High:      versions[len(versions)-1],
+		Rationale: b.comment(d, d.L_PAREN(), spec),
+		Pos:       StartOf(spec),
+	})
+}
+
+// comment returns the text of the comments of a directive line: the
+// comment lines right above it and its trailing comment. A line in a
+// block without comments of its own takes those of the block.
+func (b *modBuilder) comment(d antlr.ParserRuleContext, lparen antlr.TerminalNode, spec antlr.ParserRuleContext) string {
+	if lparen == nil {
+		return b.lineComment(d.GetStart(), eolIndex(spec))
+	}
+	if text := b.lineComment(spec.GetStart(), eolIndex(spec)); text != "" {
+		return text
+	}
+	return b.lineComment(d.GetStart(), lparen.GetSymbol().GetTokenIndex()+1)
+}
+
+// eolIndex returns the index of the token ending the line of spec.
+func eolIndex(spec antlr.ParserRuleContext) int {
+	if eol, ok := spec.GetChild(spec.GetChildCount() - 1).(*EolContext); ok {
+		return eol.GetStart().GetTokenIndex()
+	}
+	return spec.GetStop().GetTokenIndex() + 1
+}
+
+// lineComment joins the comments above the line starting with first
+// and the comment at the end of the line, which is ended by the token
+// at eol.
+func (b *modBuilder) lineComment(first antlr.Token, eol int) string {
+	lines := b.commentsAbove(first.GetTokenIndex())
+	if text, ok := b.trailingComment(eol); ok {
+		lines = append(lines, text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// commentsAbove returns the text of the comment lines that end right
+// above the token at index, which starts a line.
+func (b *modBuilder) commentsAbove(index int) []string {
+	var lines []string
+	i := b.skipSpace(index - 1)
+	for i >= 0 && b.tokenType(i) == GoModLexerNEWLINE {
+		comment := b.skipSpace(i - 1)
+		if comment < 0 || !b.isComment(comment) {
+			break
+		}
+		i = b.skipSpace(comment - 1)
+		if i >= 0 && b.tokenType(i) != GoModLexerNEWLINE {
+			break // the comment follows a directive
+		}
+		lines = append([]string{commentText(b.source.Tokens.Get(comment))}, lines...)
+	}
+	return lines
+}
+
+// trailingComment returns the text of the comment before the end of
+// line token at index, if there is one.
+func (b *modBuilder) trailingComment(index int) (string, bool) {
+	if index < 0 || index >= len(b.source.Tokens.GetAllTokens()) {
+		return "", false
+	}
+	if b.isComment(index) {
+		return commentText(b.source.Tokens.Get(index)), true
+	}
+	if i := b.skipSpace(index - 1); i >= 0 && b.isComment(i) {
+		return commentText(b.source.Tokens.Get(i)), true
+	}
+	return "", false
+}
+
+func (b *modBuilder) tokenType(i int) int {
+	return b.source.Tokens.Get(i).GetTokenType()
+}
+
+func (b *modBuilder) isComment(i int) bool {
+	t := b.tokenType(i)
+	return t == GoModLexerLINE_COMMENT || t == GoModLexerINDIRECT_COMMENT
+}
+
+// skipSpace returns the index of the last token at or before i that
+// is not white space.
+func (b *modBuilder) skipSpace(i int) int {
+	for i >= 0 && b.tokenType(i) == GoModLexerWS {
+		i--
+	}
+	return i
+}
+
+func commentText(t antlr.Token) string {
+	return strings.TrimSpace(strings.TrimPrefix(t.GetText(), "//"))
+}
@@ -0,0 +1,239 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGoModExamples(t *testing.T) {
+	var dir string
+	for _, d := range []string{"../examples", "../../examples"} {
+		if _, err := os.Stat(filepath.Join(d, "blocks.mod")); err == nil {
+			dir = d
+		}
+	}
+	if dir == "" {
+		t.Skip("examples not found")
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.mod"))
+	for _, path := range files {
+		if _, err := ReadGoMod(path); err != nil {
+			t.Errorf("%s: %v", path, err)
+		}
+	}
+}
+
+func TestModVersions(t *testing.T) {
+	tests := []struct{ version, want, err string }{
+		{"v1.2.3", "v1.2.3", ""},
+		{"v1.2", "v1.2.0", ""},
+		{"v1", "v1.0.0", ""},
+		{`"v1.2"`, "v1.2.0", ""},
+		{"v1.2.3-pre.1", "v1.2.3-pre.1", ""},
+		{"v0.0.0-20240102150405-abcdef123456", "v0.0.0-20240102150405-abcdef123456", ""},
+		{"v2.0.0+incompatible", "v2.0.0+incompatible", ""},
+		{"v1.2.3+build.5", "v1.2.3", ""},
+		{"v1.2.3-rc.1+build", "v1.2.3-rc.1", ""},
+		{"1.2.3", "", `invalid version "1.2.3": must be of the form v1.2.3`},
+		{"v01.2.3", "", `invalid version "v01.2.3": must be of the form v1.2.3`},
+		{"v1.2-pre", "", `invalid version "v1.2-pre": must be of the form v1.2.3`},
+		{"v1.2.3-01", "", `invalid version "v1.2.3-01": must be of the form v1.2.3`},
+		{"latest", "", `invalid version "latest": must be of the form v1.2.3`},
+	}
+	for _, tt := range tests {
+		f, err := ParseGoMod("go.mod", "module m\n\nrequire example.com/a "+tt.version+"\n")
+		if tt.err != "" {
+			if want := "go.mod:3:22: " + tt.err; err == nil || err.Error() != want {
+				t.Errorf("%s: error %v, want %s", tt.version, err, want)
+			}
+			if len(f.Require) != 0 {
+				t.Errorf("%s: invalid requirement kept", tt.version)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.version, err)
+			continue
+		}
+		if len(f.Require) != 1 || f.Require[0].Mod.Version != tt.want {
+			t.Errorf("%s: got %v, want %s", tt.version, f.Require, tt.want)
+		}
+	}
+}
+
+func TestModIndirect(t *testing.T) {
+	f, err := ParseGoMod("go.mod", `module m
+
+require example.com/a v1.0.0 // indirect
+require example.com/b v1.0.0 //indirect
+require example.com/c v1.0.0 // indirect; pinned for a bug fix
+require example.com/d v1.0.0 // indirectly
+require example.com/e v1.0.0 // not indirect
+require (
+	example.com/f v1.0.0 // indirect
+	// indirect
+	example.com/g v1.0.0
+)
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{
+		"example.com/a": true,
+		"example.com/b": true,
+		"example.com/c": true,
+		"example.com/d": false,
+		"example.com/e": false,
+		"example.com/f": true,
+		"example.com/g": false,
+	}
+	if len(f.Require) != len(want) {
+		t.Fatalf("got %d requirements, want %d", len(f.Require), len(want))
+	}
+	for _, r := range f.Require {
+		if r.Indirect != want[r.Mod.Path] {
+			t.Errorf("%s: Indirect = %v", r.Mod.Path, r.Indirect)
+		}
+	}
+}
+
+func TestModDeprecated(t *testing.T) {
+	tests := []struct{ src, want string }{
+		{"// Deprecated: use example.com/m/v2.\nmodule example.com/m\n", "use example.com/m/v2."},
+		{"module example.com/m // Deprecated: use example.com/m/v2.\n", "use example.com/m/v2."},
+		{"// The old module.\n//\n// Deprecated: use v2.\nmodule example.com/m\n", "use v2."},
+		{"// Deprecated: use v2.\n//\n// It has bugs.\nmodule example.com/m\n", "use v2."},
+		{"// Deprecated: use v2\n// and v3.\nmodule example.com/m\n", "use v2\nand v3."},
+		{"// Not Deprecated: really.\nmodule example.com/m\n", ""},
+		{"// Deprecated: use v2.\n\nmodule example.com/m\n", ""},
+		{"module (\n\t// Deprecated: use v2.\n\texample.com/m\n)\n", "use v2."},
+		{"// Deprecated: use v2.\nmodule (\n\texample.com/m\n)\n", "use v2."},
+		{"module example.com/m\n", ""},
+	}
+	for _, tt := range tests {
+		f, err := ParseGoMod("go.mod", tt.src)
+		if err != nil {
+			t.Errorf("%q: %v", tt.src, err)
+			continue
+		}
+		if f.Module == nil || f.Module.Path != "example.com/m" || f.Module.Deprecated != tt.want {
+			t.Errorf("%q: got %+v, want Deprecated %q", tt.src, f.Module, tt.want)
+		}
+	}
+}
+
+func TestModRetract(t *testing.T) {
+	f, err := ParseGoMod("go.mod", `module m
+
+// Published too early.
+retract v1.0.0
+
+retract [v1.1.0, v1.2] // Data race.
+
+// Broken builds.
+retract (
+	v2.0.0
+	// Leaks memory.
+	v2.1.0
+	[v2.2.0, v2.3.0] // Wrong API.
+)
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Retract{
+		{"v1.0.0", "v1.0.0", "Published too early.", Position{4, 8}},
+		{"v1.1.0", "v1.2.0", "Data race.", Position{6, 8}},
+		{"v2.0.0", "v2.0.0", "Broken builds.", Position{10, 1}},
+		{"v2.1.0", "v2.1.0", "Leaks memory.", Position{12, 1}},
+		{"v2.2.0", "v2.3.0", "Wrong API.", Position{13, 1}},
+	}
+	if len(f.Retract) != len(want) {
+		t.Fatalf("got %d retractions, want %d", len(f.Retract), len(want))
+	}
+	for i, r := range f.Retract {
+		if *r != want[i] {
+			t.Errorf("retract %d: got %+v, want %+v", i, *r, want[i])
+		}
+	}
+}
+
+func TestModReplace(t *testing.T) {
+	tests := []struct{ line, want, err string }{
+		{"example.com/a v1.0.0 => example.com/b v1.1.0", "example.com/a@v1.0.0 => example.com/b@v1.1.0", ""},
+		{"example.com/a => example.com/b v1.1", "example.com/a => example.com/b@v1.1.0", ""},
+		{"example.com/a v1.0.0 => ./a", "example.com/a@v1.0.0 => ./a", ""},
+		{"example.com/a => ../a", "example.com/a => ../a", ""},
+		{"example.com/a => /src/a", "example.com/a => /src/a", ""},
+		{`example.com/a => C:\src\a`, `example.com/a => C:\src\a`, ""},
+		{`example.com/a => .\a`, `example.com/a => .\a`, ""},
+		{`example.com/a => "./a b"`, "example.com/a => ./a b", ""},
+		{"example.com/a => example.com/b", "", "replacement module without version must be directory path (rooted or starting with . or ..)"},
+		{"example.com/a => .a", "", "replacement module without version must be directory path (rooted or starting with . or ..)"},
+		{"example.com/a => ./a v1.0.0", "", `replacement module directory path "./a" cannot have version`},
+		{"example.com/a => /src/a v1.0.0", "", `replacement module directory path "/src/a" cannot have version`},
+		{"example.com/a => example.com/b 1.0", "", `invalid version "1.0": must be of the form v1.2.3`},
+	}
+	for _, tt := range tests {
+		f, err := ParseGoMod("go.mod", "module m\n\nreplace "+tt.line+"\n")
+		if tt.err != "" {
+			if err == nil || !strings.HasSuffix(err.Error(), ": "+tt.err) {
+				t.Errorf("%s: error %v, want %s", tt.line, err, tt.err)
+			}
+			if len(f.Replace) != 0 {
+				t.Errorf("%s: invalid replacement kept", tt.line)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.line, err)
+			continue
+		}
+		if len(f.Replace) != 1 {
+			t.Errorf("%s: got %d replacements", tt.line, len(f.Replace))
+			continue
+		}
+		if got := f.Replace[0].Old.String() + " => " + f.Replace[0].New.String(); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestModErrors(t *testing.T) {
+	f, err := ParseGoMod("go.mod", `module example.com/m
+module example.com/n
+
+go 1.21
+go 1.22
+toolchain go1.22.1
+toolchain 1.22
+
+godebug panicnil
+require "example.com/\q" v1.0.0
+require example.com/b' v1.0.0
+`)
+	want := []string{
+		"go.mod:2:7: repeated module statement",
+		"go.mod:5:0: repeated go statement",
+		"go.mod:7:0: repeated toolchain statement",
+		"go.mod:9:8: usage: godebug key=value",
+		`go.mod:10:8: invalid quoted string "example.com/\q"`,
+		"go.mod:11:8: unquoted string example.com/b' cannot contain quote",
+	}
+	errs, ok := err.(ModErrors)
+	if !ok {
+		t.Fatalf("got %v, want ModErrors", err)
+	}
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Error())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got errors\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if f.Module.Path != "example.com/m" || f.Go.Version != "1.21" || f.Toolchain.Name != "go1.22.1" {
+		t.Errorf("got module %v, go %v, toolchain %v; want the first of each", f.Module, f.Go, f.Toolchain)
+	}
+}
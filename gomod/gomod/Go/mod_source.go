@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// Position is a 1-based line and a 0-based column, as reported by
+// ANTLR tokens.
+type Position struct {
+	Line   int
+	Column int
+}
+
+func tokenPos(t antlr.Token) Position {
+	return Position{t.GetLine(), t.GetColumn()}
+}
+
+// SyntaxError is a lexer or parser error reported while parsing
+// a go.mod file.
+type SyntaxError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e SyntaxError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Msg)
+}
+
+// syntaxErrorCollector records syntax errors instead of printing
+// them to the console.
+type syntaxErrorCollector struct {
+	*antlr.DefaultErrorListener
+
+	errors []SyntaxError
+}
+
+func (c *syntaxErrorCollector) SyntaxError(recognizer antlr.Recognizer, offendingSymbol interface{}, line, column int, msg string, e antlr.RecognitionException) {
+	c.errors = append(c.errors, SyntaxError{line, column, msg})
+}
+
+// ModSource is a go.mod file parsed with GoModParser. The token
+// stream is kept for the comments, which carry deprecation notices
+// and retraction rationales.
+type ModSource struct {
+	Name   string
+	Tokens *antlr.CommonTokenStream
+	Tree   *ModFileContext
+	Errors []SyntaxError
+}
+
+// ParseModSource parses input as a go.mod file. Syntax errors do not
+// stop the parse, they are collected in Errors.
+func ParseModSource(name string, input antlr.CharStream) *ModSource {
+	errors := &syntaxErrorCollector{DefaultErrorListener: antlr.NewDefaultErrorListener()}
+
+	lexer := NewGoModLexer(input)
+	lexer.RemoveErrorListeners()
+	lexer.AddErrorListener(errors)
+
+	tokens := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
+	p := NewGoModParser(tokens)
+	p.RemoveErrorListeners()
+	p.AddErrorListener(errors)
+
+	tree := p.ModFile().(*ModFileContext)
+	return &ModSource{name, tokens, tree, errors.errors}
+}
+
+// ParseModFile reads and parses the go.mod file at path.
+func ParseModFile(path string) (*ModSource, error) {
+	input, err := antlr.NewFileStream(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseModSource(path, input), nil
+}
+
+// ParseModString parses src as a go.mod file called name.
+func ParseModString(name, src string) *ModSource {
+	return ParseModSource(name, antlr.NewInputStream(src))
+}
@@ -0,0 +1,55 @@
+/*
+ * Lexer for go.mod files, following the go.mod reference
+ * https://go.dev/ref/mod#go-mod-file-grammar
+ * and the tokenizer of golang.org/x/mod/modfile.
+ *
+ * Newlines end directives, so they are tokens. Anything that is not
+ * white space, a comment, a string or one of ( ) [ ] { } , is part of
+ * an identifier: module paths, versions and godebug settings all lex
+ * as IDENT.
+ */
+
+lexer grammar GoModLexer;
+
+// Keywords
+
+MODULE    : 'module';
+GO        : 'go';
+TOOLCHAIN : 'toolchain';
+GODEBUG   : 'godebug';
+REQUIRE   : 'require';
+EXCLUDE   : 'exclude';
+REPLACE   : 'replace';
+RETRACT   : 'retract';
+TOOL      : 'tool';
+IGNORE    : 'ignore';
+
+// Punctuation
+
+L_PAREN   : '(';
+R_PAREN   : ')';
+L_BRACKET : '[';
+R_BRACKET : ']';
+COMMA     : ',';
+ARROW     : '=>';
+
+// Strings. Raw strings are tokens like in the go command, but no
+// directive accepts them.
+
+INTERPRETED_STRING : '"' (~["\\\r\n] | '\\' ~[\r\n])* '"';
+RAW_STRING         : '`' ~[`\r\n]* '`';
+
+// Comments. A "// indirect" comment marks a requirement that the main
+// module does not import directly; the go command also accepts a note
+// after a semicolon and a space, "// indirect; <note>". Other comments
+// are hidden.
+
+INDIRECT_COMMENT : '//' [ \t]* 'indirect' ([ \t]* | ';' [ \t]+ ~[ \t\r\n] ~[\r\n]*);
+LINE_COMMENT     : '//' ~[\r\n]* -> channel(HIDDEN);
+
+IDENT : '/'? IdentChar+ ('/' IdentChar+)* '/'? | '/';
+
+NEWLINE : '\r'? '\n';
+WS      : [ \t\r]+ -> channel(HIDDEN);
+
+fragment IdentChar : ~[ \t\r\n()[\]{},"`/];
@@ -0,0 +1,152 @@
+/*
+ * Parser for go.mod files, following the go.mod reference
+ * https://go.dev/ref/mod#go-mod-file-grammar
+ *
+ * Every directive but go and toolchain may be written as a block:
+ *
+ *     require (
+ *         golang.org/x/text v0.3.7
+ *         golang.org/x/sys v0.1.0 // indirect
+ *     )
+ *
+ * The rules accept the arguments the go command accepts; checking
+ * versions and paths is left to the target code.
+ */
+
+parser grammar GoModParser;
+
+options {
+    tokenVocab = GoModLexer;
+}
+
+modFile
+    : (directive | emptyLine)* EOF
+    ;
+
+directive
+    : moduleDirective
+    | goDirective
+    | toolchainDirective
+    | godebugDirective
+    | requireDirective
+    | excludeDirective
+    | replaceDirective
+    | retractDirective
+    | toolDirective
+    | ignoreDirective
+    ;
+
+moduleDirective
+    : MODULE (moduleSpec | L_PAREN (emptyLine (moduleSpec | emptyLine)*)? R_PAREN eol)
+    ;
+
+moduleSpec
+    : modulePath eol
+    ;
+
+goDirective
+    : GO version eol
+    ;
+
+toolchainDirective
+    : TOOLCHAIN toolchainName eol
+    ;
+
+godebugDirective
+    : GODEBUG (godebugSpec | L_PAREN (emptyLine (godebugSpec | emptyLine)*)? R_PAREN eol)
+    ;
+
+// key=value, without spaces.
+godebugSpec
+    : IDENT eol
+    ;
+
+requireDirective
+    : REQUIRE (requireSpec | L_PAREN (emptyLine (requireSpec | emptyLine)*)? R_PAREN eol)
+    ;
+
+requireSpec
+    : modulePath version eol
+    ;
+
+excludeDirective
+    : EXCLUDE (excludeSpec | L_PAREN (emptyLine (excludeSpec | emptyLine)*)? R_PAREN eol)
+    ;
+
+excludeSpec
+    : modulePath version eol
+    ;
+
+replaceDirective
+    : REPLACE (replaceSpec | L_PAREN (emptyLine (replaceSpec | emptyLine)*)? R_PAREN eol)
+    ;
+
+// The replacement is a module path and version, or a directory.
+replaceSpec
+    : modulePath version? ARROW modulePath version? eol
+    ;
+
+retractDirective
+    : RETRACT (retractSpec | L_PAREN (emptyLine (retractSpec | emptyLine)*)? R_PAREN eol)
+    ;
+
+retractSpec
+    : versionInterval eol
+    ;
+
+versionInterval
+    : version
+    | L_BRACKET version COMMA version R_BRACKET
+    ;
+
+toolDirective
+    : TOOL (toolSpec | L_PAREN (emptyLine (toolSpec | emptyLine)*)? R_PAREN eol)
+    ;
+
+toolSpec
+    : modulePath eol
+    ;
+
+ignoreDirective
+    : IGNORE (ignoreSpec | L_PAREN (emptyLine (ignoreSpec | emptyLine)*)? R_PAREN eol)
+    ;
+
+ignoreSpec
+    : modulePath eol
+    ;
+
+modulePath
+    : IDENT
+    | INTERPRETED_STRING
+    | keyword
+    ;
+
+version
+    : IDENT
+    | INTERPRETED_STRING
+    ;
+
+toolchainName
+    : IDENT
+    ;
+
+keyword
+    : MODULE
+    | GO
+    | TOOLCHAIN
+    | GODEBUG
+    | REQUIRE
+    | EXCLUDE
+    | REPLACE
+    | RETRACT
+    | TOOL
+    | IGNORE
+    ;
+
+eol
+    : INDIRECT_COMMENT? (NEWLINE | EOF)
+    ;
+
+emptyLine
+    : INDIRECT_COMMENT? NEWLINE
+    ;
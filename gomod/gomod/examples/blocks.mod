@@ -0,0 +1,49 @@
+// Deprecated: use example.com/mod/v2 instead.
+module example.com/mod
+
+go 1.24
+
+godebug (
+	default=go1.21
+	panicnil=1
+	asynctimerchan=0
+)
+
+require (
+	github.com/google/go-cmp v0.6.0
+	golang.org/x/mod v0.17.0
+
+	golang.org/x/sync v0.7.0 // indirect
+	golang.org/x/tools v0.21.0 // indirect; pinned for stringer
+)
+
+exclude (
+	golang.org/x/crypto v1.4.5
+	golang.org/x/text v1.6.7
+)
+
+replace (
+	golang.org/x/net v1.2.3 => example.com/fork/net v1.4.5
+	golang.org/x/sys => example.com/fork/sys v0.2.0
+	golang.org/x/term v0.1.0 => ./fork/term
+	golang.org/x/text => /home/gopher/src/text
+	"example.com/quoted path" => "./local path"
+)
+
+retract (
+	v1.0.0 // Published accidentally.
+	[v1.0.5, v1.1.2] // Contains a data race.
+	v1.9.9
+)
+
+tool (
+	golang.org/x/tools/cmd/stringer
+	example.com/mod/cmd/gen
+)
+
+ignore (
+	./third_party/javascript
+	static
+)
+
+require ()
@@ -0,0 +1,9 @@
+module example.com/nonl
+
+go 1.20
+
+require (
+	example.com/a v1.0.0 // indirect
+)
+
+require example.com/b v0.1.0
\ No newline at end of file
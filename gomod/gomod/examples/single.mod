@@ -0,0 +1,14 @@
+module example.com/hello
+
+go 1.21.0
+
+toolchain go1.22.4
+
+require golang.org/x/text v0.14.0
+require rsc.io/quote v1.5.2 // indirect
+exclude rsc.io/sampler v1.99.99
+replace golang.org/x/net => ../net
+retract v1.0.1 // Published accidentally.
+tool golang.org/x/tools/cmd/stringer
+ignore ./node_modules
+godebug default=go1.21
@@ -0,0 +1,160 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// SumEntry is one line of a go.sum file. GoMod is set if the hash is
+// the one of the module's go.mod file alone, written with a /go.mod
+// suffix after the version; Version does not include the suffix.
+type SumEntry struct {
+	Path    string
+	Version string
+	GoMod   bool
+	Hash    string
+	Pos     Position
+}
+
+func (e *SumEntry) String() string {
+	v := e.Version
+	if e.GoMod {
+		v += "/go.mod"
+	}
+	return e.Path + " " + v + " " + e.Hash
+}
+
+// SumFile is the typed model of a go.sum file, with the entries in
+// file order.
+type SumFile struct {
+	Name    string
+	Entries []*SumEntry
+}
+
+// Lookup returns the entry of the module path at version, of its
+// go.mod file if gomod is set, or nil.
+func (f *SumFile) Lookup(path, version string, gomod bool) *SumEntry {
+	for _, e := range f.Entries {
+		if e.Path == path && e.Version == version && e.GoMod == gomod {
+			return e
+		}
+	}
+	return nil
+}
+
+// Versions returns the versions of the module path with an entry, in
+// file order and without repetition.
+func (f *SumFile) Versions(path string) []string {
+	var versions []string
+	seen := map[string]bool{}
+	for _, e := range f.Entries {
+		if e.Path == path && !seen[e.Version] {
+			seen[e.Version] = true
+			versions = append(versions, e.Version)
+		}
+	}
+	return versions
+}
+
+// SumError is a syntax error or an invalid entry in a go.sum file.
+type SumError struct {
+	File string
+	Pos  Position
+	Msg  string
+}
+
+func (e SumError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Pos.Line, e.Pos.Column, e.Msg)
+}
+
+// SumErrors is the list of errors of a go.sum file.
+type SumErrors []SumError
+
+func (e SumErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// ParseGoSum parses src as the go.sum file called name and builds its
+// model. If the file has syntax errors or invalid entries, the error
+// is a SumErrors and the model holds the valid entries.
+func ParseGoSum(name, src string) (*SumFile, error) {
+	return sumFile(ParseSumString(name, src))
+}
+
+// ReadGoSum reads the go.sum file at path and builds its model like
+// ParseGoSum.
+func ReadGoSum(path string) (*SumFile, error) {
+	source, err := ParseSumFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return sumFile(source)
+}
+
+func sumFile(source *SumSource) (*SumFile, error) {
+	var errs SumErrors
+	for _, e := range source.Errors {
+		errs = append(errs, SumError{source.Name, Position{e.Line, e.Column}, e.Msg})
+	}
+	file, invalid := BuildSumFile(source)
+	errs = append(errs, invalid...)
+	if len(errs) > 0 {
+		return file, errs
+	}
+	return file, nil
+}
+
+// hashPattern is a hash of the go command: h1 is the only algorithm
+// in use, the SHA-256 of a summary of the files, base64 encoded.
+var hashPattern = regexp.MustCompile(`^h1:[A-Za-z0-9+/]{43}=$`)
+
+// BuildSumFile builds the model of a parsed go.sum file. Entries with
+// a malformed hash, and entries repeating a module version with a
+// different hash, are left out and reported. An unknown algorithm is
+// kept, since the go command ignores it.
+func BuildSumFile(source *SumSource) (*SumFile, SumErrors) {
+	file := &SumFile{Name: source.Name}
+	var errs SumErrors
+	errorf := func(ctx antlr.ParserRuleContext, format string, args ...interface{}) {
+		errs = append(errs, SumError{source.Name, StartOf(ctx), fmt.Sprintf(format, args...)})
+	}
+	for _, ctx := range source.Tree.AllEntry() {
+		ctx := ctx.(*EntryContext)
+		if ctx.ModulePath() == nil || ctx.Version() == nil || ctx.Hash() == nil {
+			continue
+		}
+		e := &SumEntry{
+			Path:    ctx.ModulePath().GetText(),
+			Version: ctx.Version().GetText(),
+			Hash:    ctx.Hash().GetText(),
+			Pos:     StartOf(ctx),
+		}
+		if strings.HasSuffix(e.Version, "/go.mod") {
+			e.Version, e.GoMod = strings.TrimSuffix(e.Version, "/go.mod"), true
+		}
+		if strings.HasPrefix(e.Hash, "h1:") && !hashPattern.MatchString(e.Hash) {
+			errorf(ctx.Hash(), "malformed hash %s", e.Hash)
+			continue
+		}
+		if prev := file.Lookup(e.Path, e.Version, e.GoMod); prev != nil {
+			if prev.Hash != e.Hash && strings.HasPrefix(e.Hash, "h1:") && strings.HasPrefix(prev.Hash, "h1:") {
+				errorf(ctx, "conflicting hash for %s, first at line %d", strings.TrimSuffix(e.String(), " "+e.Hash), prev.Pos.Line)
+			}
+			continue
+		}
+		file.Entries = append(file.Entries, e)
+	}
+	return file, errs
+}
+
+// StartOf returns the position of the first character of ctx.
+func StartOf(ctx antlr.ParserRuleContext) Position {
+	return tokenPos(ctx.GetStart())
+}
@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGoSumExamples(t *testing.T) {
+	var dir string
+	for _, d := range []string{"../examples", "../../examples"} {
+		if _, err := os.Stat(filepath.Join(d, "asm.sum")); err == nil {
+			dir = d
+		}
+	}
+	if dir == "" {
+		t.Skip("examples not found")
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.sum"))
+	for _, path := range files {
+		if _, err := ReadGoSum(path); err != nil {
+			t.Errorf("%s: %v", path, err)
+		}
+	}
+}
+
+const (
+	hashA = "h1:QH6FU8SKoTLaVs80GA8TJuLNkUYl4VokHKlPhVDg4YY="
+	hashB = "h1:8CoAGaCSYXtCPR+8y18Y9aB/kxb8JSS6FRI7mSkvD+8="
+	hashC = "h1:utOm6MM3R3dnawAiJgn0y+xvuYRsm1RKM/4giyfDgV0="
+)
+
+func TestParseGoSum(t *testing.T) {
+	f, err := ParseGoSum("go.sum", strings.Join([]string{
+		"example.com/a v1.0.0 " + hashA,
+		"example.com/a v1.0.0/go.mod " + hashB,
+		"",
+		"example.com/a v1.1.0/go.mod " + hashC,
+		"example.com/a v1.0.0 " + hashA,
+		"example.com/b v0.1.0 h2:c29tZXRoaW5nIG5ld2Vy",
+		"",
+	}, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range f.Entries {
+		got = append(got, e.String())
+	}
+	want := []string{
+		"example.com/a v1.0.0 " + hashA,
+		"example.com/a v1.0.0/go.mod " + hashB,
+		"example.com/a v1.1.0/go.mod " + hashC,
+		"example.com/b v0.1.0 h2:c29tZXRoaW5nIG5ld2Vy",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got entries\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if e := f.Lookup("example.com/a", "v1.0.0", true); e == nil || e.Hash != hashB || e.Pos != (Position{2, 0}) {
+		t.Errorf("Lookup go.mod of example.com/a v1.0.0 = %v", e)
+	}
+	if e := f.Lookup("example.com/a", "v1.1.0", false); e != nil {
+		t.Errorf("Lookup example.com/a v1.1.0 = %v, want nil", e)
+	}
+	if v := f.Versions("example.com/a"); strings.Join(v, " ") != "v1.0.0 v1.1.0" {
+		t.Errorf("Versions(example.com/a) = %v", v)
+	}
+}
+
+func TestGoSumErrors(t *testing.T) {
+	f, err := ParseGoSum("go.sum", strings.Join([]string{
+		"example.com/a v1.0.0 " + hashA,
+		"example.com/a v1.0.0/go.mod " + hashB,
+		"example.com/a v1.0.0 " + hashC,
+		"example.com/a v1.0.0/go.mod " + hashA,
+		"example.com/b v1.0.0 h1:tooshort=",
+		"example.com/c v1.0.0 h1:utOm6MM3R3dnawAiJgn0y+xvuYRsm1RKM/4giyfDgV0",
+		"",
+	}, "\n"))
+	want := []string{
+		"go.sum:3:0: conflicting hash for example.com/a v1.0.0, first at line 1",
+		"go.sum:4:0: conflicting hash for example.com/a v1.0.0/go.mod, first at line 2",
+		"go.sum:5:21: malformed hash h1:tooshort=",
+		"go.sum:6:21: malformed hash h1:utOm6MM3R3dnawAiJgn0y+xvuYRsm1RKM/4giyfDgV0",
+	}
+	errs, ok := err.(SumErrors)
+	if !ok {
+		t.Fatalf("got %v, want SumErrors", err)
+	}
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Error())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got errors\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if len(f.Entries) != 2 || f.Entries[0].Hash != hashA || f.Entries[1].Hash != hashB {
+		t.Errorf("got entries %v, want the first two lines", f.Entries)
+	}
+}
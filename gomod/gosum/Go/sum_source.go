@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// Position is a 1-based line and a 0-based column, as reported by
+// ANTLR tokens.
+type Position struct {
+	Line   int
+	Column int
+}
+
+func tokenPos(t antlr.Token) Position {
+	return Position{t.GetLine(), t.GetColumn()}
+}
+
+// SyntaxError is a lexer or parser error reported while parsing
+// a go.sum file.
+type SyntaxError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e SyntaxError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Msg)
+}
+
+// syntaxErrorCollector records syntax errors instead of printing
+// them to the console.
+type syntaxErrorCollector struct {
+	*antlr.DefaultErrorListener
+
+	errors []SyntaxError
+}
+
+func (c *syntaxErrorCollector) SyntaxError(recognizer antlr.Recognizer, offendingSymbol interface{}, line, column int, msg string, e antlr.RecognitionException) {
+	c.errors = append(c.errors, SyntaxError{line, column, msg})
+}
+
+// SumSource is a go.sum file parsed with GoSumParser.
+type SumSource struct {
+	Name   string
+	Tokens *antlr.CommonTokenStream
+	Tree   *SumFileContext
+	Errors []SyntaxError
+}
+
+// ParseSumSource parses input as a go.sum file. Syntax errors do not
+// stop the parse, they are collected in Errors.
+func ParseSumSource(name string, input antlr.CharStream) *SumSource {
+	errors := &syntaxErrorCollector{DefaultErrorListener: antlr.NewDefaultErrorListener()}
+
+	lexer := NewGoSumLexer(input)
+	lexer.RemoveErrorListeners()
+	lexer.AddErrorListener(errors)
+
+	tokens := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
+	p := NewGoSumParser(tokens)
+	p.RemoveErrorListeners()
+	p.AddErrorListener(errors)
+
+	tree := p.SumFile().(*SumFileContext)
+	return &SumSource{name, tokens, tree, errors.errors}
+}
+
+// ParseSumFile reads and parses the go.sum file at path.
+func ParseSumFile(path string) (*SumSource, error) {
+	input, err := antlr.NewFileStream(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSumSource(path, input), nil
+}
+
+// ParseSumString parses src as a go.sum file called name.
+func ParseSumString(name, src string) *SumSource {
+	return ParseSumSource(name, antlr.NewInputStream(src))
+}
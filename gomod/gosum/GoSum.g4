@@ -0,0 +1,38 @@
+/*
+ * Grammar for go.sum files: one line per module or go.mod file,
+ * holding the module path, the version and the hash of its content.
+ * https://go.dev/ref/mod#go-sum-files
+ *
+ *     golang.org/x/text v0.3.7 h1:olpwvP2KacW1ZWvsR7uQhoyTYvKAupfQrRGBFM352Gk=
+ *     golang.org/x/text v0.3.7/go.mod h1:u+2+/6zg+i71rQMx5EYifcz6MCKuco9NR6JIITiCfzQ=
+ */
+
+grammar GoSum;
+
+sumFile
+    : (entry? NEWLINE)* entry? EOF
+    ;
+
+entry
+    : modulePath version hash
+    ;
+
+modulePath
+    : FIELD
+    ;
+
+// A version, or a version followed by /go.mod for the hash of the
+// module's go.mod file alone.
+version
+    : FIELD
+    ;
+
+// An algorithm name, a colon and the base64 encoded hash.
+hash
+    : HASH
+    ;
+
+HASH    : 'h' [0-9]+ ':' [A-Za-z0-9+/=]+;
+FIELD   : ~[ \t\r\n]+;
+NEWLINE : '\r'? '\n';
+WS      : [ \t\r]+ -> channel(HIDDEN);
@@ -0,0 +1,324 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// ModuleVersion is a module path and version. Version is empty in a
+// replace of every version of a module and for a directory
+// replacement.
+type ModuleVersion struct {
+	Path    string
+	Version string
+}
+
+func (m ModuleVersion) String() string {
+	if m.Version == "" {
+		return m.Path
+	}
+	return m.Path + "@" + m.Version
+}
+
+// GoVersion is the go directive.
+type GoVersion struct {
+	Version string
+	Pos     Position
+}
+
+// Toolchain is the toolchain directive.
+type Toolchain struct {
+	Name string
+	Pos  Position
+}
+
+// Godebug is one key=value setting of a godebug directive.
+type Godebug struct {
+	Key   string
+	Value string
+	Pos   Position
+}
+
+// Use is the directory of one workspace module.
+type Use struct {
+	Path string
+	Pos  Position
+}
+
+// Replace is one replacement. New.Version is empty if New.Path is a
+// directory.
+type Replace struct {
+	Old ModuleVersion
+	New ModuleVersion
+	Pos Position
+}
+
+// WorkFile is the typed model of a go.work file, with the directives
+// of blocks flattened in file order.
+type WorkFile struct {
+	Name      string
+	Go        *GoVersion
+	Toolchain *Toolchain
+	Godebug   []*Godebug
+	Use       []*Use
+	Replace   []*Replace
+}
+
+// WorkError is a syntax error or an invalid directive in a go.work
+// file.
+type WorkError struct {
+	File string
+	Pos  Position
+	Msg  string
+}
+
+func (e WorkError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Pos.Line, e.Pos.Column, e.Msg)
+}
+
+// WorkErrors is the list of errors of a go.work file.
+type WorkErrors []WorkError
+
+func (e WorkErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// ParseGoWork parses src as the go.work file called name and builds
+// its model. If the file has syntax errors or invalid directives, the
+// error is a WorkErrors and the model holds the valid directives.
+func ParseGoWork(name, src string) (*WorkFile, error) {
+	return workFile(ParseWorkString(name, src))
+}
+
+// ReadGoWork reads the go.work file at path and builds its model like
+// ParseGoWork.
+func ReadGoWork(path string) (*WorkFile, error) {
+	source, err := ParseWorkFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return workFile(source)
+}
+
+func workFile(source *WorkSource) (*WorkFile, error) {
+	var errs WorkErrors
+	for _, e := range source.Errors {
+		errs = append(errs, WorkError{source.Name, Position{e.Line, e.Column}, e.Msg})
+	}
+	file, invalid := BuildWorkFile(source)
+	errs = append(errs, invalid...)
+	if len(errs) > 0 {
+		return file, errs
+	}
+	return file, nil
+}
+
+// BuildWorkFile builds the model of a parsed go.work file. Directives
+// the go command would reject are left out and reported.
+func BuildWorkFile(source *WorkSource) (*WorkFile, WorkErrors) {
+	b := &workBuilder{source: source, file: &WorkFile{Name: source.Name}}
+	for _, d := range source.Tree.AllDirective() {
+		switch d := d.GetChild(0).(type) {
+		case *GoDirectiveContext:
+			b.goVersion(d)
+		case *ToolchainDirectiveContext:
+			b.toolchain(d)
+		case *GodebugDirectiveContext:
+			for _, spec := range d.AllGodebugSpec() {
+				b.godebug(spec.(*GodebugSpecContext))
+			}
+		case *UseDirectiveContext:
+			for _, spec := range d.AllUseSpec() {
+				if path, ok := b.path(spec.(*UseSpecContext).ModulePath()); ok {
+					b.file.Use = append(b.file.Use, &Use{path, StartOf(spec)})
+				}
+			}
+		case *ReplaceDirectiveContext:
+			for _, spec := range d.AllReplaceSpec() {
+				b.replace(spec.(*ReplaceSpecContext))
+			}
+		}
+	}
+	return b.file, b.errs
+}
+
+// StartOf returns the position of the first character of ctx.
+func StartOf(ctx antlr.ParserRuleContext) Position {
+	return tokenPos(ctx.GetStart())
+}
+
+type workBuilder struct {
+	source *WorkSource
+	file   *WorkFile
+	errs   WorkErrors
+}
+
+func (b *workBuilder) errorf(ctx antlr.ParserRuleContext, format string, args ...interface{}) {
+	b.errs = append(b.errs, WorkError{b.source.Name, StartOf(ctx), fmt.Sprintf(format, args...)})
+}
+
+// semverIdent is a prerelease identifier: a number without leading
+// zeros or alphanumerics with at least one non-digit.
+const semverIdent = `(?:0|[1-9][0-9]*|[0-9]*[A-Za-z-][0-9A-Za-z-]*)`
+
+// Patterns of the go command for go and toolchain versions, and the
+// semantic versions of replace.
+var (
+	goVersionPattern = regexp.MustCompile(`^([1-9][0-9]*)\.(0|[1-9][0-9]*)(\.(0|[1-9][0-9]*))?([a-z]+[0-9]+)?$`)
+	toolchainPattern = regexp.MustCompile(`^default$|^go1($|\.)`)
+	semverPattern    = regexp.MustCompile(`^v(0|[1-9][0-9]*)(?:\.(0|[1-9][0-9]*)(?:\.(0|[1-9][0-9]*)(-` + semverIdent + `(?:\.` + semverIdent + `)*)?(\+[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?)?)?$`)
+)
+
+// path returns the unquoted text of a module path or directory.
+func (b *workBuilder) path(ctx IModulePathContext) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	return b.unquote(ctx.(antlr.ParserRuleContext))
+}
+
+// version returns a semantic version in canonical form, like the
+// version of a go.mod requirement.
+func (b *workBuilder) version(ctx IVersionContext) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	v, ok := b.unquote(ctx.(antlr.ParserRuleContext))
+	if !ok {
+		return "", false
+	}
+	m := semverPattern.FindStringSubmatch(v)
+	if m == nil {
+		b.errorf(ctx.(antlr.ParserRuleContext), "invalid version %q: must be of the form v1.2.3", v)
+		return "", false
+	}
+	minor, patch := m[2], m[3]
+	if minor == "" {
+		minor = "0"
+	}
+	if patch == "" {
+		patch = "0"
+	}
+	canonical := "v" + m[1] + "." + minor + "." + patch + m[4]
+	if m[5] == "+incompatible" {
+		canonical += m[5]
+	}
+	return canonical, true
+}
+
+func (b *workBuilder) unquote(ctx antlr.ParserRuleContext) (string, bool) {
+	text := ctx.GetText()
+	if strings.HasPrefix(text, `"`) {
+		s, err := strconv.Unquote(text)
+		if err != nil {
+			b.errorf(ctx, "invalid quoted string %s", text)
+			return "", false
+		}
+		return s, true
+	}
+	if strings.ContainsAny(text, "\"'`") {
+		b.errorf(ctx, "unquoted string %s cannot contain quote", text)
+		return "", false
+	}
+	return text, true
+}
+
+func (b *workBuilder) goVersion(d *GoDirectiveContext) {
+	if b.file.Go != nil {
+		b.errorf(d, "repeated go statement")
+		return
+	}
+	v := d.Version()
+	if v == nil {
+		return
+	}
+	if !goVersionPattern.MatchString(v.GetText()) {
+		b.errorf(d, "invalid go version '%s': must match format 1.23.0", v.GetText())
+		return
+	}
+	b.file.Go = &GoVersion{v.GetText(), StartOf(d)}
+}
+
+func (b *workBuilder) toolchain(d *ToolchainDirectiveContext) {
+	if b.file.Toolchain != nil {
+		b.errorf(d, "repeated toolchain statement")
+		return
+	}
+	name := d.ToolchainName()
+	if name == nil {
+		return
+	}
+	if !toolchainPattern.MatchString(name.GetText()) {
+		b.errorf(d, "invalid toolchain version '%s': must match format go1.23.0 or default", name.GetText())
+		return
+	}
+	b.file.Toolchain = &Toolchain{name.GetText(), StartOf(d)}
+}
+
+func (b *workBuilder) godebug(spec *GodebugSpecContext) {
+	setting := spec.IDENT()
+	if setting == nil {
+		return
+	}
+	text := setting.GetText()
+	eq := strings.Index(text, "=")
+	if eq < 0 || strings.ContainsAny(text, "\"`',") {
+		b.errorf(spec, "usage: godebug key=value")
+		return
+	}
+	b.file.Godebug = append(b.file.Godebug, &Godebug{text[:eq], text[eq+1:], StartOf(spec)})
+}
+
+func (b *workBuilder) replace(spec *ReplaceSpecContext) {
+	arrow := spec.ARROW()
+	if arrow == nil || len(spec.AllModulePath()) != 2 {
+		return
+	}
+	var old, new ModuleVersion
+	var ok bool
+	if old.Path, ok = b.path(spec.ModulePath(0)); !ok {
+		return
+	}
+	if new.Path, ok = b.path(spec.ModulePath(1)); !ok {
+		return
+	}
+	for _, v := range spec.AllVersion() {
+		version, ok := b.version(v)
+		if !ok {
+			return
+		}
+		if v.GetStart().GetTokenIndex() < arrow.GetSymbol().GetTokenIndex() {
+			old.Version = version
+		} else {
+			new.Version = version
+		}
+	}
+	switch {
+	case new.Version == "" && !isDirectoryPath(new.Path):
+		b.errorf(spec, "replacement module without version must be directory path (rooted or starting with . or ..)")
+		return
+	case new.Version != "" && isDirectoryPath(new.Path):
+		b.errorf(spec, "replacement module directory path %q cannot have version", new.Path)
+		return
+	}
+	b.file.Replace = append(b.file.Replace, &Replace{old, new, StartOf(spec)})
+}
+
+// isDirectoryPath reports whether a replacement is a directory rather
+// than a module path: a rooted path or one starting with . or .., as
+// on the go command line.
+func isDirectoryPath(path string) bool {
+	return path == "." || path == ".." ||
+		strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../") ||
+		strings.HasPrefix(path, `.\`) || strings.HasPrefix(path, `..\`) ||
+		strings.HasPrefix(path, "/") ||
+		len(path) >= 2 && path[1] == ':' // Windows drive letter
+}
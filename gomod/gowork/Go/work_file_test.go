@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadGoWork(t *testing.T) {
+	var dir string
+	for _, d := range []string{"../examples", "../../examples"} {
+		if _, err := os.Stat(filepath.Join(d, "workspace.work")); err == nil {
+			dir = d
+		}
+	}
+	if dir == "" {
+		t.Skip("examples not found")
+	}
+	f, err := ReadGoWork(filepath.Join(dir, "workspace.work"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Go.Version != "1.23.0" || f.Toolchain.Name != "go1.23.2" {
+		t.Errorf("got go %v, toolchain %v", f.Go, f.Toolchain)
+	}
+	if len(f.Godebug) != 1 || *f.Godebug[0] != (Godebug{"default", "go1.21", Position{5, 8}}) {
+		t.Errorf("got godebug %v", f.Godebug)
+	}
+	var use []string
+	for _, u := range f.Use {
+		use = append(use, u.Path)
+	}
+	if got := strings.Join(use, "|"); got != "./cmd|.|./lib|../tools|./with space" {
+		t.Errorf("got use %s", got)
+	}
+	var replace []string
+	for _, r := range f.Replace {
+		replace = append(replace, r.Old.String()+" => "+r.New.String())
+	}
+	want := []string{
+		"golang.org/x/net@v1.2.3 => example.com/fork/net@v1.4.5",
+		"golang.org/x/text => ../text",
+		"example.com/lib@v1.0.0 => ./lib",
+	}
+	if strings.Join(replace, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got replace\n%s\nwant\n%s", strings.Join(replace, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestGoWorkErrors(t *testing.T) {
+	f, err := ParseGoWork("go.work", `go 1.22
+go 1.23
+toolchain 1.22
+godebug panicnil
+use "./\q"
+replace example.com/a => example.com/b
+replace example.com/a => ./a v1.0.0
+replace example.com/a v1 => example.com/b v1.2
+`)
+	want := []string{
+		"go.work:2:0: repeated go statement",
+		"go.work:3:0: invalid toolchain version '1.22': must match format go1.23.0 or default",
+		"go.work:4:8: usage: godebug key=value",
+		`go.work:5:4: invalid quoted string "./\q"`,
+		"go.work:6:8: replacement module without version must be directory path (rooted or starting with . or ..)",
+		`go.work:7:8: replacement module directory path "./a" cannot have version`,
+	}
+	errs, ok := err.(WorkErrors)
+	if !ok {
+		t.Fatalf("got %v, want WorkErrors", err)
+	}
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Error())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got errors\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if len(f.Replace) != 1 || f.Replace[0].Old.Version != "v1.0.0" || f.Replace[0].New.Version != "v1.2.0" {
+		t.Errorf("got replace %v, want the canonical versions of the last line", f.Replace)
+	}
+}
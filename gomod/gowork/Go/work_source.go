@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// Position is a 1-based line and a 0-based column, as reported by
+// ANTLR tokens.
+type Position struct {
+	Line   int
+	Column int
+}
+
+func tokenPos(t antlr.Token) Position {
+	return Position{t.GetLine(), t.GetColumn()}
+}
+
+// SyntaxError is a lexer or parser error reported while parsing
+// a go.work file.
+type SyntaxError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e SyntaxError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Msg)
+}
+
+// syntaxErrorCollector records syntax errors instead of printing
+// them to the console.
+type syntaxErrorCollector struct {
+	*antlr.DefaultErrorListener
+
+	errors []SyntaxError
+}
+
+func (c *syntaxErrorCollector) SyntaxError(recognizer antlr.Recognizer, offendingSymbol interface{}, line, column int, msg string, e antlr.RecognitionException) {
+	c.errors = append(c.errors, SyntaxError{line, column, msg})
+}
+
+// WorkSource is a go.work file parsed with GoWorkParser. The token
+// stream is kept so that comments can be queried alongside the tree.
+type WorkSource struct {
+	Name   string
+	Tokens *antlr.CommonTokenStream
+	Tree   *WorkFileContext
+	Errors []SyntaxError
+}
+
+// ParseWorkSource parses input as a go.work file. Syntax errors do not
+// stop the parse, they are collected in Errors.
+func ParseWorkSource(name string, input antlr.CharStream) *WorkSource {
+	errors := &syntaxErrorCollector{DefaultErrorListener: antlr.NewDefaultErrorListener()}
+
+	lexer := NewGoWorkLexer(input)
+	lexer.RemoveErrorListeners()
+	lexer.AddErrorListener(errors)
+
+	tokens := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
+	p := NewGoWorkParser(tokens)
+	p.RemoveErrorListeners()
+	p.AddErrorListener(errors)
+
+	tree := p.WorkFile().(*WorkFileContext)
+	return &WorkSource{name, tokens, tree, errors.errors}
+}
+
+// ParseWorkFile reads and parses the go.work file at path.
+func ParseWorkFile(path string) (*WorkSource, error) {
+	input, err := antlr.NewFileStream(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseWorkSource(path, input), nil
+}
+
+// ParseWorkString parses src as a go.work file called name.
+func ParseWorkString(name, src string) *WorkSource {
+	return ParseWorkSource(name, antlr.NewInputStream(src))
+}
@@ -0,0 +1,38 @@
+/*
+ * Lexer for go.work files, following the go.work reference
+ * https://go.dev/ref/mod#go-work-file-grammar
+ *
+ * go.work files share the lexical structure of go.mod files (see
+ * ../gomod/GoModLexer.g4) and have their own set of directives.
+ */
+
+lexer grammar GoWorkLexer;
+
+// Keywords
+
+GO        : 'go';
+TOOLCHAIN : 'toolchain';
+GODEBUG   : 'godebug';
+USE       : 'use';
+REPLACE   : 'replace';
+
+// Punctuation
+
+L_PAREN : '(';
+R_PAREN : ')';
+ARROW   : '=>';
+
+// Strings. Raw strings are tokens like in the go command, but no
+// directive accepts them.
+
+INTERPRETED_STRING : '"' (~["\\\r\n] | '\\' ~[\r\n])* '"';
+RAW_STRING         : '`' ~[`\r\n]* '`';
+
+LINE_COMMENT : '//' ~[\r\n]* -> channel(HIDDEN);
+
+IDENT : '/'? IdentChar+ ('/' IdentChar+)* '/'? | '/';
+
+NEWLINE : '\r'? '\n';
+WS      : [ \t\r]+ -> channel(HIDDEN);
+
+fragment IdentChar : ~[ \t\r\n()[\]{},"`/];
@@ -0,0 +1,97 @@
+/*
+ * Parser for go.work files, following the go.work reference
+ * https://go.dev/ref/mod#go-work-file-grammar
+ *
+ *     go 1.22
+ *
+ *     use (
+ *         ./cmd
+ *         ./lib
+ *     )
+ *
+ *     replace example.com/lib v1.0.0 => ./lib
+ */
+
+parser grammar GoWorkParser;
+
+options {
+    tokenVocab = GoWorkLexer;
+}
+
+workFile
+    : (directive | emptyLine)* EOF
+    ;
+
+directive
+    : goDirective
+    | toolchainDirective
+    | godebugDirective
+    | useDirective
+    | replaceDirective
+    ;
+
+goDirective
+    : GO version eol
+    ;
+
+toolchainDirective
+    : TOOLCHAIN toolchainName eol
+    ;
+
+godebugDirective
+    : GODEBUG (godebugSpec | L_PAREN (emptyLine (godebugSpec | emptyLine)*)? R_PAREN eol)
+    ;
+
+// key=value, without spaces.
+godebugSpec
+    : IDENT eol
+    ;
+
+useDirective
+    : USE (useSpec | L_PAREN (emptyLine (useSpec | emptyLine)*)? R_PAREN eol)
+    ;
+
+// The directory of a workspace module.
+useSpec
+    : modulePath eol
+    ;
+
+replaceDirective
+    : REPLACE (replaceSpec | L_PAREN (emptyLine (replaceSpec | emptyLine)*)? R_PAREN eol)
+    ;
+
+replaceSpec
+    : modulePath version? ARROW modulePath version? eol
+    ;
+
+modulePath
+    : IDENT
+    | INTERPRETED_STRING
+    | keyword
+    ;
+
+version
+    : IDENT
+    | INTERPRETED_STRING
+    ;
+
+toolchainName
+    : IDENT
+    ;
+
+keyword
+    : GO
+    | TOOLCHAIN
+    | GODEBUG
+    | USE
+    | REPLACE
+    ;
+
+eol
+    : NEWLINE
+    | EOF
+    ;
+
+emptyLine
+    : NEWLINE
+    ;
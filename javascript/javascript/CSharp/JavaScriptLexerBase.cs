@@ -9,12 +9,6 @@ using static JavaScriptParser;
 /// </summary>
 public abstract class JavaScriptLexerBase : Lexer
 {
-    /// <summary>
-    /// Stores values of nested modes. By default mode is strict or
-    /// defined externally(useStrictDefault)
-    /// </summary>
-    private Stack<bool> scopeStrictModes = new Stack<bool>();
-
     private IToken _lastToken = null;
 
     /// <summary>
@@ -30,15 +24,71 @@ public abstract class JavaScriptLexerBase : Lexer
     private bool _useStrictCurrent = false;
 
     /// <summary>
-    /// Keeps track of the the current depth of nested template string backticks.
-    /// E.g. after the X in:
-    ///
-    /// `${a ? `${X
-    ///
-    /// templateDepth will be 2. This variable is needed to determine if a `}` is a
-    /// plain CloseBrace, or one that closes an expression inside a template string.
+    /// Kinds of contexts, which tell whether the closing bracket ends an
+    /// expression and whether a } ends a template substitution.
+    /// </summary>
+    private enum ContextKind
+    {
+        Paren,              // ( of an expression or parameter list
+        Header,             // ( of an if, while, for, with, switch or catch header
+        Bracket,            // [
+        Block,              // { of a block
+        Function,           // { of a function declaration, method or arrow function body
+        FunctionExpression, // { of a function expression body
+        Class,              // { of a class body
+        Object,             // { of an object literal or pattern
+        Template,           // ` of a template string
+        Substitution,       // ${ of a template string
+    }
+
+    /// <summary>
+    /// States of the directive prologue: at its start or after a ;, after a
+    /// string literal which may be a directive, and past the prologue.
+    /// </summary>
+    private enum Prologue
+    {
+        Start,
+        Directive,
+        None,
+    }
+
+    /// <summary>
+    /// Records why a bracket or a template string was opened, and the strict
+    /// mode of the code around it, restored when a brace closes.
+    /// </summary>
+    private struct Context
+    {
+        public ContextKind Kind;
+        public bool Strict;
+
+        public Context(ContextKind kind, bool strict)
+        {
+            Kind = kind;
+            Strict = strict;
+        }
+    }
+
+    /// <summary>
+    /// The open brackets, braces and template strings, innermost on top, the
+    /// token before _lastToken and whether a regex literal can follow
+    /// _lastToken, see IsRegexPossible.
     /// </summary>
-    private int _templateDepth = 0;
+    private Stack<Context> _contexts = new Stack<Context>();
+    private Context _closedBrace;
+    private Context _closedParen;
+    private IToken _beforeLastToken = null;
+    private bool _regexPossible = true;
+
+    /// <summary>
+    /// The depth of the contexts plus one where a class keyword or the function
+    /// keyword of a function expression waits for its body, 0 if none, and the
+    /// state of the directive prologue of the program or function body being
+    /// lexed, see ProcessStringLiteral.
+    /// </summary>
+    private int _pendingClass = 0;
+    private int _pendingFunction = 0;
+    private Prologue _prologue = Prologue.Start;
+    private bool _strictBeforeDirective = false;
 
     public JavaScriptLexerBase(ICharStream input)
         : base(input)
@@ -71,17 +121,20 @@ public abstract class JavaScriptLexerBase : Lexer
         return _useStrictCurrent;
     }
 
+    /// <summary>
+    /// Returns true if a } closes a substitution of a template string,
+    /// rather than a brace opened inside it as in `a${ {b: 1}.b }c`.
+    /// </summary>
     public bool IsInTemplateString()
     {
-        return _templateDepth > 0;
+        return _contexts.Count > 0 && _contexts.Peek().Kind == ContextKind.Substitution;
     }
 
     /// <summary>
     /// Return the next token from the character stream and records this last
     /// token in case it resides on the default channel. This recorded token
     /// is used to determine when the lexer could possibly match a regex
-    /// literal.
-    /// 
+    /// literal. Also keeps the stack of open brackets up to date.
     /// </summary>
     /// <returns>
     /// The next token from the character stream.
@@ -93,7 +146,10 @@ public abstract class JavaScriptLexerBase : Lexer
 
         if (next.Channel == DefaultTokenChannel)
         {
-            // Keep track of the last token on the default channel.
+            // Keep track of the last tokens on the default channel.
+            _regexPossible = RegexCanFollow(next);
+            UpdatePrologue(next);
+            _beforeLastToken = _lastToken;
             _lastToken = next;
         }
 
@@ -102,70 +158,337 @@ public abstract class JavaScriptLexerBase : Lexer
 
     protected void ProcessOpenBrace()
     {
-        _useStrictCurrent = scopeStrictModes.Count > 0 && scopeStrictModes.Peek() ? true : UseStrictDefault;
-        scopeStrictModes.Push(_useStrictCurrent);
+        ContextKind kind = BraceContext();
+        if (kind == ContextKind.Function && _pendingFunction == _contexts.Count + 1)
+        {
+            _pendingFunction = 0;
+            if (LastTokenIs(CloseParen))
+            {
+                kind = ContextKind.FunctionExpression; // not the body of async x => {
+            }
+        }
+        PushContext(kind);
+        if (kind == ContextKind.Class)
+        {
+            // A class body is strict mode code.
+            _useStrictCurrent = true;
+        }
     }
 
     protected void ProcessCloseBrace()
     {
-        _useStrictCurrent = scopeStrictModes.Count > 0 ? scopeStrictModes.Pop() : UseStrictDefault;
+        _closedBrace = PopContext();
+        _useStrictCurrent = _closedBrace.Strict;
     }
 
+    /// <summary>
+    /// A "use strict" directive in the prologue of the program or of a function
+    /// body makes the code strict, until UpdatePrologue finds the string is
+    /// part of an expression.
+    /// </summary>
     protected void ProcessStringLiteral()
     {
-        if (_lastToken == null || _lastToken.Type == OpenBrace)
+        // "use strict" may follow a directive without ; on a new line.
+        if (_prologue == Prologue.Start
+            || _prologue == Prologue.Directive && _lastToken.Line < Line)
         {
+            _strictBeforeDirective = _useStrictCurrent;
             if (Text.Equals("\"use strict\"") || Text.Equals("'use strict'"))
             {
-                if (scopeStrictModes.Count > 0)
-                    scopeStrictModes.Pop();
                 _useStrictCurrent = true;
-                scopeStrictModes.Push(_useStrictCurrent);
             }
         }
     }
 
+    /// <summary>
+    /// Follows the directive prologue with next, the new last token. A string
+    /// literal is a directive if a ; or a line break ends its statement, as in
+    /// "use strict"; but not "use strict".length.
+    /// </summary>
+    private void UpdatePrologue(IToken next)
+    {
+        if (_prologue == Prologue.Start)
+        {
+            if (next.Type == StringLiteral)
+            {
+                _prologue = Prologue.Directive;
+            }
+            else if (next.Type != HashBangLine)
+            {
+                _prologue = Prologue.None;
+            }
+        }
+        else if (_prologue == Prologue.Directive)
+        {
+            if (next.Type == SemiColon)
+            {
+                _prologue = Prologue.Start;
+            }
+            else if (next.Line > _lastToken.Line)
+            {
+                if (next.Type != StringLiteral)
+                {
+                    _prologue = Prologue.None;
+                }
+            }
+            else if (next.Type == CloseBrace || next.Type == Eof)
+            {
+                _prologue = Prologue.None;
+            }
+            else
+            {
+                _useStrictCurrent = _strictBeforeDirective;
+                _prologue = Prologue.None;
+            }
+        }
+        if (next.Type == OpenBrace && IsFunctionBody(_contexts.Peek().Kind))
+        {
+            // Pushed by ProcessOpenBrace
+            _prologue = Prologue.Start;
+        }
+    }
+
     public void IncreaseTemplateDepth()
     {
-        _templateDepth++;
+        PushContext(ContextKind.Template);
     }
 
     public void DecreaseTemplateDepth()
     {
-        _templateDepth--;
+        PopContext();
     }
 
     /// <summary>
-    /// Returns true if the lexer can match a regex literal.
+    /// Returns true if the lexer can match a regex literal, that is if the
+    /// syntactic grammar expects an expression after the last token.
     /// </summary>
     protected bool IsRegexPossible()
     {
-        if (_lastToken == null)
-        {
-            // No token has been produced yet: at the start of the input,
-            // no division is possible, so a regex literal _is_ possible.
-            return true;
-        }
+        return _lastToken == null || _regexPossible;
+    }
 
-        switch (_lastToken.Type)
+    /// <summary>
+    /// Returns true if a regex literal can follow next, the new last token,
+    /// and keeps the stack of contexts up to date for the tokens without a
+    /// lexer action.
+    /// </summary>
+    private bool RegexCanFollow(IToken next)
+    {
+        switch (next.Type)
         {
+            case OpenParen:
+                if (LastTokenIs(Class))
+                {
+                    _pendingClass = 0; // a method named class
+                }
+                PushContext(IsHeaderKeyword() ? ContextKind.Header : ContextKind.Paren);
+                return true;
+            case OpenBracket:
+                PushContext(ContextKind.Bracket);
+                return true;
+            case TemplateStringStartExpression:
+                PushContext(ContextKind.Substitution);
+                return true;
+            case CloseParen:
+                // if (x) /re/.test(s) but (x) / 2
+                _closedParen = PopContext();
+                return _closedParen.Kind == ContextKind.Header;
+            case CloseBrace:
+                // {} /re/.test(s) but ({}) / 2 and x = function () {} / 2,
+                // popped by ProcessCloseBrace
+                return _closedBrace.Kind != ContextKind.Object && _closedBrace.Kind != ContextKind.FunctionExpression;
+            case CloseBracket:
+                PopContext();
+                return false;
+            case TemplateCloseBrace:
+                PopContext();
+                return true;
+            case Colon:
+                if (LastTokenIs(Class))
+                {
+                    _pendingClass = 0; // a property named class
+                }
+                return true;
+            case Class:
+                if (LastTokenIs(Dot))
+                {
+                    return false;
+                }
+                _pendingClass = _contexts.Count + 1;
+                return true;
+            case Async:
+                // x = async function () {} / 2
+                _pendingFunction = ExpressionExpected() ? _contexts.Count + 1 : 0;
+                return false;
+            case Function_:
+                if (LastTokenIs(Dot))
+                {
+                    return false;
+                }
+                if (!LastTokenIs(Async))
+                {
+                    _pendingFunction = ExpressionExpected() ? _contexts.Count + 1 : 0;
+                }
+                return true;
             case Identifier:
             case NullLiteral:
             case BooleanLiteral:
             case This:
-            case CloseBracket:
-            case CloseParen:
+            case Super:
+            case As:
+            case From:
+            case NonStrictLet:
             case OctalIntegerLiteral:
             case DecimalLiteral:
             case HexIntegerLiteral:
+            case OctalIntegerLiteral2:
+            case BinaryIntegerLiteral:
+            case BigHexIntegerLiteral:
+            case BigOctalIntegerLiteral:
+            case BigBinaryIntegerLiteral:
+            case BigDecimalIntegerLiteral:
             case StringLiteral:
+            case RegularExpressionLiteral:
+            case BackTick:
             case PlusPlus:
             case MinusMinus:
                 // After any of the tokens above, no regex literal can follow.
                 return false;
-            default:
-                // In all other cases, a regex literal _is_ possible.
+        }
+        if (next.Type >= Break && next.Type <= Yield)
+        {
+            // return /re/, typeof /x/, case /y/: but a.return / 2
+            return !LastTokenIs(Dot);
+        }
+        // In all other cases, a regex literal _is_ possible.
+        return true;
+    }
+
+    /// <summary>
+    /// Returns true if the last token starts a statement with a
+    /// parenthesised header: if, while, for, for await, with, switch or a
+    /// catch clause.
+    /// </summary>
+    private bool IsHeaderKeyword()
+    {
+        if (_beforeLastToken != null && _beforeLastToken.Type == Dot)
+        {
+            return false;
+        }
+        if (LastTokenIs(Await))
+        {
+            return _beforeLastToken != null && _beforeLastToken.Type == For;
+        }
+        return LastTokenIs(If) || LastTokenIs(While) || LastTokenIs(For) || LastTokenIs(With)
+            || LastTokenIs(Switch) || LastTokenIs(Catch);
+    }
+
+    /// <summary>
+    /// Returns the context of a { following the last token: a block where
+    /// a statement can start, a function body after parameters, the body of
+    /// a pending class, an object literal where only an expression can.
+    /// </summary>
+    private ContextKind BraceContext()
+    {
+        if (_pendingClass == _contexts.Count + 1)
+        {
+            _pendingClass = 0;
+            return ContextKind.Class;
+        }
+        if (_lastToken == null)
+        {
+            return ContextKind.Block;
+        }
+        switch (_lastToken.Type)
+        {
+            case CloseParen:
+                // if (x) { but function (x) {
+                return _closedParen.Kind == ContextKind.Header ? ContextKind.Block : ContextKind.Function;
+            case ARROW:
+                return ContextKind.Function;
+        }
+        return ExpressionExpected() ? ContextKind.Object : ContextKind.Block;
+    }
+
+    /// <summary>
+    /// Returns true if an expression, but not a statement, can start after
+    /// the last token.
+    /// </summary>
+    private bool ExpressionExpected()
+    {
+        if (_lastToken == null)
+        {
+            return false;
+        }
+        switch (_lastToken.Type)
+        {
+            case CloseParen:
+                // if (x) statement, or f()\nstatement
+                return false;
+            case ARROW:
                 return true;
+            case SemiColon:
+            case OpenBrace:
+            case CloseBrace:
+            case Else:
+            case Do:
+            case Try:
+            case Catch:
+            case Finally:
+            case Static:
+                return false;
+            case Colon:
+                // A label or case in a block, a property value or the
+                // alternative of a conditional in an expression
+                return _contexts.Count > 0 && !IsBlockLike(_contexts.Peek().Kind);
+        }
+        return _regexPossible;
+    }
+
+    /// <summary>
+    /// Returns true if statements can start in a context.
+    /// </summary>
+    private static bool IsBlockLike(ContextKind kind)
+    {
+        return kind == ContextKind.Block || IsFunctionBody(kind) || kind == ContextKind.Class;
+    }
+
+    /// <summary>
+    /// Returns true if a context is the body of a function.
+    /// </summary>
+    private static bool IsFunctionBody(ContextKind kind)
+    {
+        return kind == ContextKind.Function || kind == ContextKind.FunctionExpression;
+    }
+
+    private void PushContext(ContextKind kind)
+    {
+        _contexts.Push(new Context(kind, _useStrictCurrent));
+    }
+
+    /// <summary>
+    /// Pops the innermost context. Unbalanced closing brackets are taken to
+    /// close a block of the strict mode by default.
+    /// </summary>
+    private Context PopContext()
+    {
+        if (_contexts.Count == 0)
+        {
+            return new Context(ContextKind.Block, _useStrictDefault);
         }
+        if (_pendingClass > _contexts.Count)
+        {
+            _pendingClass = 0; // class keyword of a closed context
+        }
+        if (_pendingFunction > _contexts.Count)
+        {
+            _pendingFunction = 0;
+        }
+        return _contexts.Pop();
+    }
+
+    private bool LastTokenIs(int type)
+    {
+        return _lastToken != null && _lastToken.Type == type;
     }
 }
@@ -26,16 +26,22 @@ bool JavaScriptLexerBase::IsStrictMode()
 
 bool JavaScriptLexerBase::IsInTemplateString()
 {
-	return _templateDepth > 0;
+    // A } closes a substitution of a template string, rather than a brace
+    // opened inside it as in `a${ {b: 1}.b }c`.
+    return !contexts.empty() && contexts.top().kind == SUBSTITUTION;
 }
 
 std::unique_ptr<antlr4::Token> JavaScriptLexerBase::nextToken() {
     auto next = Lexer::nextToken();
 
     if (next->getChannel() == Token::DEFAULT_CHANNEL) {
-        // Keep track of the last token on the default channel.
+        // Keep track of the last tokens on the default channel.
+        regexPossible = regexCanFollow(next->getType());
+        updatePrologue(next->getType(), next->getLine());
+        beforeLastTokenType = lastTokenType;
         lastToken = true;
         lastTokenType = next->getType();
+        lastTokenLine = next->getLine();
     }
 
     return next;
@@ -43,70 +49,272 @@ std::unique_ptr<antlr4::Token> JavaScriptLexerBase::nextToken() {
 
 void JavaScriptLexerBase::ProcessOpenBrace()
 {
-    useStrictCurrent = scopeStrictModes.size() > 0 && scopeStrictModes.top() ? true : useStrictDefault;
-    scopeStrictModes.push(useStrictCurrent);
+    ContextKind kind = braceContext();
+    if (kind == FUNCTION && pendingFunction == contexts.size() + 1)
+    {
+        pendingFunction = 0;
+        if (lastTokenType == JavaScriptLexer::CloseParen)
+        {
+            kind = FUNCTION_EXPRESSION; // not the body of async x => {
+        }
+    }
+    pushContext(kind);
+    if (kind == CLASS)
+    {
+        // A class body is strict mode code.
+        useStrictCurrent = true;
+    }
 }
 
 void JavaScriptLexerBase::ProcessCloseBrace()
 {
-    if (scopeStrictModes.size() > 0) {
-        useStrictCurrent = scopeStrictModes.top();
-        scopeStrictModes.pop();
-    } else {
-        useStrictCurrent = useStrictDefault;
-    }
+    closedBrace = popContext();
+    useStrictCurrent = closedBrace.strict;
 }
 
 void JavaScriptLexerBase::ProcessStringLiteral()
 {
-    if (lastToken || lastTokenType == JavaScriptLexer::OpenBrace)
+    // A "use strict" directive in the prologue of the program or of a
+    // function body makes the code strict, until updatePrologue finds the
+    // string is part of an expression. It may follow a directive without ;
+    // on a new line.
+    if (prologue == PROLOGUE_START
+        || (prologue == PROLOGUE_DIRECTIVE && lastTokenLine < getLine()))
     {
+        strictBeforeDirective = useStrictCurrent;
         std::string text = getText();
         if (text == "\"use strict\"" || text == "'use strict'")
         {
-            if (scopeStrictModes.size() > 0)
-                scopeStrictModes.pop();
             useStrictCurrent = true;
-            scopeStrictModes.push(useStrictCurrent);
         }
     }
 }
 
+void JavaScriptLexerBase::updatePrologue(size_t type, size_t line)
+{
+    // A string literal is a directive if a ; or a line break ends its
+    // statement, as in "use strict"; but not "use strict".length.
+    if (prologue == PROLOGUE_START) {
+        if (type == JavaScriptLexer::StringLiteral) {
+            prologue = PROLOGUE_DIRECTIVE;
+        } else if (type != JavaScriptLexer::HashBangLine) {
+            prologue = PROLOGUE_NONE;
+        }
+    } else if (prologue == PROLOGUE_DIRECTIVE) {
+        if (type == JavaScriptLexer::SemiColon) {
+            prologue = PROLOGUE_START;
+        } else if (line > lastTokenLine) {
+            if (type != JavaScriptLexer::StringLiteral) {
+                prologue = PROLOGUE_NONE;
+            }
+        } else if (type == JavaScriptLexer::CloseBrace || type == IntStream::EOF) {
+            prologue = PROLOGUE_NONE;
+        } else {
+            useStrictCurrent = strictBeforeDirective;
+            prologue = PROLOGUE_NONE;
+        }
+    }
+    if (type == JavaScriptLexer::OpenBrace && isFunctionBody(contexts.top().kind)) {
+        // Pushed by ProcessOpenBrace
+        prologue = PROLOGUE_START;
+    }
+}
+
 void JavaScriptLexerBase::IncreaseTemplateDepth()
 {
-	_templateDepth++;
+    pushContext(TEMPLATE);
 }
 
 void JavaScriptLexerBase::DecreaseTemplateDepth()
 {
-	_templateDepth--;
+    popContext();
 }
 
 bool JavaScriptLexerBase::IsRegexPossible()
 {
-    if (lastToken) {
-        // No token has been produced yet: at the start of the input,
-        // no division is possible, so a regex literal _is_ possible.
-        return true;
-    }
-    
-    switch (lastTokenType) {
+    // No token has been produced yet: at the start of the input,
+    // no division is possible, so a regex literal _is_ possible.
+    // Otherwise the syntactic grammar expects an expression.
+    return regexPossible;
+}
+
+bool JavaScriptLexerBase::regexCanFollow(size_t type)
+{
+    switch (type) {
+        case JavaScriptLexer::OpenParen:
+            if (lastTokenType == JavaScriptLexer::Class) {
+                pendingClass = 0; // a method named class
+            }
+            pushContext(isHeaderKeyword() ? HEADER : PAREN);
+            return true;
+        case JavaScriptLexer::OpenBracket:
+            pushContext(BRACKET);
+            return true;
+        case JavaScriptLexer::TemplateStringStartExpression:
+            pushContext(SUBSTITUTION);
+            return true;
+        case JavaScriptLexer::CloseParen:
+            // if (x) /re/.test(s) but (x) / 2
+            closedParen = popContext();
+            return closedParen.kind == HEADER;
+        case JavaScriptLexer::CloseBrace:
+            // {} /re/.test(s) but ({}) / 2 and x = function () {} / 2,
+            // popped by ProcessCloseBrace
+            return closedBrace.kind != OBJECT && closedBrace.kind != FUNCTION_EXPRESSION;
+        case JavaScriptLexer::CloseBracket:
+            popContext();
+            return false;
+        case JavaScriptLexer::TemplateCloseBrace:
+            popContext();
+            return true;
+        case JavaScriptLexer::Colon:
+            if (lastTokenType == JavaScriptLexer::Class) {
+                pendingClass = 0; // a property named class
+            }
+            return true;
+        case JavaScriptLexer::Class:
+            if (lastTokenType == JavaScriptLexer::Dot) {
+                return false;
+            }
+            pendingClass = contexts.size() + 1;
+            return true;
+        case JavaScriptLexer::Async:
+            // x = async function () {} / 2
+            pendingFunction = expressionExpected() ? contexts.size() + 1 : 0;
+            return false;
+        case JavaScriptLexer::Function_:
+            if (lastTokenType == JavaScriptLexer::Dot) {
+                return false;
+            }
+            if (lastTokenType != JavaScriptLexer::Async) {
+                pendingFunction = expressionExpected() ? contexts.size() + 1 : 0;
+            }
+            return true;
         case JavaScriptLexer::Identifier:
         case JavaScriptLexer::NullLiteral:
         case JavaScriptLexer::BooleanLiteral:
         case JavaScriptLexer::This:
-        case JavaScriptLexer::CloseBracket:
-        case JavaScriptLexer::CloseParen:
+        case JavaScriptLexer::Super:
+        case JavaScriptLexer::As:
+        case JavaScriptLexer::From:
+        case JavaScriptLexer::NonStrictLet:
         case JavaScriptLexer::OctalIntegerLiteral:
         case JavaScriptLexer::DecimalLiteral:
         case JavaScriptLexer::HexIntegerLiteral:
+        case JavaScriptLexer::OctalIntegerLiteral2:
+        case JavaScriptLexer::BinaryIntegerLiteral:
+        case JavaScriptLexer::BigHexIntegerLiteral:
+        case JavaScriptLexer::BigOctalIntegerLiteral:
+        case JavaScriptLexer::BigBinaryIntegerLiteral:
+        case JavaScriptLexer::BigDecimalIntegerLiteral:
         case JavaScriptLexer::StringLiteral:
+        case JavaScriptLexer::RegularExpressionLiteral:
+        case JavaScriptLexer::BackTick:
         case JavaScriptLexer::PlusPlus:
         case JavaScriptLexer::MinusMinus:
             // After any of the tokens above, no regex literal can follow.
             return false;
-        default:
-            // In all other cases, a regex literal _is_ possible.
+    }
+    if (type >= JavaScriptLexer::Break && type <= JavaScriptLexer::Yield) {
+        // return /re/, typeof /x/, case /y/: but a.return / 2
+        return lastTokenType != JavaScriptLexer::Dot;
+    }
+    // In all other cases, a regex literal _is_ possible.
+    return true;
+}
+
+bool JavaScriptLexerBase::isHeaderKeyword()
+{
+    // if, while, for, for await, with, switch or catch, but not a property
+    // named so
+    if (beforeLastTokenType == JavaScriptLexer::Dot) {
+        return false;
+    }
+    if (lastTokenType == JavaScriptLexer::Await) {
+        return beforeLastTokenType == JavaScriptLexer::For;
+    }
+    return lastTokenType == JavaScriptLexer::If || lastTokenType == JavaScriptLexer::While
+        || lastTokenType == JavaScriptLexer::For || lastTokenType == JavaScriptLexer::With
+        || lastTokenType == JavaScriptLexer::Switch || lastTokenType == JavaScriptLexer::Catch;
+}
+
+JavaScriptLexerBase::ContextKind JavaScriptLexerBase::braceContext()
+{
+    // A block where a statement can start, a function body after
+    // parameters, the body of a pending class, an object literal where
+    // only an expression can.
+    if (pendingClass == contexts.size() + 1) {
+        pendingClass = 0;
+        return CLASS;
+    }
+    switch (lastTokenType) {
+        case JavaScriptLexer::CloseParen:
+            // if (x) { but function (x) {
+            return closedParen.kind == HEADER ? BLOCK : FUNCTION;
+        case JavaScriptLexer::ARROW:
+            return FUNCTION;
+    }
+    return expressionExpected() ? OBJECT : BLOCK;
+}
+
+bool JavaScriptLexerBase::expressionExpected()
+{
+    // An expression, but not a statement, can start after the last token.
+    switch (lastTokenType) {
+        case JavaScriptLexer::CloseParen:
+            // if (x) statement, or f()\nstatement
+            return false;
+        case JavaScriptLexer::ARROW:
             return true;
+        case 0: // start of input
+        case JavaScriptLexer::SemiColon:
+        case JavaScriptLexer::OpenBrace:
+        case JavaScriptLexer::CloseBrace:
+        case JavaScriptLexer::Else:
+        case JavaScriptLexer::Do:
+        case JavaScriptLexer::Try:
+        case JavaScriptLexer::Catch:
+        case JavaScriptLexer::Finally:
+        case JavaScriptLexer::Static:
+            return false;
+        case JavaScriptLexer::Colon:
+            // A label or case in a block, a property value or the
+            // alternative of a conditional in an expression
+            return !contexts.empty() && !isBlockLike(contexts.top().kind);
+    }
+    return regexPossible;
+}
+
+bool JavaScriptLexerBase::isBlockLike(ContextKind kind)
+{
+    // Statements can start in a block, a function or a class body.
+    return kind == BLOCK || isFunctionBody(kind) || kind == CLASS;
+}
+
+bool JavaScriptLexerBase::isFunctionBody(ContextKind kind)
+{
+    return kind == FUNCTION || kind == FUNCTION_EXPRESSION;
+}
+
+void JavaScriptLexerBase::pushContext(ContextKind kind)
+{
+    contexts.push({ kind, useStrictCurrent });
+}
+
+JavaScriptLexerBase::Context JavaScriptLexerBase::popContext()
+{
+    // Unbalanced closing brackets are taken to close a block of the
+    // strict mode by default.
+    if (contexts.empty()) {
+        return { BLOCK, useStrictDefault };
+    }
+    if (pendingClass > contexts.size()) {
+        pendingClass = 0; // class keyword of a closed context
+    }
+    if (pendingFunction > contexts.size()) {
+        pendingFunction = 0;
     }
+    Context context = contexts.top();
+    contexts.pop();
+    return context;
 }
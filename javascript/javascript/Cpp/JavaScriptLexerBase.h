@@ -8,14 +8,60 @@ class JavaScriptLexerBase : public antlr4::Lexer {
 public:
     JavaScriptLexerBase(antlr4::CharStream *input): Lexer(input) { }
 
-    std::stack<bool> scopeStrictModes;
-    
     bool lastToken = false;
     size_t lastTokenType = 0;
 
     bool useStrictDefault = false;
     bool useStrictCurrent = false;
-    int _templateDepth = 0;
+
+    // Kinds of contexts, which tell whether the closing bracket ends an
+    // expression and whether a } ends a template substitution.
+    enum ContextKind {
+        PAREN,               // ( of an expression or parameter list
+        HEADER,              // ( of an if, while, for, with, switch or catch header
+        BRACKET,             // [
+        BLOCK,               // { of a block
+        FUNCTION,            // { of a function declaration, method or arrow function body
+        FUNCTION_EXPRESSION, // { of a function expression body
+        CLASS,               // { of a class body
+        OBJECT,              // { of an object literal or pattern
+        TEMPLATE,            // ` of a template string
+        SUBSTITUTION,        // ${ of a template string
+    };
+
+    // States of the directive prologue: at its start or after a ;, after a
+    // string literal which may be a directive, and past the prologue.
+    enum Prologue {
+        PROLOGUE_START,
+        PROLOGUE_DIRECTIVE,
+        PROLOGUE_NONE,
+    };
+
+    // Why a bracket or a template string was opened, and the strict mode
+    // of the code around it, restored when a brace closes.
+    struct Context {
+        ContextKind kind;
+        bool strict;
+    };
+
+    // The open brackets, braces and template strings, innermost on top, the
+    // type of the token before the last one and whether a regex literal can
+    // follow the last token.
+    std::stack<Context> contexts;
+    Context closedBrace = { BLOCK, false };
+    Context closedParen = { PAREN, false };
+    size_t beforeLastTokenType = 0;
+    bool regexPossible = true;
+
+    // The depth of the contexts plus one where a class keyword or the
+    // function keyword of a function expression waits for its body, 0 if
+    // none, the line of the last token and the state of the directive
+    // prologue of the program or function body being lexed.
+    size_t pendingClass = 0;
+    size_t pendingFunction = 0;
+    size_t lastTokenLine = 0;
+    Prologue prologue = PROLOGUE_START;
+    bool strictBeforeDirective = false;
 
     bool IsStartOfFile();
     bool getStrictDefault();
@@ -29,4 +75,15 @@ public:
     void IncreaseTemplateDepth();
     void DecreaseTemplateDepth();
     bool IsRegexPossible();
+
+private:
+    bool regexCanFollow(size_t type);
+    void updatePrologue(size_t type, size_t line);
+    bool isHeaderKeyword();
+    ContextKind braceContext();
+    bool expressionExpected();
+    static bool isBlockLike(ContextKind kind);
+    static bool isFunctionBody(ContextKind kind);
+    void pushContext(ContextKind kind);
+    Context popContext();
 };
@@ -0,0 +1,197 @@
+package parser
+
+// Function metrics records and their bookkeeping. They do not depend on
+// the grammar: golang/Go and javascript/javascript/Go hold the same copy
+// of this file, so that one dashboard can consume the metrics of both
+// languages. Keep the two copies in sync.
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// FunctionMetrics are the metrics of a function, method or function
+// literal. Lines counts the lines holding at least one token on the
+// default channel, so comment and blank lines are not included.
+type FunctionMetrics struct {
+	File       string `json:"file"`
+	Name       string `json:"name"`
+	Line       int    `json:"line"`
+	EndLine    int    `json:"endLine"`
+	Parameters int    `json:"parameters"`
+	Cyclomatic int    `json:"cyclomatic"`
+	Cognitive  int    `json:"cognitive"`
+	Lines      int    `json:"lines"`
+}
+
+// MetricsListener is implemented by the metrics listener of each
+// grammar. After a tree is walked, Functions returns the metrics of
+// every function in the order the functions start.
+type MetricsListener interface {
+	antlr.ParseTreeListener
+	Functions() []FunctionMetrics
+}
+
+// MetricsCounter does the bookkeeping shared by the metrics listeners.
+// A listener calls BeginFunction and EndFunction around each function
+// and reports the constructs it finds in between:
+//
+//   - Branch for each decision point of cyclomatic complexity,
+//   - Structure for each construct that breaks the linear flow and
+//     costs more when nested (if, loops, switch, catch),
+//   - Flow for each break in the flow that does not depend on nesting
+//     (else, a sequence of like boolean operators, a jump to a label),
+//   - Nest and Unnest around the bodies that increase nesting.
+//
+// Constructs outside of any function are ignored.
+type MetricsCounter struct {
+	file      string
+	tokens    *antlr.CommonTokenStream
+	functions []*FunctionMetrics
+	stack     []*functionCount
+}
+
+type functionCount struct {
+	metrics  *FunctionMetrics
+	nesting  int
+	literals int
+}
+
+// NewMetricsCounter returns a counter for the functions of file.
+func NewMetricsCounter(file string, tokens *antlr.CommonTokenStream) *MetricsCounter {
+	return &MetricsCounter{file: file, tokens: tokens}
+}
+
+// Functions returns the metrics of the functions counted so far.
+func (c *MetricsCounter) Functions() []FunctionMetrics {
+	functions := make([]FunctionMetrics, len(c.functions))
+	for i, f := range c.functions {
+		functions[i] = *f
+	}
+	return functions
+}
+
+func (c *MetricsCounter) current() *functionCount {
+	if len(c.stack) == 0 {
+		return nil
+	}
+	return c.stack[len(c.stack)-1]
+}
+
+// BeginFunction starts counting the function ctx.
+func (c *MetricsCounter) BeginFunction(name string, parameters int, ctx antlr.ParserRuleContext) {
+	m := &FunctionMetrics{
+		File:       c.file,
+		Name:       name,
+		Line:       ctx.GetStart().GetLine(),
+		EndLine:    lastLine(ctx.GetStop()),
+		Parameters: parameters,
+		Cyclomatic: 1,
+		Lines:      c.lines(ctx),
+	}
+	c.functions = append(c.functions, m)
+	c.stack = append(c.stack, &functionCount{metrics: m})
+}
+
+// EndFunction stops counting the innermost function.
+func (c *MetricsCounter) EndFunction() {
+	c.stack = c.stack[:len(c.stack)-1]
+}
+
+// AnonymousName returns a name for a function literal: the name of
+// the enclosing function followed by .func1, .func2, ... as in Go
+// stack traces.
+func (c *MetricsCounter) AnonymousName() string {
+	f := c.current()
+	if f == nil {
+		return "func"
+	}
+	f.literals++
+	return f.metrics.Name + ".func" + strconv.Itoa(f.literals)
+}
+
+// Branch adds a decision point.
+func (c *MetricsCounter) Branch() {
+	if f := c.current(); f != nil {
+		f.metrics.Cyclomatic++
+	}
+}
+
+// Structure adds a flow breaking construct at the current nesting.
+func (c *MetricsCounter) Structure() {
+	if f := c.current(); f != nil {
+		f.metrics.Cognitive += 1 + f.nesting
+	}
+}
+
+// Flow adds a flow breaking construct that ignores nesting.
+func (c *MetricsCounter) Flow() {
+	if f := c.current(); f != nil {
+		f.metrics.Cognitive++
+	}
+}
+
+// Nest increases the nesting of the constructs that follow.
+func (c *MetricsCounter) Nest() {
+	if f := c.current(); f != nil {
+		f.nesting++
+	}
+}
+
+// Unnest undoes Nest.
+func (c *MetricsCounter) Unnest() {
+	if f := c.current(); f != nil {
+		f.nesting--
+	}
+}
+
+// lines counts the lines of ctx with a token on the default channel.
+func (c *MetricsCounter) lines(ctx antlr.ParserRuleContext) int {
+	stop := ctx.GetStop()
+	if c.tokens == nil || stop == nil {
+		return 0
+	}
+	seen := map[int]bool{}
+	for i := ctx.GetStart().GetTokenIndex(); i <= stop.GetTokenIndex(); i++ {
+		t := c.tokens.Get(i)
+		if t.GetChannel() != antlr.TokenDefaultChannel || t.GetStop() < t.GetStart() {
+			continue // hidden, or a token inserted by the lexer
+		}
+		for line := t.GetLine(); line <= lastLine(t); line++ {
+			seen[line] = true
+		}
+	}
+	return len(seen)
+}
+
+// lastLine returns the line a token ends on.
+func lastLine(t antlr.Token) int {
+	if t == nil {
+		return 0
+	}
+	return t.GetLine() + strings.Count(t.GetText(), "\n")
+}
+
+// WriteMetricsCSV writes metrics as CSV with a header line.
+func WriteMetricsCSV(w io.Writer, metrics []FunctionMetrics) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"file", "name", "line", "endLine", "parameters", "cyclomatic", "cognitive", "lines"})
+	for _, m := range metrics {
+		out.Write([]string{m.File, m.Name, strconv.Itoa(m.Line), strconv.Itoa(m.EndLine),
+			strconv.Itoa(m.Parameters), strconv.Itoa(m.Cyclomatic), strconv.Itoa(m.Cognitive), strconv.Itoa(m.Lines)})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// WriteMetricsJSON writes metrics as a JSON array.
+func WriteMetricsJSON(w io.Writer, metrics []FunctionMetrics) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(metrics)
+}
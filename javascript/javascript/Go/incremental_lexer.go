@@ -0,0 +1,189 @@
+package parser
+
+import (
+	"sort"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// IncrementalLexer keeps the tokens of a JavaScript text and lexes them
+// again after an edit, from the last line start before the edit whose
+// tokens did not look at the edited text, up to the first line start
+// after it where the lexer is in the same state as before the edit: the
+// following tokens are only moved.
+type IncrementalLexer struct {
+	text   []rune
+	tokens []antlr.Token
+	reach  []int // the index of the last character each token looked at
+	lines  []lineState
+}
+
+// lineState is a snapshot of the lexer at the start of a line, taken
+// before lexing the token at index token. Reach is the index of the last
+// character the tokens before it looked at, -1 if none.
+type lineState struct {
+	snapshot *LexerSnapshot
+	token    int
+	reach    int
+}
+
+// lookaheadStream records the index of the last character the lexer
+// looks at: matching a token can look past its end, for instance up to
+// the end of the input for an unterminated comment.
+type lookaheadStream struct {
+	antlr.CharStream
+	reach int
+}
+
+func (s *lookaheadStream) LA(i int) int {
+	if r := s.Index() + i - 1; i > 0 && r > s.reach {
+		s.reach = r
+	}
+	return s.CharStream.LA(i)
+}
+
+// NewIncrementalLexer lexes text, as strict mode code if
+// useStrictDefault is true, see SetUseStrictDefault.
+func NewIncrementalLexer(text string, useStrictDefault bool) *IncrementalLexer {
+	x := &IncrementalLexer{text: []rune(text)}
+	lexer, input := x.newLexer()
+	lexer.SetUseStrictDefault(useStrictDefault)
+	x.lex(lexer, input, nil, 0, 0)
+	return x
+}
+
+// Text returns the current text.
+func (x *IncrementalLexer) Text() string {
+	return string(x.text)
+}
+
+// Tokens returns the tokens of the current text, on all channels, up
+// to EOF. Their start and stop are indexes of characters (runes).
+func (x *IncrementalLexer) Tokens() []antlr.Token {
+	return x.tokens
+}
+
+// Edit replaces the characters from start to end, excluded, with text
+// and lexes the text again where needed. The tokens from first to
+// last, excluded, are new; the tokens before first are unchanged and
+// the ones from last are those after the edit, moved.
+func (x *IncrementalLexer) Edit(start, end int, text string) (first, last int) {
+	old := *x
+	inserted := []rune(text)
+	x.text = make([]rune, 0, len(old.text)-(end-start)+len(inserted))
+	x.text = append(append(append(x.text, old.text[:start]...), inserted...), old.text[end:]...)
+
+	// The tokens before the last line start whose tokens did not look
+	// at the edited text stay. The reach of the line states grows, and
+	// the one of the first line state is -1.
+	i := sort.Search(len(old.lines), func(i int) bool {
+		return old.lines[i].reach >= start
+	}) - 1
+	first = old.lines[i].token
+	x.lines = old.lines[:i:i]
+	x.tokens = old.tokens[:first:first]
+	x.reach = old.reach[:first:first]
+
+	lexer, input := x.newLexer()
+	lexer.Restore(old.lines[i].snapshot)
+	last = x.lex(lexer, input, &old, start+len(inserted), len(inserted)-(end-start))
+	return first, last
+}
+
+func (x *IncrementalLexer) newLexer() (*JavaScriptLexer, *lookaheadStream) {
+	input := &lookaheadStream{CharStream: antlr.NewInputStream(string(x.text))}
+	lexer := NewJavaScriptLexer(input)
+	lexer.RemoveErrorListeners()
+	return lexer, input
+}
+
+// lex appends the tokens of lexer up to EOF, taking a snapshot at each
+// line start. From the index from, where the text is the one of old
+// moved by delta, it stops at the first line start where the state is
+// the one of old there, and moves the rest of old instead. It returns
+// the index of the first moved token, the number of tokens if none.
+func (x *IncrementalLexer) lex(lexer *JavaScriptLexer, input *lookaheadStream, old *IncrementalLexer, from, delta int) int {
+	reach := -1
+	if n := len(x.lines); n > 0 {
+		reach = x.lines[n-1].reach
+		for _, r := range x.reach[x.lines[n-1].token:] {
+			if r > reach {
+				reach = r
+			}
+		}
+	}
+	for {
+		if lexer.Interpreter.GetCharPositionInLine() == 0 {
+			s := lexer.Snapshot()
+			if old != nil && s.Index >= from {
+				if i := old.lineAt(s.Index - delta); i >= 0 && old.lines[i].snapshot.sameState(s) {
+					n := len(x.tokens)
+					x.move(lexer, old, i, s, reach)
+					return n
+				}
+			}
+			x.lines = append(x.lines, lineState{s, len(x.tokens), reach})
+		}
+		input.reach = input.Index() - 1
+		t := lexer.NextToken()
+		x.tokens = append(x.tokens, t)
+		x.reach = append(x.reach, input.reach)
+		if input.reach > reach {
+			reach = input.reach
+		}
+		if t.GetTokenType() == antlr.TokenEOF {
+			return len(x.tokens)
+		}
+	}
+}
+
+// lineAt returns the index of the line state at the character index,
+// -1 if there is none.
+func (x *IncrementalLexer) lineAt(index int) int {
+	i := sort.Search(len(x.lines), func(i int) bool {
+		return x.lines[i].snapshot.Index >= index
+	})
+	if i < len(x.lines) && x.lines[i].snapshot.Index == index {
+		return i
+	}
+	return -1
+}
+
+// move appends the tokens and line states of old from its line state
+// i, where the lexer is in state s, moved to the position of s. The
+// tokens read their text from the new input. Reach is the one of the
+// tokens before s.
+func (x *IncrementalLexer) move(lexer *JavaScriptLexer, old *IncrementalLexer, i int, s *LexerSnapshot, reach int) {
+	o := old.lines[i].snapshot
+	delta, lines := s.Index-o.Index, s.Line-o.Line
+	offset := len(x.tokens) - old.lines[i].token
+
+	// The last tokens of the moved snapshots are moved tokens or, with
+	// no default channel token since o, the last tokens of o.
+	moved := map[antlr.Token]antlr.Token{
+		o.lastToken:       s.lastToken,
+		o.beforeLastToken: s.beforeLastToken,
+	}
+	source := lexer.GetTokenSourceCharStreamPair()
+	for k, t := range old.tokens[old.lines[i].token:] {
+		m := antlr.CommonTokenFactoryDEFAULT.Create(source, t.GetTokenType(), "", t.GetChannel(),
+			t.GetStart()+delta, t.GetStop()+delta, t.GetLine()+lines, t.GetColumn())
+		moved[t] = m
+		x.tokens = append(x.tokens, m)
+		x.reach = append(x.reach, old.reach[old.lines[i].token+k]+delta)
+	}
+	k := old.lines[i].token
+	for _, l := range old.lines[i:] {
+		for ; k < l.token; k++ {
+			if r := old.reach[k] + delta; r > reach {
+				reach = r
+			}
+		}
+		c := *l.snapshot
+		c.Index += delta
+		c.Line += lines
+		c.lastToken = moved[c.lastToken]
+		c.beforeLastToken = moved[c.beforeLastToken]
+		x.lines = append(x.lines, lineState{&c, l.token + offset, reach})
+	}
+}
@@ -0,0 +1,153 @@
+package parser
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// tokenString returns the tokens with their positions and text.
+func tokenString(tokens []antlr.Token) string {
+	s := ""
+	for _, t := range tokens {
+		s += fmt.Sprintf("%d/%d %d:%d %d:%d %q\n", t.GetTokenType(), t.GetChannel(),
+			t.GetStart(), t.GetStop(), t.GetLine(), t.GetColumn(), t.GetText())
+	}
+	return s
+}
+
+// sameTokens returns true if the tokens of a and b have the same type,
+// channel, position and text.
+func sameTokens(a, b []antlr.Token) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, t := range a {
+		u := b[i]
+		if t.GetTokenType() != u.GetTokenType() || t.GetChannel() != u.GetChannel() ||
+			t.GetStart() != u.GetStart() || t.GetStop() != u.GetStop() ||
+			t.GetLine() != u.GetLine() || t.GetColumn() != u.GetColumn() || t.GetText() != u.GetText() {
+			return false
+		}
+	}
+	return true
+}
+
+// checkEdit applies an edit to x and compares its tokens with the ones
+// of the new text lexed from the start.
+func checkEdit(t *testing.T, name string, x *IncrementalLexer, start, end int, text string) bool {
+	old := x.Tokens()
+	first, last := x.Edit(start, end, text)
+	want := NewIncrementalLexer(x.Text(), false).Tokens()
+	if got := x.Tokens(); !sameTokens(got, want) {
+		t.Errorf("%s: Edit(%d, %d, %q) of\n%s\ngot tokens\n%s\nwant\n%s", name, start, end, text, x.Text(), tokenString(got), tokenString(want))
+		return false
+	}
+	for i := 0; i < first; i++ {
+		if x.Tokens()[i] != old[i] {
+			t.Errorf("%s: Edit(%d, %d, %q) changed the tokens before %d", name, start, end, text, first)
+			return false
+		}
+	}
+	if first > last {
+		t.Errorf("%s: Edit(%d, %d, %q) lexed from %d to %d", name, start, end, text, first, last)
+		return false
+	}
+	return true
+}
+
+func TestIncrementalLexerEdit(t *testing.T) {
+	tests := []struct {
+		src        string
+		start, end int
+		text       string
+	}{
+		{"a /* b\nc\nd", 10, 10, " */"}, // closes a comment opened lines before
+		{"a /* b */\nc\nd", 7, 9, ""},
+		{"x = `a\nb\n${c}\nd`\ne", 2, 3, ";"},
+		{"x = `a\nb\n`\ne", 4, 5, ""},
+		{"f()\n{\n}\n/re/g", 0, 3, "x = 1"},
+		{"f()\n/re/g\n", 0, 3, "a"},
+		{"a\nb\nc\n", 2, 3, "b /* x\ny */"},
+		{"a\nb\nc\n", 0, 6, ""},
+		{"", 0, 0, "a\nb"},
+		{"'use strict'\nfunction f() {\n}\n", 0, 0, "x;\n"},
+	}
+	for _, test := range tests {
+		checkEdit(t, fmt.Sprintf("%q", test.src), NewIncrementalLexer(test.src, false), test.start, test.end, test.text)
+	}
+}
+
+// TestIncrementalLexerRandomEdits edits the examples at random and
+// compares the tokens with the ones of the edited text lexed again. An
+// edit opening a comment or a template string is followed by one
+// closing it further on.
+func TestIncrementalLexerRandomEdits(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join(examplesDir(t), "*.js"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inserts := []string{"", "", "x", " ", "\n", "/*", "*/", "<!--", "//", "/", "`", "${", "}", "{", "'", "\"", "(", "\\"}
+	closers := map[string]string{"/*": "*/", "<!--": "-->", "`": "`"}
+	r := rand.New(rand.NewSource(1))
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		x := NewIncrementalLexer(string(src), false)
+		closer, opened := "", 0
+		for i := 0; i < 10; i++ {
+			n := len([]rune(x.Text()))
+			start := r.Intn(n + 1)
+			end := start + r.Intn(4)
+			if end > n {
+				end = n
+			}
+			text := inserts[r.Intn(len(inserts))]
+			if closer != "" {
+				start = opened + r.Intn(n-opened+1)
+				end, text, closer = start, closer, ""
+			} else if c, ok := closers[text]; ok {
+				closer, opened = c, start+len(text)
+			}
+			if !checkEdit(t, filepath.Base(file), x, start, end, text) {
+				break
+			}
+		}
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	src := "class A {\n  m() { return `a${ {b: /re/} }` / 2 }\n}\nx = y / 2\n"
+	lexer := NewJavaScriptLexer(antlr.NewInputStream(src))
+	var tokens []antlr.Token
+	var snapshots []*LexerSnapshot
+	for {
+		snapshots = append(snapshots, lexer.Snapshot())
+		tok := lexer.NextToken()
+		tokens = append(tokens, tok)
+		if tok.GetTokenType() == antlr.TokenEOF {
+			break
+		}
+	}
+	for i, s := range snapshots {
+		restored := NewJavaScriptLexer(antlr.NewInputStream(src))
+		restored.Restore(s)
+		var rest []antlr.Token
+		for {
+			tok := restored.NextToken()
+			rest = append(rest, tok)
+			if tok.GetTokenType() == antlr.TokenEOF {
+				break
+			}
+		}
+		if !sameTokens(rest, tokens[i:]) {
+			t.Errorf("restored before token %d: got\n%s\nwant\n%s", i, tokenString(rest), tokenString(tokens[i:]))
+		}
+	}
+}
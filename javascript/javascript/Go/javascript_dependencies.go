@@ -0,0 +1,416 @@
+package parser
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// Dependency is a module specifier a file depends on. Kind is
+// "import" for an import declaration, with or without bindings,
+// "export" for export { ... } from, "export-all" for export * from,
+// "dynamic" for import() and "require" for require(), both with a
+// string literal or a template without substitutions as argument.
+// Line and Column are those of the literal, Column counting from 0.
+type Dependency struct {
+	Specifier string `json:"specifier"`
+	Kind      string `json:"kind"`
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+}
+
+// Dependencies returns the dependencies of the tree parsed from file
+// in source order.
+func Dependencies(file string, tree antlr.Tree) []Dependency {
+	l := &dependencyListener{file: file}
+	antlr.ParseTreeWalkerDefault.Walk(l, tree)
+	return l.dependencies
+}
+
+type dependencyListener struct {
+	BaseJavaScriptParserListener
+
+	file         string
+	dependencies []Dependency
+}
+
+func (l *dependencyListener) add(kind, specifier string, literal antlr.Token) {
+	l.dependencies = append(l.dependencies, Dependency{specifier, kind, l.file, literal.GetLine(), literal.GetColumn()})
+}
+
+func (l *dependencyListener) EnterImportStatement(ctx *ImportStatementContext) {
+	block, ok := ctx.ImportFromBlock().(*ImportFromBlockContext)
+	if !ok {
+		return
+	}
+	if s := block.StringLiteral(); s != nil {
+		// import "polyfill";
+		l.add("import", unquote(s.GetText()), s.GetSymbol())
+	} else if from, ok := block.ImportFrom().(*ImportFromContext); ok && from.StringLiteral() != nil {
+		l.add("import", unquote(from.StringLiteral().GetText()), from.StringLiteral().GetSymbol())
+	}
+}
+
+func (l *dependencyListener) EnterExportDeclaration(ctx *ExportDeclarationContext) {
+	block, ok := ctx.ExportFromBlock().(*ExportFromBlockContext)
+	if !ok {
+		return
+	}
+	from, ok := block.ImportFrom().(*ImportFromContext)
+	if !ok || from.StringLiteral() == nil {
+		return // export { a, b };
+	}
+	kind := "export"
+	if ns, ok := block.ImportNamespace().(*ImportNamespaceContext); ok && ns.Multiply() != nil {
+		kind = "export-all"
+	}
+	l.add(kind, unquote(from.StringLiteral().GetText()), from.StringLiteral().GetSymbol())
+}
+
+func (l *dependencyListener) EnterImportExpression(ctx *ImportExpressionContext) {
+	if specifier, literal, ok := stringValue(ctx.SingleExpression()); ok {
+		l.add("dynamic", specifier, literal)
+	}
+}
+
+func (l *dependencyListener) EnterArgumentsExpression(ctx *ArgumentsExpressionContext) {
+	if callee, ok := ctx.SingleExpression().(*IdentifierExpressionContext); !ok || callee.GetText() != "require" {
+		return
+	}
+	args, ok := ctx.Arguments().(*ArgumentsContext)
+	if !ok || len(args.AllArgument()) != 1 {
+		return
+	}
+	arg, ok := args.Argument(0).(*ArgumentContext)
+	if !ok || arg.Ellipsis() != nil {
+		return
+	}
+	if specifier, literal, ok := stringValue(arg.SingleExpression()); ok {
+		l.add("require", specifier, literal)
+	}
+}
+
+// stringValue returns the value of a string literal or of a template
+// without substitutions, and its first token.
+func stringValue(expr ISingleExpressionContext) (string, antlr.Token, bool) {
+	literal, ok := expr.(*LiteralExpressionContext)
+	if !ok {
+		return "", nil, false
+	}
+	lit, ok := literal.Literal().(*LiteralContext)
+	if !ok {
+		return "", nil, false
+	}
+	if s := lit.StringLiteral(); s != nil {
+		return unquote(s.GetText()), s.GetSymbol(), true
+	}
+	template, ok := lit.TemplateStringLiteral().(*TemplateStringLiteralContext)
+	if !ok {
+		return "", nil, false
+	}
+	var b strings.Builder
+	for _, atom := range template.AllTemplateStringAtom() {
+		a, ok := atom.(*TemplateStringAtomContext)
+		if !ok || a.TemplateStringAtom() == nil {
+			return "", nil, false // `./locale/${lang}`
+		}
+		b.WriteString(a.TemplateStringAtom().GetText())
+	}
+	return b.String(), template.GetStart(), true
+}
+
+// unquote returns the value of a string literal. Escape sequences are
+// rare in module specifiers: those Go does not know are left as is.
+func unquote(text string) string {
+	s := text[1 : len(text)-1]
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	s = strings.ReplaceAll(s, `\'`, `'`)
+	if text[0] == '\'' {
+		s = strings.ReplaceAll(strings.ReplaceAll(s, `\"`, `"`), `"`, `\"`)
+	}
+	if value, err := strconv.Unquote(`"` + s + `"`); err == nil {
+		return value
+	}
+	return text[1 : len(text)-1]
+}
+
+// WriteDependenciesJSON writes dependencies as a JSON array.
+func WriteDependenciesJSON(w io.Writer, dependencies []Dependency) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(dependencies)
+}
+
+// ResolvedDependency is a dependency with the file it resolves to,
+// relative to the root of the graph unless it is outside of it. A bare
+// specifier also gives the name of its Package, resolved or not, and
+// a Node.js core module (fs, node:fs) is Builtin.
+type ResolvedDependency struct {
+	Dependency
+	Resolved string `json:"resolved,omitempty"`
+	Package  string `json:"package,omitempty"`
+	Builtin  bool   `json:"builtin,omitempty"`
+}
+
+// ModuleFile is a JavaScript file of a ModuleGraph, with its path
+// relative to the root of the graph.
+type ModuleFile struct {
+	Path         string               `json:"path"`
+	Dependencies []ResolvedDependency `json:"dependencies"`
+	Incomplete   bool                 `json:"incomplete,omitempty"` // the file has syntax errors
+}
+
+// ModuleGraph is the dependency graph of the JavaScript files below
+// Root.
+type ModuleGraph struct {
+	Root  string        `json:"root"`
+	Files []*ModuleFile `json:"files"`
+
+	byPath map[string]*ModuleFile
+}
+
+// moduleExtensions are tried in order on a specifier without the
+// extension of an existing file. Node.js only tries .js, .json and
+// .node, bundlers also the others. .jsx files are left out, as they
+// are not in the graph: the grammar does not parse JSX.
+var moduleExtensions = []string{".js", ".json", ".mjs", ".cjs"}
+
+var builtinModules = map[string]bool{
+	"assert": true, "async_hooks": true, "buffer": true, "child_process": true,
+	"cluster": true, "console": true, "constants": true, "crypto": true,
+	"dgram": true, "diagnostics_channel": true, "dns": true, "domain": true,
+	"events": true, "fs": true, "http": true, "http2": true, "https": true,
+	"inspector": true, "module": true, "net": true, "os": true, "path": true,
+	"perf_hooks": true, "process": true, "punycode": true, "querystring": true,
+	"readline": true, "repl": true, "stream": true, "string_decoder": true,
+	"timers": true, "tls": true, "trace_events": true, "tty": true, "url": true,
+	"util": true, "v8": true, "vm": true, "wasi": true, "worker_threads": true,
+	"zlib": true,
+}
+
+// LoadModuleGraph parses every .js, .mjs and .cjs file below root and
+// resolves its dependencies the way Node.js does, simplified:
+//
+//   - a relative or absolute specifier names a file, possibly without
+//     its extension (see moduleExtensions), or a directory, whose
+//     package.json main field or index file is taken;
+//   - a bare specifier names a package, looked up in the
+//     node_modules directories of the file's directory and of its
+//     parents, then resolved as a directory or file;
+//   - a Node.js core module is builtin.
+//
+// The exports and imports fields of package.json and the browser
+// field are not considered. node_modules directories and directories
+// whose name starts with . are not walked, so the dependencies of the
+// packages are not in the graph.
+func LoadModuleGraph(root string) (*ModuleGraph, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	g := &ModuleGraph{Root: root, byPath: map[string]*ModuleFile{}}
+	err = filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name := info.Name()
+		if info.IsDir() {
+			if file != root && (name == "node_modules" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch filepath.Ext(name) {
+		case ".js", ".mjs", ".cjs":
+		default:
+			return nil
+		}
+		source, err := ParseJavaScriptFile(file)
+		if err != nil {
+			return err
+		}
+		g.addFile(file, source)
+		return nil
+	})
+	return g, err
+}
+
+func (g *ModuleGraph) addFile(file string, source *JavaScriptSource) {
+	m := &ModuleFile{Path: g.relative(file), Incomplete: len(source.Errors) > 0}
+	for _, dep := range Dependencies(m.Path, source.Tree) {
+		resolved := ResolvedDependency{Dependency: dep}
+		switch spec := dep.Specifier; {
+		case strings.HasPrefix(spec, "./") || strings.HasPrefix(spec, "../") || spec == "." || spec == ".." || filepath.IsAbs(spec):
+			if !filepath.IsAbs(spec) {
+				spec = filepath.Join(filepath.Dir(file), spec)
+			}
+			resolved.Resolved = g.relative(resolvePath(spec))
+		case strings.HasPrefix(spec, "node:") || builtinModules[strings.SplitN(spec, "/", 2)[0]]:
+			resolved.Builtin = true
+		default:
+			resolved.Package = packageName(spec)
+			resolved.Resolved = g.relative(resolvePackage(filepath.Dir(file), spec))
+		}
+		m.Dependencies = append(m.Dependencies, resolved)
+	}
+	g.byPath[m.Path] = m
+	g.Files = append(g.Files, m)
+}
+
+// relative returns file relative to the root of the graph with
+// slashes, or as is if it is outside of it.
+func (g *ModuleGraph) relative(file string) string {
+	if file == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(g.Root, file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(file)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// packageName returns the package of a bare specifier: its first path
+// element, or its first two for a scoped package (@scope/name).
+func packageName(spec string) string {
+	parts := strings.SplitN(spec, "/", 3)
+	if strings.HasPrefix(spec, "@") && len(parts) > 1 {
+		return parts[0] + "/" + parts[1]
+	}
+	return parts[0]
+}
+
+func resolvePackage(dir, spec string) string {
+	for {
+		if file := resolvePath(filepath.Join(dir, "node_modules", filepath.FromSlash(spec))); file != "" {
+			return file
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// resolvePath resolves p as a file, then as a directory, "" if neither
+// exists.
+func resolvePath(p string) string {
+	if file := resolveFile(p); file != "" {
+		return file
+	}
+	return resolveDirectory(p)
+}
+
+func resolveFile(p string) string {
+	if isFile(p) {
+		return p
+	}
+	for _, ext := range moduleExtensions {
+		if isFile(p + ext) {
+			return p + ext
+		}
+	}
+	return ""
+}
+
+func resolveDirectory(dir string) string {
+	if data, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil {
+		var pkg struct {
+			Main string `json:"main"`
+		}
+		if json.Unmarshal(data, &pkg) == nil && pkg.Main != "" {
+			main := filepath.Join(dir, filepath.FromSlash(pkg.Main))
+			if file := resolveFile(main); file != "" {
+				return file
+			}
+			if file := resolveFile(filepath.Join(main, "index")); file != "" {
+				return file
+			}
+		}
+	}
+	return resolveFile(filepath.Join(dir, "index"))
+}
+
+func isFile(p string) bool {
+	info, err := os.Stat(p)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// File returns the file of the graph with the given path relative to
+// Root, or nil.
+func (g *ModuleGraph) File(path string) *ModuleFile {
+	return g.byPath[path]
+}
+
+// Reachable returns the files of the graph a bundle with entry point
+// path includes: path and the files its dependencies resolve to,
+// transitively, sorted.
+func (g *ModuleGraph) Reachable(path string) []string {
+	seen := map[string]bool{}
+	var visit func(p string)
+	visit = func(p string) {
+		m := g.byPath[p]
+		if m == nil || seen[p] {
+			return
+		}
+		seen[p] = true
+		for _, dep := range m.Dependencies {
+			visit(dep.Resolved)
+		}
+	}
+	visit(path)
+	return sortedKeys(seen)
+}
+
+// Dependents returns the files of the graph which depend on path,
+// directly or not, sorted: the files a change of path may impact.
+func (g *ModuleGraph) Dependents(path string) []string {
+	importers := map[string][]string{}
+	for _, m := range g.Files {
+		for _, dep := range m.Dependencies {
+			if dep.Resolved != "" {
+				importers[dep.Resolved] = append(importers[dep.Resolved], m.Path)
+			}
+		}
+	}
+	seen := map[string]bool{}
+	var visit func(p string)
+	visit = func(p string) {
+		for _, importer := range importers[p] {
+			if !seen[importer] {
+				seen[importer] = true
+				visit(importer)
+			}
+		}
+	}
+	visit(path)
+	delete(seen, path)
+	return sortedKeys(seen)
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WriteJSON writes the files of the graph with their resolved
+// dependencies.
+func (g *ModuleGraph) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(g)
+}
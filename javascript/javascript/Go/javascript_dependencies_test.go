@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDependencies(t *testing.T) {
+	source := ParseJavaScriptString("deps.js", `import "polyfill";
+import a, { b } from './a';
+export { c } from "./c";
+export * from './d';
+const e = await import(`+"`./e`"+`);
+const f = require('f');
+import(`+"`./locale/${lang}`"+`);
+require(name);
+export { g };
+`)
+	if len(source.Errors) > 0 {
+		t.Fatal(source.Errors)
+	}
+	var got []string
+	for _, d := range Dependencies(source.Name, source.Tree) {
+		got = append(got, fmt.Sprintf("%d:%d %s %s", d.Line, d.Column, d.Kind, d.Specifier))
+	}
+	want := []string{
+		"1:7 import polyfill",
+		"2:21 import ./a",
+		"3:18 export ./c",
+		"4:14 export-all ./d",
+		"5:23 dynamic ./e",
+		"6:18 require f",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got dependencies\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// writeFiles writes files, path and content, below dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for path, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadModuleGraph(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"src/main.js": `import a from './a';
+import './lib';
+import u from './util';
+import c from './comp';
+import fs from 'node:fs';
+const path = require('path');
+import pad from 'left-pad';
+import pkg from '@scope/pkg';
+import sub from '@scope/pkg/sub';
+import missing from 'missing/deep';
+`,
+		"src/a.js":                              "export * from './b.cjs';\n",
+		"src/b.cjs":                             "module.exports = 1;\n",
+		"src/comp.jsx":                          "export default () => <div/>;\n",
+		"src/lib/package.json":                  `{"main": "entry"}`,
+		"src/lib/entry.js":                      "require('../a');\n",
+		"src/util/index.js":                     "",
+		"node_modules/left-pad/index.js":        "require('./missing');\n",
+		"node_modules/@scope/pkg/package.json":  `{"main": "dist"}`,
+		"node_modules/@scope/pkg/dist/index.js": "",
+		"node_modules/@scope/pkg/sub.js":        "",
+		".cache/x.js":                           "import './y';\n",
+	})
+	g, err := LoadModuleGraph(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var files []string
+	for _, m := range g.Files {
+		files = append(files, m.Path)
+	}
+	if got := strings.Join(files, " "); got != "src/a.js src/b.cjs src/lib/entry.js src/main.js src/util/index.js" {
+		t.Errorf("got files %s", got)
+	}
+
+	var got []string
+	for _, d := range g.File("src/main.js").Dependencies {
+		got = append(got, fmt.Sprintf("%s -> %q package %q builtin %v", d.Specifier, d.Resolved, d.Package, d.Builtin))
+	}
+	// The .jsx file is not resolved, the bare specifiers are looked up
+	// in the node_modules directory of the parent directory.
+	want := []string{
+		`./a -> "src/a.js" package "" builtin false`,
+		`./lib -> "src/lib/entry.js" package "" builtin false`,
+		`./util -> "src/util/index.js" package "" builtin false`,
+		`./comp -> "" package "" builtin false`,
+		`node:fs -> "" package "" builtin true`,
+		`path -> "" package "" builtin true`,
+		`left-pad -> "node_modules/left-pad/index.js" package "left-pad" builtin false`,
+		`@scope/pkg -> "node_modules/@scope/pkg/dist/index.js" package "@scope/pkg" builtin false`,
+		`@scope/pkg/sub -> "node_modules/@scope/pkg/sub.js" package "@scope/pkg" builtin false`,
+		`missing/deep -> "" package "missing" builtin false`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got dependencies\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if got := strings.Join(g.Reachable("src/main.js"), " "); got != "src/a.js src/b.cjs src/lib/entry.js src/main.js src/util/index.js" {
+		t.Errorf("Reachable(src/main.js) = %s", got)
+	}
+	if got := strings.Join(g.Reachable("src/a.js"), " "); got != "src/a.js src/b.cjs" {
+		t.Errorf("Reachable(src/a.js) = %s", got)
+	}
+	if got := strings.Join(g.Dependents("src/b.cjs"), " "); got != "src/a.js src/lib/entry.js src/main.js" {
+		t.Errorf("Dependents(src/b.cjs) = %s", got)
+	}
+	if got := g.Dependents("src/main.js"); len(got) != 0 {
+		t.Errorf("Dependents(src/main.js) = %v, want none", got)
+	}
+}
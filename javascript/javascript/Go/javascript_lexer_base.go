@@ -6,35 +6,67 @@ import "github.com/antlr/antlr4/runtime/Go/antlr"
 type JavaScriptLexerBase struct {
 	*antlr.BaseLexer
 
-	scopeStrictModes []bool
-	stackLength      int
-	stackIx          int
-
 	lastToken        antlr.Token
 	useStrictDefault bool
 	useStrictCurrent bool
-	templateDepth    int
-}
 
-func (l *JavaScriptLexerBase) IsStartOfFile() bool {
-	return l.lastToken == nil
+	// The open brackets, braces and template strings, innermost
+	// last, the token before the last one and whether a regex
+	// literal can follow the last token, for IsRegexPossible.
+	contexts        []lexerContext
+	closedBrace     lexerContext
+	closedParen     lexerContext
+	beforeLastToken antlr.Token
+	regexPossible   bool
+
+	// The depth of the contexts plus one where a class keyword or the
+	// function keyword of a function expression waits for its body, 0
+	// if none, and the state of the directive prologue of the program or
+	// function body being lexed, see ProcessStringLiteral.
+	pendingClass          int
+	pendingFunction       int
+	prologue              int
+	strictBeforeDirective bool
+
+	// The lexer mode and the mode stack, which BaseLexer keeps to
+	// itself, followed by SetMode, PushMode and PopMode for Snapshot.
+	mode      int
+	modeStack []int
 }
 
-func (l *JavaScriptLexerBase) pushStrictModeScope(v bool) {
-	if l.stackIx == l.stackLength {
-		l.scopeStrictModes = append(l.scopeStrictModes, v)
-		l.stackLength++
-	} else {
-		l.scopeStrictModes[l.stackIx] = v
-	}
-	l.stackIx++
+// lexerContext records why a bracket or a template string was opened.
+// Strict is the strict mode of the code around it, restored when a
+// brace closes.
+type lexerContext struct {
+	kind   int
+	strict bool
 }
 
-func (l *JavaScriptLexerBase) popStrictModeScope() bool {
-	l.stackIx--
-	v := l.scopeStrictModes[l.stackIx]
-	l.scopeStrictModes[l.stackIx] = false
-	return v
+// Kinds of lexerContext, which tell whether the closing bracket ends
+// an expression and whether a } ends a template substitution.
+const (
+	parenContext              = iota // ( of an expression or parameter list
+	headerContext                    // ( of an if, while, for, with, switch or catch header
+	bracketContext                   // [
+	blockContext                     // { of a block
+	functionContext                  // { of a function declaration, method or arrow function body
+	functionExpressionContext        // { of a function expression body
+	classContext                     // { of a class body
+	objectContext                    // { of an object literal or pattern
+	templateContext                  // ` of a template string
+	substitutionContext              // ${ of a template string
+)
+
+// States of the directive prologue: at its start or after a ;, after a
+// string literal which may be a directive, and past the prologue.
+const (
+	prologueStart = iota
+	prologueDirective
+	prologueNone
+)
+
+func (l *JavaScriptLexerBase) IsStartOfFile() bool {
+	return l.lastToken == nil
 }
 
 // IsStrictMode is self explanatory.
@@ -42,75 +74,467 @@ func (l *JavaScriptLexerBase) IsStrictMode() bool {
 	return l.useStrictCurrent
 }
 
+// GetStrictDefault returns the strict mode of the code outside of
+// any class body or function with a "use strict" directive.
+func (l *JavaScriptLexerBase) GetStrictDefault() bool {
+	return l.useStrictDefault
+}
+
+// SetUseStrictDefault sets the strict mode by default, true for an
+// ES module. Call it before lexing.
+func (l *JavaScriptLexerBase) SetUseStrictDefault(value bool) {
+	l.useStrictDefault = value
+	l.useStrictCurrent = value
+}
+
 // NextToken from the character stream.
 func (l *JavaScriptLexerBase) NextToken() antlr.Token {
 	next := l.BaseLexer.NextToken() // Get next token
 	if next.GetChannel() == antlr.TokenDefaultChannel {
-		// Keep track of the last token on default channel
+		// Keep track of the last tokens on default channel
+		l.regexPossible = l.regexCanFollow(next)
+		l.updatePrologue(next)
+		l.beforeLastToken = l.lastToken
 		l.lastToken = next
 	}
 	return next
 }
 
 // ProcessOpenBrace is called when a { is encountered during
-// lexing, we push a new scope everytime.
+// lexing, we push a block, a body or an object literal, which keeps
+// the strict mode of the code around it. A class body is strict.
 func (l *JavaScriptLexerBase) ProcessOpenBrace() {
-	l.useStrictCurrent = l.useStrictDefault
-	if l.stackIx > 0 && l.scopeStrictModes[l.stackIx-1] {
+	kind := l.braceContext()
+	if kind == functionContext && l.pendingFunction == len(l.contexts)+1 {
+		l.pendingFunction = 0
+		if l.lastTokenIs(JavaScriptLexerCloseParen) {
+			kind = functionExpressionContext // not the body of async x => {
+		}
+	}
+	l.pushContext(kind)
+	if kind == classContext {
 		l.useStrictCurrent = true
 	}
-	l.pushStrictModeScope(l.useStrictCurrent)
 }
 
 // ProcessCloseBrace is called when a } is encountered during
-// lexing, we pop a scope unless we're inside global scope.
+// lexing, we pop its brace and restore the strict mode.
 func (l *JavaScriptLexerBase) ProcessCloseBrace() {
-	l.useStrictCurrent = l.useStrictDefault
-	if l.stackIx > 0 {
-		l.useStrictCurrent = l.popStrictModeScope()
-	}
+	l.closedBrace = l.popContext()
+	l.useStrictCurrent = l.closedBrace.strict
 }
 
-// ProcessStringLiteral is called when lexing a string literal.
+// ProcessStringLiteral is called when lexing a string literal. A
+// "use strict" directive in the prologue of the program or of a
+// function body makes the code strict, until updatePrologue finds
+// the string is part of an expression.
 func (l *JavaScriptLexerBase) ProcessStringLiteral() {
-	if l.lastToken == nil || l.lastToken.GetTokenType() == JavaScriptLexerOpenBrace {
-		if l.GetText() == `"use strict"` || l.GetText() == "'use strict'" {
-			if l.stackIx > 0 {
-				l.popStrictModeScope()
+	switch {
+	case l.prologue == prologueStart:
+	case l.prologue == prologueDirective && l.lastToken.GetLine() < l.GetLine():
+		// "use strict" on a new line after a directive without ;
+	default:
+		return
+	}
+	l.strictBeforeDirective = l.useStrictCurrent
+	if l.GetText() == `"use strict"` || l.GetText() == "'use strict'" {
+		l.useStrictCurrent = true
+	}
+}
+
+// updatePrologue follows the directive prologue with t, the new last
+// token. A string literal is a directive if a ; or a line break ends
+// its statement, as in "use strict"; but not "use strict".length.
+func (l *JavaScriptLexerBase) updatePrologue(t antlr.Token) {
+	switch l.prologue {
+	case prologueStart:
+		if t.GetTokenType() == JavaScriptLexerStringLiteral {
+			l.prologue = prologueDirective
+		} else if t.GetTokenType() != JavaScriptLexerHashBangLine {
+			l.prologue = prologueNone
+		}
+	case prologueDirective:
+		switch {
+		case t.GetTokenType() == JavaScriptLexerSemiColon:
+			l.prologue = prologueStart
+		case t.GetLine() > l.lastToken.GetLine():
+			if t.GetTokenType() != JavaScriptLexerStringLiteral {
+				l.prologue = prologueNone
 			}
-			l.useStrictCurrent = true
-			l.pushStrictModeScope(l.useStrictCurrent)
+		case t.GetTokenType() == JavaScriptLexerCloseBrace, t.GetTokenType() == antlr.TokenEOF:
+			l.prologue = prologueNone
+		default:
+			l.useStrictCurrent = l.strictBeforeDirective
+			l.prologue = prologueNone
 		}
 	}
+	if t.GetTokenType() == JavaScriptLexerOpenBrace && isFunctionBody(l.contexts[len(l.contexts)-1].kind) {
+		// Pushed by ProcessOpenBrace
+		l.prologue = prologueStart
+	}
 }
 
 // IsRegexPossible returns true if the lexer can match a
-// regex literal.
+// regex literal, that is if the syntactic grammar expects an
+// expression after the last token.
 func (l *JavaScriptLexerBase) IsRegexPossible() bool {
-	if l.lastToken == nil {
+	return l.lastToken == nil || l.regexPossible
+}
+
+// regexCanFollow returns true if a regex literal can follow t, the
+// new last token, and keeps the stack of contexts up to date for the
+// tokens without a lexer action.
+func (l *JavaScriptLexerBase) regexCanFollow(t antlr.Token) bool {
+	switch t.GetTokenType() {
+	case JavaScriptLexerOpenParen:
+		if l.lastTokenIs(JavaScriptLexerClass) {
+			l.pendingClass = 0 // a method named class
+		}
+		if l.isHeaderKeyword() {
+			l.pushContext(headerContext)
+		} else {
+			l.pushContext(parenContext)
+		}
+		return true
+	case JavaScriptLexerOpenBracket:
+		l.pushContext(bracketContext)
+		return true
+	case JavaScriptLexerTemplateStringStartExpression:
+		l.pushContext(substitutionContext)
+		return true
+	case JavaScriptLexerCloseParen:
+		// if (x) /re/.test(s) but (x) / 2
+		l.closedParen = l.popContext()
+		return l.closedParen.kind == headerContext
+	case JavaScriptLexerCloseBrace:
+		// {} /re/.test(s) but ({}) / 2 and x = function () {} / 2,
+		// popped by ProcessCloseBrace
+		return l.closedBrace.kind != objectContext && l.closedBrace.kind != functionExpressionContext
+	case JavaScriptLexerCloseBracket:
+		l.popContext()
+		return false
+	case JavaScriptLexerTemplateCloseBrace:
+		l.popContext()
+		return true
+	case JavaScriptLexerColon:
+		if l.lastTokenIs(JavaScriptLexerClass) {
+			l.pendingClass = 0 // a property named class
+		}
+		return true
+	case JavaScriptLexerClass:
+		if l.lastTokenIs(JavaScriptLexerDot) {
+			return false
+		}
+		l.pendingClass = len(l.contexts) + 1
+		return true
+	case JavaScriptLexerAsync:
+		// x = async function () {} / 2
+		l.pendingFunction = 0
+		if l.expressionExpected() {
+			l.pendingFunction = len(l.contexts) + 1
+		}
+		return false
+	case JavaScriptLexerFunction_:
+		if l.lastTokenIs(JavaScriptLexerDot) {
+			return false
+		}
+		if !l.lastTokenIs(JavaScriptLexerAsync) {
+			l.pendingFunction = 0
+			if l.expressionExpected() {
+				l.pendingFunction = len(l.contexts) + 1
+			}
+		}
 		return true
-	}
-	switch l.lastToken.GetTokenType() {
 	case JavaScriptLexerIdentifier, JavaScriptLexerNullLiteral,
 		JavaScriptLexerBooleanLiteral, JavaScriptLexerThis,
-		JavaScriptLexerCloseBracket, JavaScriptLexerCloseParen,
+		JavaScriptLexerSuper, JavaScriptLexerAs, JavaScriptLexerFrom,
+		JavaScriptLexerNonStrictLet,
 		JavaScriptLexerOctalIntegerLiteral, JavaScriptLexerDecimalLiteral,
-		JavaScriptLexerHexIntegerLiteral, JavaScriptLexerStringLiteral,
+		JavaScriptLexerHexIntegerLiteral, JavaScriptLexerOctalIntegerLiteral2,
+		JavaScriptLexerBinaryIntegerLiteral, JavaScriptLexerBigHexIntegerLiteral,
+		JavaScriptLexerBigOctalIntegerLiteral, JavaScriptLexerBigBinaryIntegerLiteral,
+		JavaScriptLexerBigDecimalIntegerLiteral, JavaScriptLexerStringLiteral,
+		JavaScriptLexerRegularExpressionLiteral, JavaScriptLexerBackTick,
 		JavaScriptLexerPlusPlus, JavaScriptLexerMinusMinus:
 		return false
-	default:
+	}
+	if t.GetTokenType() >= JavaScriptLexerBreak && t.GetTokenType() <= JavaScriptLexerYield {
+		// return /re/, typeof /x/, case /y/: but a.return / 2
+		return !l.lastTokenIs(JavaScriptLexerDot)
+	}
+	return true
+}
+
+// isHeaderKeyword returns true if the last token starts a statement
+// with a parenthesised header: if, while, for, for await, with, switch
+// or a catch clause.
+func (l *JavaScriptLexerBase) isHeaderKeyword() bool {
+	if l.beforeLastToken != nil && l.beforeLastToken.GetTokenType() == JavaScriptLexerDot {
+		return false
+	}
+	switch {
+	case l.lastTokenIs(JavaScriptLexerIf), l.lastTokenIs(JavaScriptLexerWhile),
+		l.lastTokenIs(JavaScriptLexerFor), l.lastTokenIs(JavaScriptLexerWith),
+		l.lastTokenIs(JavaScriptLexerSwitch), l.lastTokenIs(JavaScriptLexerCatch):
+		return true
+	case l.lastTokenIs(JavaScriptLexerAwait):
+		return l.beforeLastToken != nil && l.beforeLastToken.GetTokenType() == JavaScriptLexerFor
+	}
+	return false
+}
+
+// braceContext returns the context of a { following the last token: a
+// block where a statement can start, a function body after parameters,
+// the body of a pending class, an object literal where only an
+// expression can.
+func (l *JavaScriptLexerBase) braceContext() int {
+	if l.pendingClass == len(l.contexts)+1 {
+		l.pendingClass = 0
+		return classContext
+	}
+	if l.lastToken == nil {
+		return blockContext
+	}
+	switch l.lastToken.GetTokenType() {
+	case JavaScriptLexerCloseParen:
+		// if (x) { but function (x) {
+		if l.closedParen.kind == headerContext {
+			return blockContext
+		}
+		return functionContext
+	case JavaScriptLexerARROW:
+		return functionContext
+	}
+	if l.expressionExpected() {
+		return objectContext
+	}
+	return blockContext
+}
+
+// expressionExpected returns true if an expression, but not a
+// statement, can start after the last token.
+func (l *JavaScriptLexerBase) expressionExpected() bool {
+	if l.lastToken == nil {
+		return false
+	}
+	switch l.lastToken.GetTokenType() {
+	case JavaScriptLexerCloseParen:
+		// if (x) statement, or f()\nstatement
+		return false
+	case JavaScriptLexerARROW:
 		return true
+	case JavaScriptLexerSemiColon, JavaScriptLexerOpenBrace, JavaScriptLexerCloseBrace,
+		JavaScriptLexerElse, JavaScriptLexerDo, JavaScriptLexerTry,
+		JavaScriptLexerCatch, JavaScriptLexerFinally, JavaScriptLexerStatic:
+		return false
+	case JavaScriptLexerColon:
+		// A label or case in a block, a property value or the
+		// alternative of a conditional in an expression
+		n := len(l.contexts)
+		return n > 0 && !isBlockLike(l.contexts[n-1].kind)
 	}
+	return l.regexPossible
+}
+
+// isBlockLike returns true if statements can start in a context.
+func isBlockLike(kind int) bool {
+	return kind == blockContext || isFunctionBody(kind) || kind == classContext
 }
 
+// isFunctionBody returns true if a context is the body of a function.
+func isFunctionBody(kind int) bool {
+	return kind == functionContext || kind == functionExpressionContext
+}
+
+func (l *JavaScriptLexerBase) pushContext(kind int) {
+	l.contexts = append(l.contexts, lexerContext{kind, l.useStrictCurrent})
+}
+
+// popContext pops the innermost context. Unbalanced closing brackets
+// are taken to close a block of the strict mode by default.
+func (l *JavaScriptLexerBase) popContext() lexerContext {
+	n := len(l.contexts)
+	if n == 0 {
+		return lexerContext{blockContext, l.useStrictDefault}
+	}
+	c := l.contexts[n-1]
+	l.contexts = l.contexts[:n-1]
+	if l.pendingClass > n {
+		l.pendingClass = 0 // class keyword of a closed context
+	}
+	if l.pendingFunction > n {
+		l.pendingFunction = 0
+	}
+	return c
+}
+
+func (l *JavaScriptLexerBase) lastTokenIs(tokenType int) bool {
+	return l.lastToken != nil && l.lastToken.GetTokenType() == tokenType
+}
+
+// IncreaseTemplateDepth is called on the backtick opening a template
+// string.
 func (l *JavaScriptLexerBase) IncreaseTemplateDepth() {
-	l.templateDepth++
+	l.pushContext(templateContext)
 }
 
+// DecreaseTemplateDepth is called on the backtick closing a template
+// string.
 func (l *JavaScriptLexerBase) DecreaseTemplateDepth() {
-	l.templateDepth--
+	l.popContext()
 }
 
+// IsInTemplateString returns true if a } closes a substitution of a
+// template string, rather than a brace opened inside it as in
+// `a${ {b: 1}.b }c`.
 func (l *JavaScriptLexerBase) IsInTemplateString() bool {
-	return l.templateDepth > 0
+	n := len(l.contexts)
+	return n > 0 && l.contexts[n-1].kind == substitutionContext
+}
+
+// SetMode sets the lexer mode.
+func (l *JavaScriptLexerBase) SetMode(m int) {
+	l.BaseLexer.SetMode(m)
+	l.mode = m
+}
+
+// PushMode pushes the lexer mode and enters mode m, on the backtick
+// opening a template string.
+func (l *JavaScriptLexerBase) PushMode(m int) {
+	l.BaseLexer.PushMode(m)
+	l.modeStack = append(l.modeStack, l.mode)
+	l.mode = m
+}
+
+// PopMode returns to the pushed lexer mode.
+func (l *JavaScriptLexerBase) PopMode() int {
+	m := l.BaseLexer.PopMode()
+	l.modeStack = l.modeStack[:len(l.modeStack)-1]
+	l.mode = m
+	return m
+}
+
+// LexerSnapshot is the state of a JavaScriptLexerBase between two
+// tokens: the position in the input, the lexer mode and mode stack,
+// the context stack, the last tokens and the strict mode.
+type LexerSnapshot struct {
+	Index  int // of the next character in the input
+	Line   int
+	Column int
+
+	mode      int
+	modeStack []int
+
+	lastToken             antlr.Token
+	beforeLastToken       antlr.Token
+	useStrictDefault      bool
+	useStrictCurrent      bool
+	contexts              []lexerContext
+	closedBrace           lexerContext
+	closedParen           lexerContext
+	regexPossible         bool
+	pendingClass          int
+	pendingFunction       int
+	prologue              int
+	strictBeforeDirective bool
+}
+
+// Snapshot returns the state of the lexer, which Restore brings back,
+// so that lexing can start again from the current position.
+func (l *JavaScriptLexerBase) Snapshot() *LexerSnapshot {
+	return &LexerSnapshot{
+		Index:  l.GetInputStream().Index(),
+		Line:   l.Interpreter.GetLine(),
+		Column: l.Interpreter.GetCharPositionInLine(),
+
+		mode:      l.mode,
+		modeStack: append([]int(nil), l.modeStack...),
+
+		lastToken:             l.lastToken,
+		beforeLastToken:       l.beforeLastToken,
+		useStrictDefault:      l.useStrictDefault,
+		useStrictCurrent:      l.useStrictCurrent,
+		contexts:              append([]lexerContext(nil), l.contexts...),
+		closedBrace:           l.closedBrace,
+		closedParen:           l.closedParen,
+		regexPossible:         l.regexPossible,
+		pendingClass:          l.pendingClass,
+		pendingFunction:       l.pendingFunction,
+		prologue:              l.prologue,
+		strictBeforeDirective: l.strictBeforeDirective,
+	}
+}
+
+// Restore brings back the state of a snapshot, taken by this lexer or
+// by another one on an input with the same text before s.Index, and
+// seeks the input to s.Index.
+func (l *JavaScriptLexerBase) Restore(s *LexerSnapshot) {
+	l.GetInputStream().Seek(s.Index)
+	if sim, ok := l.Interpreter.(*antlr.LexerATNSimulator); ok {
+		sim.Line = s.Line
+		sim.CharPositionInLine = s.Column
+	}
+
+	for len(l.modeStack) > 0 {
+		l.PopMode()
+	}
+	if len(s.modeStack) == 0 {
+		l.SetMode(s.mode)
+	} else {
+		l.SetMode(s.modeStack[0])
+		for _, m := range s.modeStack[1:] {
+			l.PushMode(m)
+		}
+		l.PushMode(s.mode)
+	}
+
+	l.lastToken = s.lastToken
+	l.beforeLastToken = s.beforeLastToken
+	l.useStrictDefault = s.useStrictDefault
+	l.useStrictCurrent = s.useStrictCurrent
+	l.contexts = append([]lexerContext(nil), s.contexts...)
+	l.closedBrace = s.closedBrace
+	l.closedParen = s.closedParen
+	l.regexPossible = s.regexPossible
+	l.pendingClass = s.pendingClass
+	l.pendingFunction = s.pendingFunction
+	l.prologue = s.prologue
+	l.strictBeforeDirective = s.strictBeforeDirective
+}
+
+// sameState returns true if the lexer matches the same tokens from the
+// states of s and o on the same text, wherever they are in the input.
+func (s *LexerSnapshot) sameState(o *LexerSnapshot) bool {
+	if s.mode != o.mode || len(s.modeStack) != len(o.modeStack) || len(s.contexts) != len(o.contexts) {
+		return false
+	}
+	for i := range s.modeStack {
+		if s.modeStack[i] != o.modeStack[i] {
+			return false
+		}
+	}
+	for i := range s.contexts {
+		if s.contexts[i] != o.contexts[i] {
+			return false
+		}
+	}
+	return tokenType(s.lastToken) == tokenType(o.lastToken) &&
+		tokenType(s.beforeLastToken) == tokenType(o.beforeLastToken) &&
+		s.useStrictDefault == o.useStrictDefault &&
+		s.useStrictCurrent == o.useStrictCurrent &&
+		s.closedBrace == o.closedBrace &&
+		s.closedParen == o.closedParen &&
+		s.regexPossible == o.regexPossible &&
+		s.pendingClass == o.pendingClass &&
+		s.pendingFunction == o.pendingFunction &&
+		s.prologue == o.prologue &&
+		s.strictBeforeDirective == o.strictBeforeDirective
+}
+
+// tokenType returns the type of t, antlr.TokenInvalidType if t is nil.
+func tokenType(t antlr.Token) int {
+	if t == nil {
+		return antlr.TokenInvalidType
+	}
+	return t.GetTokenType()
 }
@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// lexTypes returns the symbolic names of the default channel tokens of
+// src, without EOF.
+func lexTypes(src string) string {
+	lexer := NewJavaScriptLexer(antlr.NewInputStream(src))
+	lexer.RemoveErrorListeners()
+	var names []string
+	for t := lexer.NextToken(); t.GetTokenType() != antlr.TokenEOF; t = lexer.NextToken() {
+		if t.GetChannel() == antlr.TokenDefaultChannel {
+			names = append(names, lexer.SymbolicNames[t.GetTokenType()])
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+func TestRegexOrDivide(t *testing.T) {
+	tests := []struct{ src, want string }{
+		{"a / b / c", "Identifier Divide Identifier Divide Identifier"},
+		{"x = /re/g", "Identifier Assign RegularExpressionLiteral"},
+		{"/re/.test(s)", "RegularExpressionLiteral Dot Identifier OpenParen Identifier CloseParen"},
+		{"a[0] / 2 / c", "Identifier OpenBracket DecimalLiteral CloseBracket Divide DecimalLiteral Divide Identifier"},
+		{"a++ / 2 / c", "Identifier PlusPlus Divide DecimalLiteral Divide Identifier"},
+		{"(a) / 2 / c", "OpenParen Identifier CloseParen Divide DecimalLiteral Divide Identifier"},
+		{"if (x) /re/.test(s)", "If OpenParen Identifier CloseParen RegularExpressionLiteral Dot Identifier OpenParen Identifier CloseParen"},
+		{"return /re/", "Return RegularExpressionLiteral"},
+		{"a.return / 2 / c", "Identifier Dot Return Divide DecimalLiteral Divide Identifier"},
+		{"{} /re/", "OpenBrace CloseBrace RegularExpressionLiteral"},
+		{"({}) / 2 / c", "OpenParen OpenBrace CloseBrace CloseParen Divide DecimalLiteral Divide Identifier"},
+		{"x = {} / 2 / c", "Identifier Assign OpenBrace CloseBrace Divide DecimalLiteral Divide Identifier"},
+		{"function f() {} /re/", "Function_ Identifier OpenParen CloseParen OpenBrace CloseBrace RegularExpressionLiteral"},
+		{"x = function () {} / 2 / c", "Identifier Assign Function_ OpenParen CloseParen OpenBrace CloseBrace Divide DecimalLiteral Divide Identifier"},
+		{"x = function f(a) { if (a) {} } / 2 / c", "Identifier Assign Function_ Identifier OpenParen Identifier CloseParen OpenBrace If OpenParen Identifier CloseParen OpenBrace CloseBrace CloseBrace Divide DecimalLiteral Divide Identifier"},
+		{"x = async function () {} / 2 / c", "Identifier Assign Async Function_ OpenParen CloseParen OpenBrace CloseBrace Divide DecimalLiteral Divide Identifier"},
+		{"async function f() {} /re/", "Async Function_ Identifier OpenParen CloseParen OpenBrace CloseBrace RegularExpressionLiteral"},
+		{"x = async () => {}\n/re/", "Identifier Assign Async OpenParen CloseParen ARROW OpenBrace CloseBrace RegularExpressionLiteral"},
+		{"f(function () {} / 2, c / d)", "Identifier OpenParen Function_ OpenParen CloseParen OpenBrace CloseBrace Divide DecimalLiteral Comma Identifier Divide Identifier CloseParen"},
+		{"`a` / 2 / c", "BackTick TemplateStringAtom BackTick Divide DecimalLiteral Divide Identifier"},
+		{"`${a}` / 2 / c", "BackTick TemplateStringStartExpression Identifier TemplateCloseBrace BackTick Divide DecimalLiteral Divide Identifier"},
+	}
+	for _, test := range tests {
+		if got := lexTypes(test.src); got != test.want {
+			t.Errorf("%q: got %s, want %s", test.src, got, test.want)
+		}
+	}
+}
+
+func TestTemplateCloseBrace(t *testing.T) {
+	tests := []struct{ src, want string }{
+		{"`a${b}c`", "BackTick TemplateStringAtom TemplateStringStartExpression Identifier TemplateCloseBrace TemplateStringAtom BackTick"},
+		{"`${ {b: 1}.b }`", "BackTick TemplateStringStartExpression OpenBrace Identifier Colon DecimalLiteral CloseBrace Dot Identifier TemplateCloseBrace BackTick"},
+		{"`${ function () {} }`", "BackTick TemplateStringStartExpression Function_ OpenParen CloseParen OpenBrace CloseBrace TemplateCloseBrace BackTick"},
+		{"`${ `${a}` }`", "BackTick TemplateStringStartExpression BackTick TemplateStringStartExpression Identifier TemplateCloseBrace BackTick TemplateCloseBrace BackTick"},
+		{"{ `${a}` }", "OpenBrace BackTick TemplateStringStartExpression Identifier TemplateCloseBrace BackTick CloseBrace"},
+		{"`${a}${b}`", "BackTick TemplateStringStartExpression Identifier TemplateCloseBrace TemplateStringStartExpression Identifier TemplateCloseBrace BackTick"},
+	}
+	for _, test := range tests {
+		if got := lexTypes(test.src); got != test.want {
+			t.Errorf("%q: got %s, want %s", test.src, got, test.want)
+		}
+	}
+}
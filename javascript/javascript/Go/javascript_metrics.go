@@ -0,0 +1,278 @@
+package parser
+
+import (
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// JavaScriptMetricsListener computes the FunctionMetrics of the
+// function declarations, function expressions, arrow functions and
+// methods of a JavaScript file. Cyclomatic complexity counts if
+// statements, loops, case clauses, catch clauses, conditional
+// expressions and the &&, || and ?? operators. Cognitive complexity
+// adds if statements, loops, switch statements, catch clauses and
+// conditional expressions weighted by their nesting, else branches,
+// sequences of like logical operators and jumps to labels.
+type JavaScriptMetricsListener struct {
+	BaseJavaScriptParserListener
+	*MetricsCounter
+}
+
+// NewJavaScriptMetricsListener returns a listener for a tree parsed
+// from the tokens of file.
+func NewJavaScriptMetricsListener(file string, tokens *antlr.CommonTokenStream) *JavaScriptMetricsListener {
+	return &JavaScriptMetricsListener{MetricsCounter: NewMetricsCounter(file, tokens)}
+}
+
+func formalParameters(ctx IFormalParameterListContext) int {
+	list, ok := ctx.(*FormalParameterListContext)
+	if !ok {
+		return 0
+	}
+	n := len(list.AllFormalParameterArg())
+	if list.LastFormalParameterArg() != nil {
+		n++
+	}
+	return n
+}
+
+// functionName returns the name a function expression is bound to by
+// a variable declaration, an assignment or an object property, or a
+// generated name.
+func (l *JavaScriptMetricsListener) functionName(ctx antlr.ParserRuleContext) string {
+	expr, ok := ctx.GetParent().(*FunctionExpressionContext)
+	if !ok {
+		return l.AnonymousName()
+	}
+	switch p := expr.GetParent().(type) {
+	case *VariableDeclarationContext:
+		if p.Assignable() != nil {
+			return p.Assignable().GetText()
+		}
+	case *AssignmentExpressionContext:
+		if p.SingleExpression(0) != nil {
+			return p.SingleExpression(0).GetText()
+		}
+	case *PropertyExpressionAssignmentContext:
+		if p.PropertyName() != nil {
+			return p.PropertyName().GetText()
+		}
+	}
+	return l.AnonymousName()
+}
+
+// propertyName returns the text of the name of a method or accessor
+// after prefix, or a generated name if error recovery left it out.
+func (l *JavaScriptMetricsListener) propertyName(prefix string, name IPropertyNameContext) string {
+	if name == nil || name.GetText() == "" {
+		return l.AnonymousName()
+	}
+	return prefix + name.GetText()
+}
+
+func (l *JavaScriptMetricsListener) getterName(ctx IGetterContext) string {
+	if getter, ok := ctx.(*GetterContext); ok {
+		return l.propertyName("get ", getter.PropertyName())
+	}
+	return l.AnonymousName()
+}
+
+func (l *JavaScriptMetricsListener) setterName(ctx ISetterContext) string {
+	if setter, ok := ctx.(*SetterContext); ok {
+		return l.propertyName("set ", setter.PropertyName())
+	}
+	return l.AnonymousName()
+}
+
+func (l *JavaScriptMetricsListener) EnterFunctionDeclaration(ctx *FunctionDeclarationContext) {
+	var name string
+	if ctx.Identifier() != nil {
+		name = ctx.Identifier().GetText()
+	} else {
+		name = l.AnonymousName() // left out by error recovery
+	}
+	l.BeginFunction(name, formalParameters(ctx.FormalParameterList()), ctx)
+}
+
+func (l *JavaScriptMetricsListener) ExitFunctionDeclaration(ctx *FunctionDeclarationContext) {
+	l.EndFunction()
+}
+
+func (l *JavaScriptMetricsListener) EnterAnonymousFunctionDecl(ctx *AnonymousFunctionDeclContext) {
+	l.BeginFunction(l.functionName(ctx), formalParameters(ctx.FormalParameterList()), ctx)
+}
+
+func (l *JavaScriptMetricsListener) ExitAnonymousFunctionDecl(ctx *AnonymousFunctionDeclContext) {
+	l.EndFunction()
+}
+
+func (l *JavaScriptMetricsListener) EnterArrowFunction(ctx *ArrowFunctionContext) {
+	parameters := 0
+	if params, ok := ctx.ArrowFunctionParameters().(*ArrowFunctionParametersContext); ok {
+		if params.Identifier() != nil {
+			parameters = 1
+		} else {
+			parameters = formalParameters(params.FormalParameterList())
+		}
+	}
+	l.BeginFunction(l.functionName(ctx), parameters, ctx)
+}
+
+func (l *JavaScriptMetricsListener) ExitArrowFunction(ctx *ArrowFunctionContext) {
+	l.EndFunction()
+}
+
+func (l *JavaScriptMetricsListener) EnterMethodDefinition(ctx *MethodDefinitionContext) {
+	var name string
+	switch {
+	case ctx.Getter() != nil:
+		name = l.getterName(ctx.Getter())
+	case ctx.Setter() != nil:
+		name = l.setterName(ctx.Setter())
+	default:
+		name = l.propertyName("", ctx.PropertyName())
+	}
+	l.BeginFunction(name, formalParameters(ctx.FormalParameterList()), ctx)
+}
+
+func (l *JavaScriptMetricsListener) ExitMethodDefinition(ctx *MethodDefinitionContext) {
+	l.EndFunction()
+}
+
+func (l *JavaScriptMetricsListener) EnterFunctionProperty(ctx *FunctionPropertyContext) {
+	l.BeginFunction(l.propertyName("", ctx.PropertyName()), formalParameters(ctx.FormalParameterList()), ctx)
+}
+
+func (l *JavaScriptMetricsListener) ExitFunctionProperty(ctx *FunctionPropertyContext) {
+	l.EndFunction()
+}
+
+func (l *JavaScriptMetricsListener) EnterPropertyGetter(ctx *PropertyGetterContext) {
+	l.BeginFunction(l.getterName(ctx.Getter()), 0, ctx)
+}
+
+func (l *JavaScriptMetricsListener) ExitPropertyGetter(ctx *PropertyGetterContext) {
+	l.EndFunction()
+}
+
+func (l *JavaScriptMetricsListener) EnterPropertySetter(ctx *PropertySetterContext) {
+	l.BeginFunction(l.setterName(ctx.Setter()), 1, ctx)
+}
+
+func (l *JavaScriptMetricsListener) ExitPropertySetter(ctx *PropertySetterContext) {
+	l.EndFunction()
+}
+
+// elseIf returns true if ctx is the else branch of another if
+// statement.
+func elseIf(ctx *IfStatementContext) bool {
+	stmt, ok := ctx.GetParent().(*StatementContext)
+	if !ok {
+		return false
+	}
+	outer, ok := stmt.GetParent().(*IfStatementContext)
+	return ok && outer.Statement(1) == stmt
+}
+
+func (l *JavaScriptMetricsListener) EnterIfStatement(ctx *IfStatementContext) {
+	l.Branch()
+	if elseIf(ctx) {
+		l.Flow()
+	} else {
+		l.Structure()
+		l.Nest()
+	}
+	if e, ok := ctx.Statement(1).(*StatementContext); ok && e.IfStatement() == nil {
+		l.Flow() // else
+	}
+}
+
+func (l *JavaScriptMetricsListener) ExitIfStatement(ctx *IfStatementContext) {
+	if !elseIf(ctx) {
+		l.Unnest()
+	}
+}
+
+func (l *JavaScriptMetricsListener) enterLoop() {
+	l.Branch()
+	l.Structure()
+	l.Nest()
+}
+
+func (l *JavaScriptMetricsListener) EnterDoStatement(ctx *DoStatementContext)       { l.enterLoop() }
+func (l *JavaScriptMetricsListener) ExitDoStatement(ctx *DoStatementContext)        { l.Unnest() }
+func (l *JavaScriptMetricsListener) EnterWhileStatement(ctx *WhileStatementContext) { l.enterLoop() }
+func (l *JavaScriptMetricsListener) ExitWhileStatement(ctx *WhileStatementContext)  { l.Unnest() }
+func (l *JavaScriptMetricsListener) EnterForStatement(ctx *ForStatementContext)     { l.enterLoop() }
+func (l *JavaScriptMetricsListener) ExitForStatement(ctx *ForStatementContext)      { l.Unnest() }
+func (l *JavaScriptMetricsListener) EnterForInStatement(ctx *ForInStatementContext) { l.enterLoop() }
+func (l *JavaScriptMetricsListener) ExitForInStatement(ctx *ForInStatementContext)  { l.Unnest() }
+func (l *JavaScriptMetricsListener) EnterForOfStatement(ctx *ForOfStatementContext) { l.enterLoop() }
+func (l *JavaScriptMetricsListener) ExitForOfStatement(ctx *ForOfStatementContext)  { l.Unnest() }
+
+func (l *JavaScriptMetricsListener) EnterSwitchStatement(ctx *SwitchStatementContext) {
+	l.Structure()
+	l.Nest()
+}
+
+func (l *JavaScriptMetricsListener) ExitSwitchStatement(ctx *SwitchStatementContext) {
+	l.Unnest()
+}
+
+func (l *JavaScriptMetricsListener) EnterCaseClause(ctx *CaseClauseContext) {
+	l.Branch()
+}
+
+func (l *JavaScriptMetricsListener) EnterCatchProduction(ctx *CatchProductionContext) {
+	l.Branch()
+	l.Structure()
+	l.Nest()
+}
+
+func (l *JavaScriptMetricsListener) ExitCatchProduction(ctx *CatchProductionContext) {
+	l.Unnest()
+}
+
+func (l *JavaScriptMetricsListener) EnterTernaryExpression(ctx *TernaryExpressionContext) {
+	l.Branch()
+	l.Structure()
+	l.Nest()
+}
+
+func (l *JavaScriptMetricsListener) ExitTernaryExpression(ctx *TernaryExpressionContext) {
+	l.Unnest()
+}
+
+// Each logical operator is a decision; a sequence of the same
+// operator, as in a && b && c, breaks the flow once.
+func (l *JavaScriptMetricsListener) EnterLogicalAndExpression(ctx *LogicalAndExpressionContext) {
+	l.Branch()
+	if _, same := ctx.GetParent().(*LogicalAndExpressionContext); !same {
+		l.Flow()
+	}
+}
+
+func (l *JavaScriptMetricsListener) EnterLogicalOrExpression(ctx *LogicalOrExpressionContext) {
+	l.Branch()
+	if _, same := ctx.GetParent().(*LogicalOrExpressionContext); !same {
+		l.Flow()
+	}
+}
+
+func (l *JavaScriptMetricsListener) EnterCoalesceExpression(ctx *CoalesceExpressionContext) {
+	l.Branch()
+	if _, same := ctx.GetParent().(*CoalesceExpressionContext); !same {
+		l.Flow()
+	}
+}
+
+func (l *JavaScriptMetricsListener) EnterBreakStatement(ctx *BreakStatementContext) {
+	if ctx.Identifier() != nil {
+		l.Flow()
+	}
+}
+
+func (l *JavaScriptMetricsListener) EnterContinueStatement(ctx *ContinueStatementContext) {
+	if ctx.Identifier() != nil {
+		l.Flow()
+	}
+}
@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+const metricsSrc = `// total sums the prices of the items.
+function total(items, tax) {
+  let sum = 0;
+  for (const item of items) {
+    if (item.price > 0 && item.count) {
+      sum += item.price;
+    } else {
+      continue;
+    }
+  }
+  try {
+    check(sum);
+  } catch (e) {
+    return tax ? sum : 0;
+  }
+  return sum ?? 0;
+}
+
+const round = (x) => x > 0 ? Math.round(x) : 0;
+
+const shape = {
+  get area() {
+    return this.w * this.h;
+  },
+  set area(a) {
+    this.w = a;
+  },
+  scale: function (f) {
+    switch (f) {
+      case 0: return null;
+      case 1: return this;
+      default: return f || this.w && this.h;
+    }
+  },
+};
+
+class Box {
+  open(a, b) {
+    while (a) {
+      a = b.next(() => a - 1);
+    }
+  }
+  get size() { return 1; }
+}
+`
+
+func javaScriptMetrics(src string) []FunctionMetrics {
+	source := ParseJavaScriptString("m.js", src)
+	l := NewJavaScriptMetricsListener(source.Name, source.Tokens)
+	antlr.ParseTreeWalkerDefault.Walk(l, source.Tree)
+	return l.Functions()
+}
+
+func TestJavaScriptMetrics(t *testing.T) {
+	// total: cyclomatic 1 + for + if + && + catch + ?: + ??; cognitive
+	// for (1), if nested once (2), && (1), else (1), catch (1), ?:
+	// nested once (2), ?? (1).
+	// round: cyclomatic 1 + ?:; cognitive ?: (1).
+	// scale: cyclomatic 1 + two cases + || + &&; cognitive switch (1),
+	// || (1) and && (1), which are not a sequence.
+	// open: cyclomatic 1 + while; cognitive while (1). The arrow
+	// function is counted on its own.
+	want := []FunctionMetrics{
+		{File: "m.js", Name: "total", Line: 2, EndLine: 17, Parameters: 2, Cyclomatic: 7, Cognitive: 9, Lines: 16},
+		{File: "m.js", Name: "round", Line: 19, EndLine: 19, Parameters: 1, Cyclomatic: 2, Cognitive: 1, Lines: 1},
+		{File: "m.js", Name: "get area", Line: 22, EndLine: 24, Parameters: 0, Cyclomatic: 1, Cognitive: 0, Lines: 3},
+		{File: "m.js", Name: "set area", Line: 25, EndLine: 27, Parameters: 1, Cyclomatic: 1, Cognitive: 0, Lines: 3},
+		{File: "m.js", Name: "scale", Line: 28, EndLine: 34, Parameters: 1, Cyclomatic: 5, Cognitive: 3, Lines: 7},
+		{File: "m.js", Name: "open", Line: 38, EndLine: 42, Parameters: 2, Cyclomatic: 2, Cognitive: 1, Lines: 5},
+		{File: "m.js", Name: "open.func1", Line: 40, EndLine: 40, Parameters: 0, Cyclomatic: 1, Cognitive: 0, Lines: 1},
+		{File: "m.js", Name: "get size", Line: 43, EndLine: 43, Parameters: 0, Cyclomatic: 1, Cognitive: 0, Lines: 1},
+	}
+	got := javaScriptMetrics(metricsSrc)
+	if len(got) != len(want) {
+		t.Fatalf("got %d functions, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %+v\nwant %+v", got[i], want[i])
+		}
+	}
+}
+
+// Error recovery may leave out the name of a method.
+func TestJavaScriptMetricsMissingName(t *testing.T) {
+	got := javaScriptMetrics("x = { *() {} }")
+	if len(got) != 1 || got[0].Name != "func" {
+		t.Errorf("got %+v, want one function named func", got)
+	}
+}
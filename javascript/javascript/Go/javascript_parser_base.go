@@ -1,10 +1,6 @@
 package parser
 
-import (
-	"strings"
-
-	"github.com/antlr/antlr4/runtime/Go/antlr"
-)
+import "github.com/antlr/antlr4/runtime/Go/antlr"
 
 // JavaScriptParserBase implementation.
 type JavaScriptParserBase struct {
@@ -31,8 +27,10 @@ func (p *JavaScriptParserBase) next(str string) bool {
 	return p.GetTokenStream().LT(1).GetText() == str
 }
 
+// Whether no line terminator separates the current token from the
+// previous one.
 func (p *JavaScriptParserBase) notLineTerminator() bool {
-	return !p.here(JavaScriptParserLineTerminator)
+	return !p.lineTerminatorAhead()
 }
 
 func (p *JavaScriptParserBase) notOpenBraceAndNotFunction() bool {
@@ -44,49 +42,15 @@ func (p *JavaScriptParserBase) closeBrace() bool {
 	return p.GetTokenStream().LT(1).GetTokenType() == JavaScriptParserCloseBrace
 }
 
-// Returns true if on the current index of the parser's
-// token stream a token of the given type exists on the
-// Hidden channel.
+// Returns true if a token of the given type is among the hidden
+// channel tokens between the previous token and the current one.
 func (p *JavaScriptParserBase) here(_type int) bool {
-	// Get the token ahead of the current index.
-	possibleIndexEosToken := p.GetCurrentToken().GetTokenIndex() - 1
-	ahead := p.GetTokenStream().Get(possibleIndexEosToken)
-
-	// Check if the token resides on the HIDDEN channel and if it's of the
-	// provided type.
-	return ahead.GetChannel() == antlr.LexerHidden && ahead.GetTokenType() == _type
+	return hasHiddenToken(p.GetTokenStream(), p.GetCurrentToken().GetTokenIndex(), _type)
 }
 
-// Returns true if on the current index of the parser's
-// token stream a token exists on the Hidden channel which
-// either is a line terminator, or is a multi line comment that
-// contains a line terminator.
+// Returns true if a line terminator, or a multi line comment that
+// contains one, is among the hidden channel tokens between the
+// previous token and the current one.
 func (p *JavaScriptParserBase) lineTerminatorAhead() bool {
-	// Get the token ahead of the current index.
-	possibleIndexEosToken := p.GetCurrentToken().GetTokenIndex() - 1
-	ahead := p.GetTokenStream().Get(possibleIndexEosToken)
-
-	if ahead.GetChannel() != antlr.LexerHidden {
-		// We're only interested in tokens on the HIDDEN channel.
-		return true
-	}
-
-	if ahead.GetTokenType() == JavaScriptParserLineTerminator {
-		// There is definitely a line terminator ahead.
-		return true
-	}
-
-	if ahead.GetTokenType() == JavaScriptParserWhiteSpaces {
-		// Get the token ahead of the current whitespaces.
-		possibleIndexEosToken = p.GetCurrentToken().GetTokenIndex() - 2
-		ahead = p.GetTokenStream().Get(possibleIndexEosToken)
-	}
-
-	// Get the token's text and type.
-	text := ahead.GetText()
-	_type := ahead.GetTokenType()
-
-	// Check if the token is, or contains a line terminator.
-	return (_type == JavaScriptParserMultiLineComment && (strings.Contains(text, "\r") || strings.Contains(text, "\n"))) ||
-		(_type == JavaScriptParserLineTerminator)
+	return newlineBefore(p.GetTokenStream(), p.GetCurrentToken().GetTokenIndex())
 }
@@ -0,0 +1,34 @@
+package parser
+
+import "testing"
+
+// The line terminators that end a statement may follow any number of
+// comments.
+func TestLineTerminatorAhead(t *testing.T) {
+	tests := []struct {
+		src        string
+		statements int // 0 for a syntax error
+	}{
+		{"a /*x*/ /*y*/ \n b", 2},
+		{"a /*x*/\n/*y*/ b", 2},
+		{"a /*x\n*/ b", 2},
+		{"a // x\n b", 2},
+		{"a\u2028b", 2},
+		{"a /*x*/ /*y*/ b", 0},
+		{"a /*x*/ /*y*/ \n ++b", 2},
+		{"a /*x*/ /*y*/ ++b", 0},
+	}
+	for _, test := range tests {
+		source := ParseJavaScriptString("test.js", test.src)
+		statements := 0
+		if source.Tree.SourceElements() != nil {
+			statements = len(source.Tree.SourceElements().(*SourceElementsContext).AllSourceElement())
+		}
+		if len(source.Errors) > 0 {
+			statements = 0
+		}
+		if statements != test.statements {
+			t.Errorf("%q: %d statements, want %d (errors %v)", test.src, statements, test.statements, source.Errors)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// SyntaxError is a lexer or parser error reported while parsing
+// a JavaScript file.
+type SyntaxError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e SyntaxError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Msg)
+}
+
+// syntaxErrorCollector records syntax errors instead of printing
+// them to the console.
+type syntaxErrorCollector struct {
+	*antlr.DefaultErrorListener
+
+	errors []SyntaxError
+}
+
+func (c *syntaxErrorCollector) SyntaxError(recognizer antlr.Recognizer, offendingSymbol interface{}, line, column int, msg string, e antlr.RecognitionException) {
+	c.errors = append(c.errors, SyntaxError{line, column, msg})
+}
+
+// JavaScriptSource is a JavaScript file parsed with JavaScriptParser.
+// The token stream is kept so that hidden channel tokens (comments,
+// white space) can be queried alongside the tree.
+type JavaScriptSource struct {
+	Name   string
+	Tokens *antlr.CommonTokenStream
+	Tree   *ProgramContext
+	Errors []SyntaxError
+}
+
+// ParseJavaScriptSource parses input as a JavaScript file, as an ES
+// module, so strict mode code, if name ends in .mjs. Syntax errors do
+// not stop the parse, they are collected in Errors and the tree holds
+// whatever the parser recovered.
+func ParseJavaScriptSource(name string, input antlr.CharStream) *JavaScriptSource {
+	errors := &syntaxErrorCollector{DefaultErrorListener: antlr.NewDefaultErrorListener()}
+
+	lexer := NewJavaScriptLexer(input)
+	lexer.RemoveErrorListeners()
+	lexer.AddErrorListener(errors)
+	lexer.SetUseStrictDefault(filepath.Ext(name) == ".mjs")
+
+	tokens := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
+	p := NewJavaScriptParser(tokens)
+	p.RemoveErrorListeners()
+	p.AddErrorListener(errors)
+
+	tree := p.Program().(*ProgramContext)
+	return &JavaScriptSource{name, tokens, tree, errors.errors}
+}
+
+// ParseJavaScriptFile reads and parses the JavaScript file at path.
+func ParseJavaScriptFile(path string) (*JavaScriptSource, error) {
+	input, err := antlr.NewFileStream(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJavaScriptSource(path, input), nil
+}
+
+// ParseJavaScriptString parses src as a JavaScript file called name.
+func ParseJavaScriptString(name, src string) *JavaScriptSource {
+	return ParseJavaScriptSource(name, antlr.NewInputStream(src))
+}
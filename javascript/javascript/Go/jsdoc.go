@@ -0,0 +1,408 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// JSDoc is a /** ... */ comment split into its description and block
+// tags. Params, Returns and Deprecated give the parsed @param (@arg,
+// @argument), @returns (@return) and @deprecated tags; Tags holds
+// every block tag in order, including those.
+type JSDoc struct {
+	Comment     antlr.Token
+	Description string
+	Params      []JSDocParam
+	Returns     *JSDocReturns
+	Deprecated  *JSDocTag
+	Tags        []JSDocTag
+}
+
+// JSDocTag is a block tag: Name without the @ and the Text up to the
+// next tag.
+type JSDocTag struct {
+	Name string
+	Text string
+	Line int
+}
+
+// JSDocParam is a @param tag. Name may be a property of another
+// parameter (options.size). Optional is set by [name] or [name=value],
+// which also gives the Default, or by a type ending with =.
+type JSDocParam struct {
+	Name        string
+	Type        string
+	Description string
+	Optional    bool
+	Default     string
+	Line        int
+}
+
+// JSDocReturns is a @returns tag.
+type JSDocReturns struct {
+	Type        string
+	Description string
+	Line        int
+}
+
+// ParseJSDoc parses the text of a /** ... */ comment starting on line.
+// The leading * of each line is dropped, and a line starting with @
+// starts a block tag.
+func ParseJSDoc(text string, line int) *JSDoc {
+	doc := &JSDoc{}
+	var description []string
+	var tag *JSDocTag
+	body := strings.TrimSuffix(strings.TrimPrefix(text, "/**"), "*/")
+	for i, l := range strings.Split(body, "\n") {
+		l = strings.TrimLeft(l, " \t")
+		l = strings.TrimPrefix(strings.TrimPrefix(l, "*"), " ")
+		l = strings.TrimRight(l, " \t\r")
+		if strings.HasPrefix(l, "@") {
+			name := strings.Fields(l)[0]
+			doc.Tags = append(doc.Tags, JSDocTag{Name: name[1:], Text: strings.TrimSpace(l[len(name):]), Line: line + i})
+			tag = &doc.Tags[len(doc.Tags)-1]
+		} else if tag != nil {
+			tag.Text += "\n" + l
+		} else {
+			description = append(description, l)
+		}
+	}
+	doc.Description = strings.TrimSpace(strings.Join(description, "\n"))
+	for i := range doc.Tags {
+		tag := &doc.Tags[i]
+		tag.Text = strings.TrimSpace(tag.Text)
+		switch tag.Name {
+		case "param", "arg", "argument":
+			doc.Params = append(doc.Params, parseParamTag(tag))
+		case "returns", "return":
+			typ, text := splitType(tag.Text)
+			doc.Returns = &JSDocReturns{typ, text, tag.Line}
+		case "deprecated":
+			doc.Deprecated = tag
+		}
+	}
+	return doc
+}
+
+// splitType splits a leading {type} off text.
+func splitType(text string) (string, string) {
+	if end := closingBracket(text, '{', '}'); end > 0 {
+		return strings.TrimSpace(text[1:end]), strings.TrimSpace(text[end+1:])
+	}
+	return "", text
+}
+
+// closingBracket returns the index of the bracket closing the one text
+// starts with, as in {Object<string, {a: number}>} or [list=[1, 2]],
+// or -1.
+func closingBracket(text string, open, close rune) int {
+	if !strings.HasPrefix(text, string(open)) {
+		return -1
+	}
+	depth := 0
+	for i, c := range text {
+		switch c {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func parseParamTag(tag *JSDocTag) JSDocParam {
+	p := JSDocParam{Line: tag.Line}
+	var text string
+	p.Type, text = splitType(tag.Text)
+	if strings.HasSuffix(p.Type, "=") {
+		p.Optional = true
+	}
+	if strings.HasPrefix(text, "[") {
+		if end := closingBracket(text, '[', ']'); end > 0 {
+			p.Optional = true
+			p.Name = text[1:end]
+			if eq := strings.Index(p.Name, "="); eq >= 0 {
+				p.Name, p.Default = p.Name[:eq], p.Name[eq+1:]
+			}
+			text = text[end+1:]
+		}
+	} else if fields := strings.Fields(text); len(fields) > 0 {
+		p.Name = fields[0]
+		text = strings.TrimSpace(text)[len(p.Name):]
+	}
+	text = strings.TrimSpace(text)
+	p.Description = strings.TrimSpace(strings.TrimPrefix(text, "-"))
+	return p
+}
+
+// JSDocComment returns the /** ... */ comment right before ctx, with
+// only white space in between, or nil.
+func JSDocComment(tokens *antlr.CommonTokenStream, ctx antlr.ParserRuleContext) *JSDoc {
+	hidden := tokens.GetHiddenTokensToLeft(ctx.GetStart().GetTokenIndex(), antlr.TokenHiddenChannel)
+	for i := len(hidden) - 1; i >= 0; i-- {
+		t := hidden[i]
+		switch t.GetTokenType() {
+		case JavaScriptLexerWhiteSpaces, JavaScriptLexerLineTerminator:
+			continue
+		case JavaScriptLexerMultiLineComment:
+			if text := t.GetText(); strings.HasPrefix(text, "/**") && text != "/**/" {
+				doc := ParseJSDoc(text, t.GetLine())
+				doc.Comment = t
+				return doc
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// DocumentedDecl is a function, class, method or variable declaration
+// with its JSDoc comment. Kind is "function", "class", "method" or
+// "variable". Function is set if the declaration is a function or a
+// variable initialized with one, whose Params are then the names of
+// the parameters, "" for a destructuring pattern.
+type DocumentedDecl struct {
+	Kind     string
+	Name     string
+	Node     antlr.ParserRuleContext
+	Function bool
+	Params   []string
+	Doc      *JSDoc
+}
+
+// JSDocDeclarations returns the declarations of source that have a
+// JSDoc comment, in source order. The comment of a declaration goes
+// before its export keyword, if any, and the comment of a method
+// before its modifiers (static, async).
+func JSDocDeclarations(source *JavaScriptSource) []*DocumentedDecl {
+	l := &jsdocListener{tokens: source.Tokens}
+	antlr.ParseTreeWalkerDefault.Walk(l, source.Tree)
+	return l.decls
+}
+
+type jsdocListener struct {
+	BaseJavaScriptParserListener
+
+	tokens *antlr.CommonTokenStream
+	decls  []*DocumentedDecl
+}
+
+func (l *jsdocListener) add(decl *DocumentedDecl, anchor antlr.ParserRuleContext) {
+	if decl.Doc = JSDocComment(l.tokens, anchor); decl.Doc != nil {
+		l.decls = append(l.decls, decl)
+	}
+}
+
+// exportAnchor returns the export statement declaring ctx, or ctx.
+func exportAnchor(ctx antlr.ParserRuleContext) antlr.ParserRuleContext {
+	for p := ctx.GetParent(); p != nil; p = p.GetParent() {
+		switch p := p.(type) {
+		case *ExportDeclarationContext:
+			return p
+		case *ExportDefaultDeclarationContext:
+			return p
+		case *DeclarationContext, *FunctionDeclContext, *FunctionExpressionContext:
+		default:
+			return ctx
+		}
+	}
+	return ctx
+}
+
+func (l *jsdocListener) EnterFunctionDeclaration(ctx *FunctionDeclarationContext) {
+	if ctx.Identifier() == nil {
+		return
+	}
+	decl := &DocumentedDecl{Kind: "function", Name: ctx.Identifier().GetText(), Node: ctx, Function: true,
+		Params: parameterNames(ctx.FormalParameterList())}
+	l.add(decl, exportAnchor(ctx))
+}
+
+func (l *jsdocListener) EnterClassDeclaration(ctx *ClassDeclarationContext) {
+	if ctx.Identifier() == nil {
+		return
+	}
+	l.add(&DocumentedDecl{Kind: "class", Name: ctx.Identifier().GetText(), Node: ctx}, exportAnchor(ctx))
+}
+
+func (l *jsdocListener) EnterMethodDefinition(ctx *MethodDefinitionContext) {
+	decl := &DocumentedDecl{Kind: "method", Node: ctx, Function: true, Params: parameterNames(ctx.FormalParameterList())}
+	switch {
+	case ctx.PropertyName() != nil:
+		decl.Name = ctx.PropertyName().GetText()
+	case ctx.Getter() != nil:
+		if getter, ok := ctx.Getter().(*GetterContext); ok && getter.PropertyName() != nil {
+			decl.Name = "get " + getter.PropertyName().GetText()
+		}
+	case ctx.Setter() != nil:
+		if setter, ok := ctx.Setter().(*SetterContext); ok && setter.PropertyName() != nil {
+			decl.Name = "set " + setter.PropertyName().GetText()
+		}
+	}
+	var anchor antlr.ParserRuleContext = ctx
+	if element, ok := ctx.GetParent().(*ClassElementContext); ok {
+		anchor = element
+	}
+	l.add(decl, anchor)
+}
+
+func (l *jsdocListener) EnterFunctionProperty(ctx *FunctionPropertyContext) {
+	if ctx.PropertyName() == nil {
+		return
+	}
+	l.add(&DocumentedDecl{Kind: "method", Name: ctx.PropertyName().GetText(), Node: ctx, Function: true,
+		Params: parameterNames(ctx.FormalParameterList())}, ctx)
+}
+
+func (l *jsdocListener) EnterPropertyGetter(ctx *PropertyGetterContext) {
+	getter, ok := ctx.Getter().(*GetterContext)
+	if !ok || getter.PropertyName() == nil {
+		return
+	}
+	l.add(&DocumentedDecl{Kind: "method", Name: "get " + getter.PropertyName().GetText(), Node: ctx, Function: true}, ctx)
+}
+
+func (l *jsdocListener) EnterPropertySetter(ctx *PropertySetterContext) {
+	setter, ok := ctx.Setter().(*SetterContext)
+	if !ok || setter.PropertyName() == nil {
+		return
+	}
+	l.add(&DocumentedDecl{Kind: "method", Name: "set " + setter.PropertyName().GetText(), Node: ctx, Function: true,
+		Params: []string{parameterName(ctx.FormalParameterArg())}}, ctx)
+}
+
+// A variable statement is documented as its first variable.
+func (l *jsdocListener) EnterVariableStatement(ctx *VariableStatementContext) {
+	list, ok := ctx.VariableDeclarationList().(*VariableDeclarationListContext)
+	if !ok {
+		return
+	}
+	first, ok := list.VariableDeclaration(0).(*VariableDeclarationContext)
+	if !ok || first.Assignable() == nil {
+		return
+	}
+	decl := &DocumentedDecl{Kind: "variable", Name: first.Assignable().GetText(), Node: ctx}
+	if fn, ok := first.SingleExpression().(*FunctionExpressionContext); ok {
+		decl.Function = true
+		switch f := fn.AnonymousFunction().(type) {
+		case *FunctionDeclContext:
+			if fd, ok := f.FunctionDeclaration().(*FunctionDeclarationContext); ok {
+				decl.Params = parameterNames(fd.FormalParameterList())
+			}
+		case *AnonymousFunctionDeclContext:
+			decl.Params = parameterNames(f.FormalParameterList())
+		case *ArrowFunctionContext:
+			if params, ok := f.ArrowFunctionParameters().(*ArrowFunctionParametersContext); ok {
+				if params.Identifier() != nil {
+					decl.Params = []string{params.Identifier().GetText()}
+				} else {
+					decl.Params = parameterNames(params.FormalParameterList())
+				}
+			}
+		}
+	}
+	l.add(decl, exportAnchor(ctx))
+}
+
+// parameterNames returns the names of the parameters, "" for a
+// destructuring pattern.
+func parameterNames(ctx IFormalParameterListContext) []string {
+	list, ok := ctx.(*FormalParameterListContext)
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, arg := range list.AllFormalParameterArg() {
+		names = append(names, parameterName(arg))
+	}
+	if last, ok := list.LastFormalParameterArg().(*LastFormalParameterArgContext); ok {
+		name := ""
+		if id, ok := last.SingleExpression().(*IdentifierExpressionContext); ok {
+			name = id.GetText()
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// parameterName returns the name of a parameter, "" for a
+// destructuring pattern.
+func parameterName(ctx IFormalParameterArgContext) string {
+	arg, ok := ctx.(*FormalParameterArgContext)
+	if !ok {
+		return ""
+	}
+	if a, ok := arg.Assignable().(*AssignableContext); ok && a.Identifier() != nil {
+		return a.Identifier().GetText()
+	}
+	return ""
+}
+
+// ParamMismatch is a difference between the @param tags of a
+// declaration and its parameters, at position Index. Documented is ""
+// for a parameter with no tag, Actual "" for a tag with no parameter.
+// Line is the line of the tag, or of the declaration.
+type ParamMismatch struct {
+	Decl       string
+	Index      int
+	Documented string
+	Actual     string
+	Line       int
+}
+
+func (m ParamMismatch) String() string {
+	switch {
+	case m.Documented == "":
+		return fmt.Sprintf("%d: %s: parameter %s is not documented", m.Line, m.Decl, m.Actual)
+	case m.Actual == "":
+		return fmt.Sprintf("%d: %s: @param %s documents no parameter", m.Line, m.Decl, m.Documented)
+	default:
+		return fmt.Sprintf("%d: %s: @param %s documents parameter %s", m.Line, m.Decl, m.Documented, m.Actual)
+	}
+}
+
+// ParamMismatches compares the @param tags of the comment with the
+// parameters of a function declaration by position. Tags of
+// properties (options.size) are skipped, and a destructuring pattern
+// takes any name. A comment without @param tags documents nothing, so
+// it has no mismatches.
+func (d *DocumentedDecl) ParamMismatches() []ParamMismatch {
+	if !d.Function {
+		return nil
+	}
+	var params []JSDocParam
+	for _, p := range d.Doc.Params {
+		if !strings.ContainsAny(p.Name, ".[") {
+			params = append(params, p)
+		}
+	}
+	if len(params) == 0 {
+		return nil
+	}
+	var mismatches []ParamMismatch
+	for i := 0; i < len(params) || i < len(d.Params); i++ {
+		m := ParamMismatch{Decl: d.Name, Index: i}
+		if i < len(params) {
+			m.Documented = params[i].Name
+			m.Line = params[i].Line
+		} else {
+			m.Line = d.Node.GetStart().GetLine()
+		}
+		if i < len(d.Params) {
+			m.Actual = d.Params[i]
+			if m.Actual == "" && m.Documented != "" {
+				continue // a pattern
+			}
+		}
+		if m.Documented != m.Actual {
+			mismatches = append(mismatches, m)
+		}
+	}
+	return mismatches
+}
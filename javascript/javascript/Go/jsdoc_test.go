@@ -0,0 +1,136 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseJSDoc(t *testing.T) {
+	doc := ParseJSDoc(`/**
+ * Resizes the image.
+ *
+ * Keeps the ratio.
+ * @param {number} width - the width
+ * @param {{w: number, h: {min: number}}} size the size,
+ *   in pixels
+ * @param {string=} unit
+ * @arg {number[]} [list=[1, 2]] - the steps
+ * @param {Object} [options]
+ * @param {boolean} [options.crop=false]
+ * @returns {Promise<{ok: boolean}>} whether it worked
+ * @deprecated Use scale.
+ * @see scale
+ */`, 10)
+	if doc.Description != "Resizes the image.\n\nKeeps the ratio." {
+		t.Errorf("got description %q", doc.Description)
+	}
+	params := []JSDocParam{
+		{Name: "width", Type: "number", Description: "the width", Line: 14},
+		{Name: "size", Type: "{w: number, h: {min: number}}", Description: "the size,\n  in pixels", Line: 15},
+		{Name: "unit", Type: "string=", Optional: true, Line: 17},
+		{Name: "list", Type: "number[]", Description: "the steps", Optional: true, Default: "[1, 2]", Line: 18},
+		{Name: "options", Type: "Object", Optional: true, Line: 19},
+		{Name: "options.crop", Type: "boolean", Optional: true, Default: "false", Line: 20},
+	}
+	if len(doc.Params) != len(params) {
+		t.Fatalf("got params %+v", doc.Params)
+	}
+	for i := range params {
+		if doc.Params[i] != params[i] {
+			t.Errorf("got param %+v\nwant %+v", doc.Params[i], params[i])
+		}
+	}
+	if doc.Returns == nil || *doc.Returns != (JSDocReturns{"Promise<{ok: boolean}>", "whether it worked", 21}) {
+		t.Errorf("got returns %+v", doc.Returns)
+	}
+	if doc.Deprecated == nil || *doc.Deprecated != (JSDocTag{"deprecated", "Use scale.", 22}) {
+		t.Errorf("got deprecated %+v", doc.Deprecated)
+	}
+	if len(doc.Tags) != 9 || doc.Tags[8] != (JSDocTag{"see", "scale", 23}) {
+		t.Errorf("got tags %+v", doc.Tags)
+	}
+}
+
+const jsdocSrc = `/**
+ * Adds two numbers.
+ * @param {number} a
+ * @param {number} b
+ */
+export function add(a, b) { return a + b; }
+
+/** @param {number} x */
+export default function scale(x, factor) { return x * factor; }
+
+/** Detached. */
+;
+function detached() {}
+
+/** @param {string} s */
+export const trim = (s, chars) => s;
+
+/**/
+function empty() {}
+
+/** A shape. */
+class Shape {
+  /**
+   * @param {number} w
+   * @param {number} [h]
+   */
+  static async create(w, h) {}
+  /** @param {{x: number}} options */
+  move({ x }) {}
+  /** @param {number} ratio */
+  get ratio() { return 1; }
+}
+
+const o = {
+  /** Width. */
+  get width() { return 1; },
+  /** @param {number} value */
+  set width(w) {},
+  /** @param {number} n */
+  run(n) {},
+};
+`
+
+func TestJSDocDeclarations(t *testing.T) {
+	source := ParseJavaScriptString("doc.js", jsdocSrc)
+	if len(source.Errors) > 0 {
+		t.Fatal(source.Errors)
+	}
+	var decls, mismatches []string
+	for _, d := range JSDocDeclarations(source) {
+		decls = append(decls, fmt.Sprintf("%d %s %s %v %q", d.Doc.Comment.GetLine(), d.Kind, d.Name, d.Function, d.Params))
+		for _, m := range d.ParamMismatches() {
+			mismatches = append(mismatches, m.String())
+		}
+	}
+	// The comment of an export goes before export, the comment of a
+	// method before its modifiers.
+	want := []string{
+		`1 function add true ["a" "b"]`,
+		`8 function scale true ["x" "factor"]`,
+		`15 variable trim true ["s" "chars"]`,
+		`21 class Shape false []`,
+		`23 method create true ["w" "h"]`,
+		`28 method move true [""]`,
+		`30 method get ratio true []`,
+		`35 method get width true []`,
+		`37 method set width true ["w"]`,
+		`39 method run true ["n"]`,
+	}
+	if strings.Join(decls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got declarations\n%s\nwant\n%s", strings.Join(decls, "\n"), strings.Join(want, "\n"))
+	}
+	wantMismatches := []string{
+		"9: scale: parameter factor is not documented",
+		"16: trim: parameter chars is not documented",
+		"30: get ratio: @param ratio documents no parameter",
+		"37: set width: @param value documents parameter w",
+	}
+	if strings.Join(mismatches, "\n") != strings.Join(wantMismatches, "\n") {
+		t.Errorf("got mismatches\n%s\nwant\n%s", strings.Join(mismatches, "\n"), strings.Join(wantMismatches, "\n"))
+	}
+}
@@ -0,0 +1,137 @@
+package parser
+
+import "github.com/antlr/antlr4/runtime/Go/antlr"
+
+// IsStrictMode returns true if the code of node is strict mode code:
+// node is a function with a "use strict" directive, is in such a
+// function or in a class, or the program starts with the directive.
+// strictDefault is the strict mode by default of the lexer, see
+// SetUseStrictDefault, true for an ES module.
+//
+// For the functions of the tree, IsStrictMode agrees with the strict
+// mode of their tokens, in which the lexer matched keywords such as
+// let, static and yield.
+func IsStrictMode(node antlr.Tree, strictDefault bool) bool {
+	if strictDefault || hasStrictBody(node) {
+		return true
+	}
+	for n := node; n != nil; n = n.GetParent() {
+		switch ctx := n.(type) {
+		case *FunctionBodyContext:
+			if hasUseStrict(ctx.SourceElements()) {
+				return true
+			}
+		case *ClassTailContext:
+			// The class heritage and body of a class declaration or
+			// expression
+			return true
+		case *ProgramContext:
+			return hasUseStrict(ctx.SourceElements())
+		}
+	}
+	return false
+}
+
+// hasStrictBody returns true if node is a function, method or arrow
+// function whose body has a "use strict" directive.
+//
+// The body of () => { 'use strict' } parses as an object literal with
+// a shorthand property, while the lexer, for which a { after => always
+// starts a function body, switches to strict mode: such an object
+// literal counts as a body with the directive.
+func hasStrictBody(node antlr.Tree) bool {
+	for i := 0; i < node.GetChildCount(); i++ {
+		switch child := node.GetChild(i).(type) {
+		case *FunctionBodyContext:
+			return hasUseStrict(child.SourceElements())
+		case *ArrowFunctionBodyContext:
+			if body, ok := child.FunctionBody().(*FunctionBodyContext); ok {
+				return hasUseStrict(body.SourceElements())
+			}
+			return isUseStrictObject(child.SingleExpression())
+		}
+	}
+	return false
+}
+
+// isUseStrictObject returns true if expression is { "use strict" },
+// without a trailing comma, after which the lexer would leave strict
+// mode again.
+func isUseStrictObject(expression ISingleExpressionContext) bool {
+	object, ok := expression.(*ObjectLiteralExpressionContext)
+	if !ok {
+		return false
+	}
+	literal, ok := object.ObjectLiteral().(*ObjectLiteralContext)
+	if !ok || len(literal.AllPropertyAssignment()) != 1 || len(literal.AllComma()) != 0 {
+		return false
+	}
+	property, ok := literal.PropertyAssignment(0).(*PropertyShorthandContext)
+	if !ok || property.Ellipsis() != nil {
+		return false
+	}
+	text, ok := stringLiteral(property.SingleExpression())
+	return ok && isUseStrict(text)
+}
+
+// hasUseStrict returns true if the directive prologue of elements, the
+// string literal statements they start with, has a "use strict"
+// directive.
+func hasUseStrict(elements ISourceElementsContext) bool {
+	list, ok := elements.(*SourceElementsContext)
+	if !ok {
+		return false
+	}
+	for _, element := range list.AllSourceElement() {
+		text, ok := directive(element)
+		if !ok {
+			return false
+		}
+		if isUseStrict(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// isUseStrict returns true for exactly "use strict" or 'use strict',
+// without escapes.
+func isUseStrict(text string) bool {
+	return text == `"use strict"` || text == "'use strict'"
+}
+
+// directive returns the string literal of a statement made of a
+// string literal alone.
+func directive(element ISourceElementContext) (string, bool) {
+	source, ok := element.(*SourceElementContext)
+	if !ok {
+		return "", false
+	}
+	statement, ok := source.Statement().(*StatementContext)
+	if !ok {
+		return "", false
+	}
+	expression, ok := statement.ExpressionStatement().(*ExpressionStatementContext)
+	if !ok {
+		return "", false
+	}
+	sequence, ok := expression.ExpressionSequence().(*ExpressionSequenceContext)
+	if !ok || len(sequence.AllSingleExpression()) != 1 {
+		return "", false
+	}
+	return stringLiteral(sequence.SingleExpression(0))
+}
+
+// stringLiteral returns the text of expression if it is a string
+// literal.
+func stringLiteral(expression ISingleExpressionContext) (string, bool) {
+	literal, ok := expression.(*LiteralExpressionContext)
+	if !ok {
+		return "", false
+	}
+	value, ok := literal.Literal().(*LiteralContext)
+	if !ok || value.StringLiteral() == nil {
+		return "", false
+	}
+	return value.StringLiteral().GetText(), true
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// strictNodes returns the functions, methods and blocks of tree in
+// the order they start.
+func strictNodes(tree antlr.Tree) []antlr.Tree {
+	var nodes []antlr.Tree
+	switch tree.(type) {
+	case *FunctionDeclarationContext, *AnonymousFunctionDeclContext, *ArrowFunctionContext, *MethodDefinitionContext, *BlockContext:
+		nodes = append(nodes, tree)
+	}
+	for i := 0; i < tree.GetChildCount(); i++ {
+		nodes = append(nodes, strictNodes(tree.GetChild(i))...)
+	}
+	return nodes
+}
+
+func TestIsStrictMode(t *testing.T) {
+	tests := []struct {
+		src    string
+		strict string // s or - for each node of strictNodes
+	}{
+		{"function f() { 'a'; 'use strict'; }", "s"},
+		{"function f() { 'a'\n'use strict' }", "s"},
+		{"function f() { 'a' + 1; 'use strict'; }", "-"},
+		{"function f() { 'use strict'.length; }", "-"},
+		{`function f() { "use\x20strict"; }`, "-"},
+		{"function f() { 'use strict'; return function () { { } }; }", "sss"},
+		{"function f() { { 'use strict'; } }", "--"},
+		{"'use strict'; { } function f() { }", "ss"},
+		{"{ 'use strict'; } function f() { }", "--"},
+		{"class A { m() { } } function f() { }", "s-"},
+		{"x = class extends (function () { }) { }", "s"},
+		{"var a = () => { 'use strict' }", "s"},
+		{"var a = () => { 'use strict'; }", "s"},
+		{"var a = () => { 'use strict', }", "-"},
+		{"var a = () => ({ 'use strict' })", "-"},
+		{"var a = () => 'use strict'", "-"},
+	}
+	for _, test := range tests {
+		source := ParseJavaScriptString("test.js", test.src)
+		if len(source.Errors) > 0 {
+			t.Errorf("%q: %v", test.src, source.Errors)
+			continue
+		}
+		strict := ""
+		for _, node := range strictNodes(source.Tree) {
+			if IsStrictMode(node, false) {
+				strict += "s"
+			} else {
+				strict += "-"
+			}
+		}
+		if strict != test.strict {
+			t.Errorf("%q: got %s, want %s", test.src, strict, test.strict)
+		}
+	}
+	if !IsStrictMode(ParseJavaScriptString("test.mjs", "function f() { }").Tree, true) {
+		t.Error("an ES module is not strict")
+	}
+}
@@ -8,12 +8,6 @@ import java.util.Stack;
  */
 public abstract class JavaScriptLexerBase extends Lexer
 {
-    /**
-     * Stores values of nested modes. By default mode is strict or
-     * defined externally (useStrictDefault)
-     */
-    private Stack<Boolean> scopeStrictModes = new Stack<Boolean>();
-
     private Token lastToken = null;
     /**
      * Default value of strict mode
@@ -25,16 +19,65 @@ public abstract class JavaScriptLexerBase extends Lexer
      * Can be defined during parsing, see StringFunctions.js and StringGlobal.js samples
      */
     private boolean useStrictCurrent = false;
+
     /**
-     * Keeps track of the the current depth of nested template string backticks.
-     * E.g. after the X in:
-     *
-     * `${a ? `${X
-     *
-     * templateDepth will be 2. This variable is needed to determine if a `}` is a
-     * plain CloseBrace, or one that closes an expression inside a template string.
+     * Kinds of contexts, which tell whether the closing bracket ends an
+     * expression and whether a } ends a template substitution.
      */
-    private int templateDepth = 0;
+    private static final int PAREN = 0;               // ( of an expression or parameter list
+    private static final int HEADER = 1;              // ( of an if, while, for, with, switch or catch header
+    private static final int BRACKET = 2;             // [
+    private static final int BLOCK = 3;               // { of a block
+    private static final int FUNCTION = 4;            // { of a function declaration, method or arrow function body
+    private static final int FUNCTION_EXPRESSION = 5; // { of a function expression body
+    private static final int CLASS = 6;               // { of a class body
+    private static final int OBJECT = 7;              // { of an object literal or pattern
+    private static final int TEMPLATE = 8;            // ` of a template string
+    private static final int SUBSTITUTION = 9;        // ${ of a template string
+
+    /**
+     * States of the directive prologue: at its start or after a ;, after a
+     * string literal which may be a directive, and past the prologue.
+     */
+    private static final int PROLOGUE_START = 0;
+    private static final int PROLOGUE_DIRECTIVE = 1;
+    private static final int PROLOGUE_NONE = 2;
+
+    /**
+     * Records why a bracket or a template string was opened, and the strict
+     * mode of the code around it, restored when a brace closes.
+     */
+    private static final class Context {
+        final int kind;
+        final boolean strict;
+
+        Context(int kind, boolean strict) {
+            this.kind = kind;
+            this.strict = strict;
+        }
+    }
+
+    /**
+     * The open brackets, braces and template strings, innermost on top, the
+     * token before lastToken and whether a regex literal can follow lastToken,
+     * see IsRegexPossible.
+     */
+    private Stack<Context> contexts = new Stack<Context>();
+    private Context closedBrace = null;
+    private Context closedParen = null;
+    private Token beforeLastToken = null;
+    private boolean regexPossible = true;
+
+    /**
+     * The depth of the contexts plus one where a class keyword or the function
+     * keyword of a function expression waits for its body, 0 if none, and the
+     * state of the directive prologue of the program or function body being
+     * lexed, see ProcessStringLiteral.
+     */
+    private int pendingClass = 0;
+    private int pendingFunction = 0;
+    private int prologue = PROLOGUE_START;
+    private boolean strictBeforeDirective = false;
 
     public JavaScriptLexerBase(CharStream input) {
         super(input);
@@ -57,16 +100,19 @@ public abstract class JavaScriptLexerBase extends Lexer
         return useStrictCurrent;
     }
 
+    /**
+     * Returns {@code true} if a } closes a substitution of a template string,
+     * rather than a brace opened inside it as in `a${ {b: 1}.b }c`.
+     */
     public boolean IsInTemplateString() {
-        return this.templateDepth > 0;
+        return !contexts.isEmpty() && contexts.peek().kind == SUBSTITUTION;
     }
 
     /**
      * Return the next token from the character stream and records this last
      * token in case it resides on the default channel. This recorded token
      * is used to determine when the lexer could possibly match a regex
-     * literal. Also changes scopeStrictModes stack if tokenize special
-     * string 'use strict';
+     * literal. Also keeps the stack of open brackets up to date.
      *
      * @return the next token from the character stream.
      */
@@ -75,7 +121,10 @@ public abstract class JavaScriptLexerBase extends Lexer
         Token next = super.nextToken();
 
         if (next.getChannel() == Token.DEFAULT_CHANNEL) {
-            // Keep track of the last token on the default channel.
+            // Keep track of the last tokens on the default channel.
+            this.regexPossible = this.regexCanFollow(next);
+            this.updatePrologue(next);
+            this.beforeLastToken = this.lastToken;
             this.lastToken = next;
         }
 
@@ -84,67 +133,294 @@ public abstract class JavaScriptLexerBase extends Lexer
 
     protected void ProcessOpenBrace()
     {
-        useStrictCurrent = scopeStrictModes.size() > 0 && scopeStrictModes.peek() ? true : useStrictDefault;
-        scopeStrictModes.push(useStrictCurrent);
+        int kind = braceContext();
+        if (kind == FUNCTION && pendingFunction == contexts.size() + 1)
+        {
+            pendingFunction = 0;
+            if (lastTokenIs(JavaScriptLexer.CloseParen))
+            {
+                kind = FUNCTION_EXPRESSION; // not the body of async x => {
+            }
+        }
+        pushContext(kind);
+        if (kind == CLASS)
+        {
+            // A class body is strict mode code.
+            useStrictCurrent = true;
+        }
     }
 
     protected void ProcessCloseBrace()
     {
-        useStrictCurrent = scopeStrictModes.size() > 0 ? scopeStrictModes.pop() : useStrictDefault;
+        closedBrace = popContext();
+        useStrictCurrent = closedBrace.strict;
     }
 
+    /**
+     * A "use strict" directive in the prologue of the program or of a function
+     * body makes the code strict, until updatePrologue finds the string is
+     * part of an expression.
+     */
     protected void ProcessStringLiteral()
     {
-        if (lastToken == null || lastToken.getType() == JavaScriptLexer.OpenBrace)
+        // "use strict" may follow a directive without ; on a new line.
+        if (prologue == PROLOGUE_START
+            || prologue == PROLOGUE_DIRECTIVE && lastToken.getLine() < getLine())
         {
+            strictBeforeDirective = useStrictCurrent;
             String text = getText();
             if (text.equals("\"use strict\"") || text.equals("'use strict'"))
             {
-                if (scopeStrictModes.size() > 0)
-                    scopeStrictModes.pop();
                 useStrictCurrent = true;
-                scopeStrictModes.push(useStrictCurrent);
             }
         }
     }
 
+    /**
+     * Follows the directive prologue with {@code next}, the new last token. A
+     * string literal is a directive if a ; or a line break ends its statement,
+     * as in "use strict"; but not "use strict".length.
+     */
+    private void updatePrologue(Token next) {
+        if (prologue == PROLOGUE_START) {
+            if (next.getType() == JavaScriptLexer.StringLiteral) {
+                prologue = PROLOGUE_DIRECTIVE;
+            } else if (next.getType() != JavaScriptLexer.HashBangLine) {
+                prologue = PROLOGUE_NONE;
+            }
+        } else if (prologue == PROLOGUE_DIRECTIVE) {
+            if (next.getType() == JavaScriptLexer.SemiColon) {
+                prologue = PROLOGUE_START;
+            } else if (next.getLine() > lastToken.getLine()) {
+                if (next.getType() != JavaScriptLexer.StringLiteral) {
+                    prologue = PROLOGUE_NONE;
+                }
+            } else if (next.getType() == JavaScriptLexer.CloseBrace || next.getType() == Token.EOF) {
+                prologue = PROLOGUE_NONE;
+            } else {
+                useStrictCurrent = strictBeforeDirective;
+                prologue = PROLOGUE_NONE;
+            }
+        }
+        if (next.getType() == JavaScriptLexer.OpenBrace && isFunctionBody(contexts.peek().kind)) {
+            // Pushed by ProcessOpenBrace
+            prologue = PROLOGUE_START;
+        }
+    }
+
     public void IncreaseTemplateDepth() {
-        this.templateDepth++;
+        pushContext(TEMPLATE);
     }
 
     public void DecreaseTemplateDepth() {
-        this.templateDepth--;
+        popContext();
     }
 
     /**
-     * Returns {@code true} if the lexer can match a regex literal.
+     * Returns {@code true} if the lexer can match a regex literal, that is
+     * if the syntactic grammar expects an expression after the last token.
      */
     protected boolean IsRegexPossible() {
+        return this.lastToken == null || this.regexPossible;
+    }
 
-        if (this.lastToken == null) {
-            // No token has been produced yet: at the start of the input,
-            // no division is possible, so a regex literal _is_ possible.
-            return true;
-        }
-
-        switch (this.lastToken.getType()) {
+    /**
+     * Returns {@code true} if a regex literal can follow {@code next}, the
+     * new last token, and keeps the stack of contexts up to date for the
+     * tokens without a lexer action.
+     */
+    private boolean regexCanFollow(Token next) {
+        switch (next.getType()) {
+            case JavaScriptLexer.OpenParen:
+                if (lastTokenIs(JavaScriptLexer.Class)) {
+                    pendingClass = 0; // a method named class
+                }
+                pushContext(isHeaderKeyword() ? HEADER : PAREN);
+                return true;
+            case JavaScriptLexer.OpenBracket:
+                pushContext(BRACKET);
+                return true;
+            case JavaScriptLexer.TemplateStringStartExpression:
+                pushContext(SUBSTITUTION);
+                return true;
+            case JavaScriptLexer.CloseParen:
+                // if (x) /re/.test(s) but (x) / 2
+                closedParen = popContext();
+                return closedParen.kind == HEADER;
+            case JavaScriptLexer.CloseBrace:
+                // {} /re/.test(s) but ({}) / 2 and x = function () {} / 2,
+                // popped by ProcessCloseBrace
+                return closedBrace.kind != OBJECT && closedBrace.kind != FUNCTION_EXPRESSION;
+            case JavaScriptLexer.CloseBracket:
+                popContext();
+                return false;
+            case JavaScriptLexer.TemplateCloseBrace:
+                popContext();
+                return true;
+            case JavaScriptLexer.Colon:
+                if (lastTokenIs(JavaScriptLexer.Class)) {
+                    pendingClass = 0; // a property named class
+                }
+                return true;
+            case JavaScriptLexer.Class:
+                if (lastTokenIs(JavaScriptLexer.Dot)) {
+                    return false;
+                }
+                pendingClass = contexts.size() + 1;
+                return true;
+            case JavaScriptLexer.Async:
+                // x = async function () {} / 2
+                pendingFunction = expressionExpected() ? contexts.size() + 1 : 0;
+                return false;
+            case JavaScriptLexer.Function_:
+                if (lastTokenIs(JavaScriptLexer.Dot)) {
+                    return false;
+                }
+                if (!lastTokenIs(JavaScriptLexer.Async)) {
+                    pendingFunction = expressionExpected() ? contexts.size() + 1 : 0;
+                }
+                return true;
             case JavaScriptLexer.Identifier:
             case JavaScriptLexer.NullLiteral:
             case JavaScriptLexer.BooleanLiteral:
             case JavaScriptLexer.This:
-            case JavaScriptLexer.CloseBracket:
-            case JavaScriptLexer.CloseParen:
+            case JavaScriptLexer.Super:
+            case JavaScriptLexer.As:
+            case JavaScriptLexer.From:
+            case JavaScriptLexer.NonStrictLet:
             case JavaScriptLexer.OctalIntegerLiteral:
             case JavaScriptLexer.DecimalLiteral:
             case JavaScriptLexer.HexIntegerLiteral:
+            case JavaScriptLexer.OctalIntegerLiteral2:
+            case JavaScriptLexer.BinaryIntegerLiteral:
+            case JavaScriptLexer.BigHexIntegerLiteral:
+            case JavaScriptLexer.BigOctalIntegerLiteral:
+            case JavaScriptLexer.BigBinaryIntegerLiteral:
+            case JavaScriptLexer.BigDecimalIntegerLiteral:
             case JavaScriptLexer.StringLiteral:
+            case JavaScriptLexer.RegularExpressionLiteral:
+            case JavaScriptLexer.BackTick:
             case JavaScriptLexer.PlusPlus:
             case JavaScriptLexer.MinusMinus:
                 // After any of the tokens above, no regex literal can follow.
                 return false;
-            default:
-                // In all other cases, a regex literal _is_ possible.
+        }
+        if (next.getType() >= JavaScriptLexer.Break && next.getType() <= JavaScriptLexer.Yield) {
+            // return /re/, typeof /x/, case /y/: but a.return / 2
+            return !lastTokenIs(JavaScriptLexer.Dot);
+        }
+        // In all other cases, a regex literal _is_ possible.
+        return true;
+    }
+
+    /**
+     * Returns {@code true} if the last token starts a statement with a
+     * parenthesised header: if, while, for, for await, with, switch or a
+     * catch clause.
+     */
+    private boolean isHeaderKeyword() {
+        if (beforeLastToken != null && beforeLastToken.getType() == JavaScriptLexer.Dot) {
+            return false;
+        }
+        if (lastTokenIs(JavaScriptLexer.Await)) {
+            return beforeLastToken != null && beforeLastToken.getType() == JavaScriptLexer.For;
+        }
+        return lastTokenIs(JavaScriptLexer.If) || lastTokenIs(JavaScriptLexer.While)
+            || lastTokenIs(JavaScriptLexer.For) || lastTokenIs(JavaScriptLexer.With)
+            || lastTokenIs(JavaScriptLexer.Switch) || lastTokenIs(JavaScriptLexer.Catch);
+    }
+
+    /**
+     * Returns the context of a { following the last token: a block where
+     * a statement can start, a function body after parameters, the body of
+     * a pending class, an object literal where only an expression can.
+     */
+    private int braceContext() {
+        if (pendingClass == contexts.size() + 1) {
+            pendingClass = 0;
+            return CLASS;
+        }
+        if (lastToken == null) {
+            return BLOCK;
+        }
+        switch (lastToken.getType()) {
+            case JavaScriptLexer.CloseParen:
+                // if (x) { but function (x) {
+                return closedParen.kind == HEADER ? BLOCK : FUNCTION;
+            case JavaScriptLexer.ARROW:
+                return FUNCTION;
+        }
+        return expressionExpected() ? OBJECT : BLOCK;
+    }
+
+    /**
+     * Returns {@code true} if an expression, but not a statement, can start
+     * after the last token.
+     */
+    private boolean expressionExpected() {
+        if (lastToken == null) {
+            return false;
+        }
+        switch (lastToken.getType()) {
+            case JavaScriptLexer.CloseParen:
+                // if (x) statement, or f()\nstatement
+                return false;
+            case JavaScriptLexer.ARROW:
                 return true;
+            case JavaScriptLexer.SemiColon:
+            case JavaScriptLexer.OpenBrace:
+            case JavaScriptLexer.CloseBrace:
+            case JavaScriptLexer.Else:
+            case JavaScriptLexer.Do:
+            case JavaScriptLexer.Try:
+            case JavaScriptLexer.Catch:
+            case JavaScriptLexer.Finally:
+            case JavaScriptLexer.Static:
+                return false;
+            case JavaScriptLexer.Colon:
+                // A label or case in a block, a property value or the
+                // alternative of a conditional in an expression
+                return !contexts.isEmpty() && !isBlockLike(contexts.peek().kind);
         }
+        return regexPossible;
+    }
+
+    /**
+     * Returns {@code true} if statements can start in a context.
+     */
+    private static boolean isBlockLike(int kind) {
+        return kind == BLOCK || isFunctionBody(kind) || kind == CLASS;
+    }
+
+    /**
+     * Returns {@code true} if a context is the body of a function.
+     */
+    private static boolean isFunctionBody(int kind) {
+        return kind == FUNCTION || kind == FUNCTION_EXPRESSION;
+    }
+
+    private void pushContext(int kind) {
+        contexts.push(new Context(kind, useStrictCurrent));
+    }
+
+    /**
+     * Pops the innermost context. Unbalanced closing brackets are taken to
+     * close a block of the strict mode by default.
+     */
+    private Context popContext() {
+        if (contexts.isEmpty()) {
+            return new Context(BLOCK, useStrictDefault);
+        }
+        if (pendingClass > contexts.size()) {
+            pendingClass = 0; // class keyword of a closed context
+        }
+        if (pendingFunction > contexts.size()) {
+            pendingFunction = 0;
+        }
+        return contexts.pop();
+    }
+
+    private boolean lastTokenIs(int type) {
+        return lastToken != null && lastToken.getType() == type;
     }
 }
@@ -1,15 +1,55 @@
 import antlr4 from 'antlr4';
 import JavaScriptLexer from './JavaScriptLexer.js';
 
+// Kinds of contexts, which tell whether the closing bracket ends an
+// expression and whether a } ends a template substitution. A context
+// also records the strict mode of the code around it, restored when a
+// brace closes.
+const PAREN = 0;               // ( of an expression or parameter list
+const HEADER = 1;              // ( of an if, while, for, with, switch or catch header
+const BRACKET = 2;             // [
+const BLOCK = 3;               // { of a block
+const FUNCTION = 4;            // { of a function declaration, method or arrow function body
+const FUNCTION_EXPRESSION = 5; // { of a function expression body
+const CLASS = 6;               // { of a class body
+const OBJECT = 7;              // { of an object literal or pattern
+const TEMPLATE = 8;            // ` of a template string
+const SUBSTITUTION = 9;        // ${ of a template string
+
+// States of the directive prologue: at its start or after a ;, after a
+// string literal which may be a directive, and past the prologue.
+const PROLOGUE_START = 0;
+const PROLOGUE_DIRECTIVE = 1;
+const PROLOGUE_NONE = 2;
+
+// Statements can start in a block, a function or a class body.
+function isBlockLike(kind) {
+    return kind === BLOCK || isFunctionBody(kind) || kind === CLASS;
+}
+
+function isFunctionBody(kind) {
+    return kind === FUNCTION || kind === FUNCTION_EXPRESSION;
+}
+
 export default class JavaScriptLexerBase extends antlr4.Lexer {
 
     constructor(input) {
         super(input);
-        this.scopeStrictModes = new Array();
         this.lastToken = null;
         this.useStrictDefault = false;
         this.useStrictCurrent = false;
-        this.templateDepth = 0;
+        this.contexts = [];
+        this.closedBrace = null;
+        this.closedParen = null;
+        this.beforeLastToken = null;
+        this.regexPossible = true;
+        // The depth of the contexts plus one where a class keyword or the
+        // function keyword of a function expression waits for its body, 0
+        // if none, and the directive prologue state.
+        this.pendingClass = 0;
+        this.pendingFunction = 0;
+        this.prologue = PROLOGUE_START;
+        this.strictBeforeDirective = false;
     }
 
     getStrictDefault() {
@@ -26,7 +66,7 @@ export default class JavaScriptLexerBase extends antlr4.Lexer {
     }
 
     IsInTemplateString() {
-        return this.templateDepth > 0;
+        return this.contexts.length > 0 && this.contexts[this.contexts.length - 1].kind === SUBSTITUTION;
     }
 
     getCurrentToken() {
@@ -37,69 +77,253 @@ export default class JavaScriptLexerBase extends antlr4.Lexer {
         var next = super.nextToken();
 
         if (next.channel === antlr4.Token.DEFAULT_CHANNEL) {
+            this.regexPossible = this.regexCanFollow(next);
+            this.updatePrologue(next);
+            this.beforeLastToken = this.lastToken;
             this.lastToken = next;
         }
         return next;
     }
 
     ProcessOpenBrace() {
-        this.useStrictCurrent =
-            this.scopeStrictModes.length > 0 && this.scopeStrictModes[this.scopeStrictModes.length - 1]
-                ? true
-                : this.useStrictDefault;
-        this.scopeStrictModes.push(this.useStrictCurrent);
+        let kind = this.braceContext();
+        if (kind === FUNCTION && this.pendingFunction === this.contexts.length + 1) {
+            this.pendingFunction = 0;
+            if (this.lastTokenIs(JavaScriptLexer.CloseParen)) {
+                kind = FUNCTION_EXPRESSION; // not the body of async x => {
+            }
+        }
+        this.pushContext(kind);
+        if (kind === CLASS) {
+            // A class body is strict mode code.
+            this.useStrictCurrent = true;
+        }
     }
 
     ProcessCloseBrace() {
-        this.useStrictCurrent =
-            this.scopeStrictModes.length > 0
-                ? this.scopeStrictModes.pop()
-                : this.useStrictDefault;
+        this.closedBrace = this.popContext();
+        this.useStrictCurrent = this.closedBrace.strict;
     }
 
+    // A "use strict" directive in the prologue of the program or of a
+    // function body makes the code strict, until updatePrologue finds the
+    // string is part of an expression.
     ProcessStringLiteral() {
-        if (this.lastToken === null ||
-                this.lastToken.type === JavaScriptLexer.OpenBrace) {
+        // "use strict" may follow a directive without ; on a new line.
+        if (this.prologue === PROLOGUE_START ||
+                this.prologue === PROLOGUE_DIRECTIVE && this.lastToken.line < this.line) {
+            this.strictBeforeDirective = this.useStrictCurrent;
             if (super.text === '"use strict"' || super.text === "'use strict'") {
-                if (this.scopeStrictModes.length > 0) {
-                    this.scopeStrictModes.pop();
-                }
                 this.useStrictCurrent = true;
-                this.scopeStrictModes.push(this.useStrictCurrent);
             }
         }
     }
 
+    // A string literal is a directive if a ; or a line break ends its
+    // statement, as in "use strict"; but not "use strict".length.
+    updatePrologue(next) {
+        if (this.prologue === PROLOGUE_START) {
+            if (next.type === JavaScriptLexer.StringLiteral) {
+                this.prologue = PROLOGUE_DIRECTIVE;
+            } else if (next.type !== JavaScriptLexer.HashBangLine) {
+                this.prologue = PROLOGUE_NONE;
+            }
+        } else if (this.prologue === PROLOGUE_DIRECTIVE) {
+            if (next.type === JavaScriptLexer.SemiColon) {
+                this.prologue = PROLOGUE_START;
+            } else if (next.line > this.lastToken.line) {
+                if (next.type !== JavaScriptLexer.StringLiteral) {
+                    this.prologue = PROLOGUE_NONE;
+                }
+            } else if (next.type === JavaScriptLexer.CloseBrace || next.type === antlr4.Token.EOF) {
+                this.prologue = PROLOGUE_NONE;
+            } else {
+                this.useStrictCurrent = this.strictBeforeDirective;
+                this.prologue = PROLOGUE_NONE;
+            }
+        }
+        if (next.type === JavaScriptLexer.OpenBrace &&
+                isFunctionBody(this.contexts[this.contexts.length - 1].kind)) {
+            // Pushed by ProcessOpenBrace
+            this.prologue = PROLOGUE_START;
+        }
+    }
+
     IncreaseTemplateDepth() {
-        this.templateDepth++;
+        this.pushContext(TEMPLATE);
     }
 
     DecreaseTemplateDepth() {
-        this.templateDepth--;
+        this.popContext();
     }
 
     IsRegexPossible() {
-        if (this.lastToken === null) {
-            return true;
-        }
+        return this.lastToken === null || this.regexPossible;
+    }
 
-        switch (this.lastToken.type) {
+    regexCanFollow(next) {
+        switch (next.type) {
+            case JavaScriptLexer.OpenParen:
+                if (this.lastTokenIs(JavaScriptLexer.Class)) {
+                    this.pendingClass = 0; // a method named class
+                }
+                this.pushContext(this.isHeaderKeyword() ? HEADER : PAREN);
+                return true;
+            case JavaScriptLexer.OpenBracket:
+                this.pushContext(BRACKET);
+                return true;
+            case JavaScriptLexer.TemplateStringStartExpression:
+                this.pushContext(SUBSTITUTION);
+                return true;
+            case JavaScriptLexer.CloseParen:
+                // if (x) /re/.test(s) but (x) / 2
+                this.closedParen = this.popContext();
+                return this.closedParen.kind === HEADER;
+            case JavaScriptLexer.CloseBrace:
+                // {} /re/.test(s) but ({}) / 2 and x = function () {} / 2,
+                // popped by ProcessCloseBrace
+                return this.closedBrace.kind !== OBJECT && this.closedBrace.kind !== FUNCTION_EXPRESSION;
+            case JavaScriptLexer.CloseBracket:
+                this.popContext();
+                return false;
+            case JavaScriptLexer.TemplateCloseBrace:
+                this.popContext();
+                return true;
+            case JavaScriptLexer.Colon:
+                if (this.lastTokenIs(JavaScriptLexer.Class)) {
+                    this.pendingClass = 0; // a property named class
+                }
+                return true;
+            case JavaScriptLexer.Class:
+                if (this.lastTokenIs(JavaScriptLexer.Dot)) {
+                    return false;
+                }
+                this.pendingClass = this.contexts.length + 1;
+                return true;
+            case JavaScriptLexer.Async:
+                // x = async function () {} / 2
+                this.pendingFunction = this.expressionExpected() ? this.contexts.length + 1 : 0;
+                return false;
+            case JavaScriptLexer.Function_:
+                if (this.lastTokenIs(JavaScriptLexer.Dot)) {
+                    return false;
+                }
+                if (!this.lastTokenIs(JavaScriptLexer.Async)) {
+                    this.pendingFunction = this.expressionExpected() ? this.contexts.length + 1 : 0;
+                }
+                return true;
             case JavaScriptLexer.Identifier:
             case JavaScriptLexer.NullLiteral:
             case JavaScriptLexer.BooleanLiteral:
             case JavaScriptLexer.This:
-            case JavaScriptLexer.CloseBracket:
-            case JavaScriptLexer.CloseParen:
+            case JavaScriptLexer.Super:
+            case JavaScriptLexer.As:
+            case JavaScriptLexer.From:
+            case JavaScriptLexer.NonStrictLet:
             case JavaScriptLexer.OctalIntegerLiteral:
             case JavaScriptLexer.DecimalLiteral:
             case JavaScriptLexer.HexIntegerLiteral:
+            case JavaScriptLexer.OctalIntegerLiteral2:
+            case JavaScriptLexer.BinaryIntegerLiteral:
+            case JavaScriptLexer.BigHexIntegerLiteral:
+            case JavaScriptLexer.BigOctalIntegerLiteral:
+            case JavaScriptLexer.BigBinaryIntegerLiteral:
+            case JavaScriptLexer.BigDecimalIntegerLiteral:
             case JavaScriptLexer.StringLiteral:
+            case JavaScriptLexer.RegularExpressionLiteral:
+            case JavaScriptLexer.BackTick:
             case JavaScriptLexer.PlusPlus:
             case JavaScriptLexer.MinusMinus:
                 return false;
-            default:
+        }
+        if (next.type >= JavaScriptLexer.Break && next.type <= JavaScriptLexer.Yield) {
+            // return /re/, typeof /x/, case /y/: but a.return / 2
+            return !this.lastTokenIs(JavaScriptLexer.Dot);
+        }
+        return true;
+    }
+
+    isHeaderKeyword() {
+        if (this.beforeLastToken !== null && this.beforeLastToken.type === JavaScriptLexer.Dot) {
+            return false;
+        }
+        if (this.lastTokenIs(JavaScriptLexer.Await)) {
+            return this.beforeLastToken !== null && this.beforeLastToken.type === JavaScriptLexer.For;
+        }
+        return this.lastTokenIs(JavaScriptLexer.If) || this.lastTokenIs(JavaScriptLexer.While) ||
+            this.lastTokenIs(JavaScriptLexer.For) || this.lastTokenIs(JavaScriptLexer.With) ||
+            this.lastTokenIs(JavaScriptLexer.Switch) || this.lastTokenIs(JavaScriptLexer.Catch);
+    }
+
+    braceContext() {
+        if (this.pendingClass === this.contexts.length + 1) {
+            this.pendingClass = 0;
+            return CLASS;
+        }
+        if (this.lastToken === null) {
+            return BLOCK;
+        }
+        switch (this.lastToken.type) {
+            case JavaScriptLexer.CloseParen:
+                // if (x) { but function (x) {
+                return this.closedParen.kind === HEADER ? BLOCK : FUNCTION;
+            case JavaScriptLexer.ARROW:
+                return FUNCTION;
+        }
+        return this.expressionExpected() ? OBJECT : BLOCK;
+    }
+
+    // Returns true if an expression, but not a statement, can start after
+    // the last token.
+    expressionExpected() {
+        if (this.lastToken === null) {
+            return false;
+        }
+        switch (this.lastToken.type) {
+            case JavaScriptLexer.CloseParen:
+                // if (x) statement, or f()\nstatement
+                return false;
+            case JavaScriptLexer.ARROW:
                 return true;
+            case JavaScriptLexer.SemiColon:
+            case JavaScriptLexer.OpenBrace:
+            case JavaScriptLexer.CloseBrace:
+            case JavaScriptLexer.Else:
+            case JavaScriptLexer.Do:
+            case JavaScriptLexer.Try:
+            case JavaScriptLexer.Catch:
+            case JavaScriptLexer.Finally:
+            case JavaScriptLexer.Static:
+                return false;
+            case JavaScriptLexer.Colon:
+                // A label or case in a block, a property value or the
+                // alternative of a conditional in an expression
+                return this.contexts.length > 0 && !isBlockLike(this.contexts[this.contexts.length - 1].kind);
         }
+        return this.regexPossible;
+    }
+
+    pushContext(kind) {
+        this.contexts.push({kind: kind, strict: this.useStrictCurrent});
+    }
+
+    // Unbalanced closing brackets are taken to close a block of the
+    // strict mode by default.
+    popContext() {
+        if (this.contexts.length === 0) {
+            return {kind: BLOCK, strict: this.useStrictDefault};
+        }
+        if (this.pendingClass > this.contexts.length) {
+            this.pendingClass = 0; // class keyword of a closed context
+        }
+        if (this.pendingFunction > this.contexts.length) {
+            this.pendingFunction = 0;
+        }
+        return this.contexts.pop();
+    }
+
+    lastTokenIs(type) {
+        return this.lastToken !== null && this.lastToken.type === type;
     }
 
     IsStartOfFile() {
@@ -5,15 +5,33 @@ relativeImport = False
 if __name__ is not None and "." in __name__:
     relativeImport = True
 
+# Kinds of contexts, which tell whether the closing bracket ends an
+# expression and whether a } ends a template substitution. A context is a
+# (kind, strict) tuple, strict being the strict mode of the code around it,
+# restored when a brace closes.
+PAREN = 0                # ( of an expression or parameter list
+HEADER = 1               # ( of an if, while, for, with, switch or catch header
+BRACKET = 2              # [
+BLOCK = 3                # { of a block
+FUNCTION = 4             # { of a function declaration, method or arrow function body
+FUNCTION_EXPRESSION = 5  # { of a function expression body
+CLASS = 6                # { of a class body
+OBJECT = 7               # { of an object literal or pattern
+TEMPLATE = 8             # ` of a template string
+SUBSTITUTION = 9         # ${ of a template string
+FUNCTION_BODIES = [FUNCTION, FUNCTION_EXPRESSION]
+
+# States of the directive prologue: at its start or after a ;, after a
+# string literal which may be a directive, and past the prologue.
+PROLOGUE_START = 0
+PROLOGUE_DIRECTIVE = 1
+PROLOGUE_NONE = 2
+
 class JavaScriptLexerBase(Lexer):
     def __init__(self, *args, **kwargs):
         super(JavaScriptLexerBase, self).__init__(*args, **kwargs)
 
-        """Stores values of nested modes. By default mode is strict or
-        defined externally (useStrictDefault)"""
-        self.scopeStrictModes = []
         self.lastToken: Token = None
-        self.templateDepth = 0
 
         """Default value of strict mode
         Can be defined externally by setUseStrictDefault"""
@@ -23,6 +41,24 @@ class JavaScriptLexerBase(Lexer):
         Can be defined during parsing, see StringFunctions.js and StringGlobal.js samples"""
         self.useStrictCurrent = False
 
+        """The open brackets, braces and template strings, innermost last, the
+        token before lastToken and whether a regex literal can follow
+        lastToken, see IsRegexPossible"""
+        self.contexts = []
+        self.closedBrace = (BLOCK, False)
+        self.closedParen = (PAREN, False)
+        self.beforeLastToken: Token = None
+        self.regexPossible = True
+
+        """The depth of the contexts plus one where a class keyword or the
+        function keyword of a function expression waits for its body, 0 if
+        none, and the state of the directive prologue of the program or
+        function body being lexed, see ProcessStringLiteral"""
+        self.pendingClass = 0
+        self.pendingFunction = 0
+        self.prologue = PROLOGUE_START
+        self.strictBeforeDirective = False
+
     def IsStartOfFile(self) -> bool:
         return self.lastToken == null
     
@@ -37,7 +73,9 @@ class JavaScriptLexerBase(Lexer):
         return self.useStrictCurrent
 
     def IsInTemplateString(self) -> bool:
-        return self.templateDepth > 0;
+        """A } closes a substitution of a template string, rather than a brace
+        opened inside it as in `a${ {b: 1}.b }c`."""
+        return bool(self.contexts) and self.contexts[-1][0] == SUBSTITUTION
 
     def IsStartOfFile(self):
         return self.lastToken is None
@@ -46,68 +84,262 @@ class JavaScriptLexerBase(Lexer):
         """Return the next token from the character stream and records this last
         token in case it resides on the default channel. This recorded token
         is used to determine when the lexer could possibly match a regex
-        literal. Also changes scopeStrictModes stack if tokenize special
-        string 'use strict';
+        literal. Also keeps the stack of open brackets up to date.
 
         :return the next token from the character stream."""
         next_token: Token = super(JavaScriptLexerBase, self).nextToken()
 
         if next_token.channel == Token.DEFAULT_CHANNEL:
+            self.regexPossible = self.regexCanFollow(next_token)
+            self.updatePrologue(next_token)
+            self.beforeLastToken = self.lastToken
             self.lastToken = next_token
 
         return next_token
 
     def ProcessOpenBrace(self):
-        self.useStrictCurrent = bool(self.scopeStrictModes) and (True if self.scopeStrictModes[-1] else self.useStrictDefault)
-        self.scopeStrictModes.append(self.useStrictCurrent)
+        if relativeImport:
+            from .JavaScriptLexer import JavaScriptLexer
+        else:
+            from JavaScriptLexer import JavaScriptLexer
+
+        kind = self.braceContext()
+        if kind == FUNCTION and self.pendingFunction == len(self.contexts) + 1:
+            self.pendingFunction = 0
+            if self.lastTokenIs(JavaScriptLexer.CloseParen):
+                kind = FUNCTION_EXPRESSION  # not the body of async x => {
+        self.pushContext(kind)
+        if kind == CLASS:
+            # A class body is strict mode code.
+            self.useStrictCurrent = True
 
     def ProcessCloseBrace(self):
-        self.useStrictCurrent = bool(self.scopeStrictModes) and (True if self.scopeStrictModes.pop(-1) else self.useStrictDefault)
+        self.closedBrace = self.popContext()
+        self.useStrictCurrent = self.closedBrace[1]
 
     def ProcessStringLiteral(self):
+        """A "use strict" directive in the prologue of the program or of a
+        function body makes the code strict, until updatePrologue finds the
+        string is part of an expression."""
+        # "use strict" may follow a directive without ; on a new line.
+        if self.prologue == PROLOGUE_START or (
+                self.prologue == PROLOGUE_DIRECTIVE and self.lastToken.line < self.line):
+            self.strictBeforeDirective = self.useStrictCurrent
+            text = self.text
+            if text == '"use strict"' or text == "'use strict'":
+                self.useStrictCurrent = True
+
+    def updatePrologue(self, next_token: Token):
+        """Follows the directive prologue with next_token, the new last token. A
+        string literal is a directive if a ; or a line break ends its
+        statement, as in "use strict"; but not "use strict".length."""
         if relativeImport:
             from .JavaScriptLexer import JavaScriptLexer
         else:
             from JavaScriptLexer import JavaScriptLexer
-        if not self.lastToken or self.lastToken.type == JavaScriptLexer.OpenBrace:
-            text = self.text
-            if text == '"use strict"' or text == "'use strict'":
-                if self.scopeStrictModes:
-                    self.scopeStrictModes.pop(-1)
-                self.useStrictCurrent = True
-                self.scopeStrictModes.append(self.useStrictCurrent)
+
+        t = next_token.type
+        if self.prologue == PROLOGUE_START:
+            if t == JavaScriptLexer.StringLiteral:
+                self.prologue = PROLOGUE_DIRECTIVE
+            elif t != JavaScriptLexer.HashBangLine:
+                self.prologue = PROLOGUE_NONE
+        elif self.prologue == PROLOGUE_DIRECTIVE:
+            if t == JavaScriptLexer.SemiColon:
+                self.prologue = PROLOGUE_START
+            elif next_token.line > self.lastToken.line:
+                if t != JavaScriptLexer.StringLiteral:
+                    self.prologue = PROLOGUE_NONE
+            elif t == JavaScriptLexer.CloseBrace or t == Token.EOF:
+                self.prologue = PROLOGUE_NONE
+            else:
+                self.useStrictCurrent = self.strictBeforeDirective
+                self.prologue = PROLOGUE_NONE
+        if t == JavaScriptLexer.OpenBrace and self.contexts[-1][0] in FUNCTION_BODIES:
+            # Pushed by ProcessOpenBrace
+            self.prologue = PROLOGUE_START
 
     def IncreaseTemplateDepth(self):
-        self.templateDepth = self.templateDepth + 1
+        self.pushContext(TEMPLATE)
 
     def DecreaseTemplateDepth(self):
-        self.templateDepth = self.templateDepth - 1
+        self.popContext()
 
     def IsRegexPossible(self) -> bool:
-        """Returns {@code true} if the lexer can match a regex literal. """
+        """Returns {@code true} if the lexer can match a regex literal, that is
+        if the syntactic grammar expects an expression after the last token."""
+        return not self.lastToken or self.regexPossible
+
+    def regexCanFollow(self, next_token: Token) -> bool:
+        """Returns {@code true} if a regex literal can follow next_token, the
+        new last token, and keeps the stack of contexts up to date for the
+        tokens without a lexer action."""
         if relativeImport:
             from .JavaScriptLexer import JavaScriptLexer
         else:
             from JavaScriptLexer import JavaScriptLexer
 
-        if not self.lastToken:
-            # No token has been produced yet: at the start of the input,
-            # no division is possible, so a regex literal _is_ possible.
+        t = next_token.type
+        if t == JavaScriptLexer.OpenParen:
+            if self.lastTokenIs(JavaScriptLexer.Class):
+                self.pendingClass = 0  # a method named class
+            self.pushContext(HEADER if self.isHeaderKeyword() else PAREN)
+            return True
+        if t == JavaScriptLexer.OpenBracket:
+            self.pushContext(BRACKET)
+            return True
+        if t == JavaScriptLexer.TemplateStringStartExpression:
+            self.pushContext(SUBSTITUTION)
+            return True
+        if t == JavaScriptLexer.CloseParen:
+            # if (x) /re/.test(s) but (x) / 2
+            self.closedParen = self.popContext()
+            return self.closedParen[0] == HEADER
+        if t == JavaScriptLexer.CloseBrace:
+            # {} /re/.test(s) but ({}) / 2 and x = function () {} / 2,
+            # popped by ProcessCloseBrace
+            return self.closedBrace[0] not in [OBJECT, FUNCTION_EXPRESSION]
+        if t == JavaScriptLexer.CloseBracket:
+            self.popContext()
+            return False
+        if t == JavaScriptLexer.TemplateCloseBrace:
+            self.popContext()
+            return True
+        if t == JavaScriptLexer.Colon:
+            if self.lastTokenIs(JavaScriptLexer.Class):
+                self.pendingClass = 0  # a property named class
+            return True
+        if t == JavaScriptLexer.Class:
+            if self.lastTokenIs(JavaScriptLexer.Dot):
+                return False
+            self.pendingClass = len(self.contexts) + 1
+            return True
+        if t == JavaScriptLexer.Async:
+            # x = async function () {} / 2
+            self.pendingFunction = len(self.contexts) + 1 if self.expressionExpected() else 0
+            return False
+        if t == JavaScriptLexer.Function_:
+            if self.lastTokenIs(JavaScriptLexer.Dot):
+                return False
+            if not self.lastTokenIs(JavaScriptLexer.Async):
+                self.pendingFunction = len(self.contexts) + 1 if self.expressionExpected() else 0
             return True
 
-        if self.lastToken.type in [
+        if t in [
                 JavaScriptLexer.Identifier,
                 JavaScriptLexer.NullLiteral,
                 JavaScriptLexer.BooleanLiteral,
                 JavaScriptLexer.This,
-                JavaScriptLexer.CloseBracket,
-                JavaScriptLexer.CloseParen,
+                JavaScriptLexer.Super,
+                JavaScriptLexer.As,
+                JavaScriptLexer.From,
+                JavaScriptLexer.NonStrictLet,
                 JavaScriptLexer.OctalIntegerLiteral,
                 JavaScriptLexer.DecimalLiteral,
                 JavaScriptLexer.HexIntegerLiteral,
+                JavaScriptLexer.OctalIntegerLiteral2,
+                JavaScriptLexer.BinaryIntegerLiteral,
+                JavaScriptLexer.BigHexIntegerLiteral,
+                JavaScriptLexer.BigOctalIntegerLiteral,
+                JavaScriptLexer.BigBinaryIntegerLiteral,
+                JavaScriptLexer.BigDecimalIntegerLiteral,
                 JavaScriptLexer.StringLiteral,
+                JavaScriptLexer.RegularExpressionLiteral,
+                JavaScriptLexer.BackTick,
                 JavaScriptLexer.PlusPlus,
                 JavaScriptLexer.MinusMinus]:
+            # After any of the tokens above, no regex literal can follow.
             return False
 
+        if JavaScriptLexer.Break <= t <= JavaScriptLexer.Yield:
+            # return /re/, typeof /x/, case /y/: but a.return / 2
+            return not self.lastTokenIs(JavaScriptLexer.Dot)
+
         return True
+
+    def isHeaderKeyword(self) -> bool:
+        """Returns {@code true} if the last token starts a statement with a
+        parenthesised header: if, while, for, for await, with, switch or a
+        catch clause."""
+        if relativeImport:
+            from .JavaScriptLexer import JavaScriptLexer
+        else:
+            from JavaScriptLexer import JavaScriptLexer
+
+        if self.beforeLastToken and self.beforeLastToken.type == JavaScriptLexer.Dot:
+            return False
+        if self.lastTokenIs(JavaScriptLexer.Await):
+            return bool(self.beforeLastToken) and self.beforeLastToken.type == JavaScriptLexer.For
+        return self.lastToken is not None and self.lastToken.type in [
+            JavaScriptLexer.If, JavaScriptLexer.While, JavaScriptLexer.For, JavaScriptLexer.With,
+            JavaScriptLexer.Switch, JavaScriptLexer.Catch]
+
+    def braceContext(self) -> int:
+        """Returns the context of a { following the last token: a block where
+        a statement can start, a function body after parameters, the body of
+        a pending class, an object literal where only an expression can."""
+        if relativeImport:
+            from .JavaScriptLexer import JavaScriptLexer
+        else:
+            from JavaScriptLexer import JavaScriptLexer
+
+        if self.pendingClass == len(self.contexts) + 1:
+            self.pendingClass = 0
+            return CLASS
+        if not self.lastToken:
+            return BLOCK
+        if self.lastToken.type == JavaScriptLexer.CloseParen:
+            # if (x) { but function (x) {
+            return BLOCK if self.closedParen[0] == HEADER else FUNCTION
+        if self.lastToken.type == JavaScriptLexer.ARROW:
+            return FUNCTION
+        return OBJECT if self.expressionExpected() else BLOCK
+
+    def expressionExpected(self) -> bool:
+        """Returns {@code true} if an expression, but not a statement, can
+        start after the last token."""
+        if relativeImport:
+            from .JavaScriptLexer import JavaScriptLexer
+        else:
+            from JavaScriptLexer import JavaScriptLexer
+
+        if not self.lastToken:
+            return False
+        if self.lastToken.type == JavaScriptLexer.CloseParen:
+            # if (x) statement, or f()\nstatement
+            return False
+        if self.lastToken.type == JavaScriptLexer.ARROW:
+            return True
+        if self.lastToken.type in [
+                JavaScriptLexer.SemiColon,
+                JavaScriptLexer.OpenBrace,
+                JavaScriptLexer.CloseBrace,
+                JavaScriptLexer.Else,
+                JavaScriptLexer.Do,
+                JavaScriptLexer.Try,
+                JavaScriptLexer.Catch,
+                JavaScriptLexer.Finally,
+                JavaScriptLexer.Static]:
+            return False
+        if self.lastToken.type == JavaScriptLexer.Colon:
+            # A label or case in a block, a property value or the
+            # alternative of a conditional in an expression
+            return bool(self.contexts) and self.contexts[-1][0] not in [BLOCK, CLASS] + FUNCTION_BODIES
+        return self.regexPossible
+
+    def pushContext(self, kind: int):
+        self.contexts.append((kind, self.useStrictCurrent))
+
+    def popContext(self) -> tuple:
+        """Unbalanced closing brackets are taken to close a block of the strict
+        mode by default."""
+        if not self.contexts:
+            return (BLOCK, self.useStrictDefault)
+        if self.pendingClass > len(self.contexts):
+            self.pendingClass = 0  # class keyword of a closed context
+        if self.pendingFunction > len(self.contexts):
+            self.pendingFunction = 0
+        return self.contexts.pop(-1)
+
+    def lastTokenIs(self, type: int) -> bool:
+        return self.lastToken is not None and self.lastToken.type == type
@@ -0,0 +1,54 @@
+//------------------------------------------------------------------------------
+// Directive prologues and strict mode code
+// A "use strict" directive applies to the program or the function body whose
+//   prologue, the string literal statements at its start, contains it. Class
+//   bodies are always strict. 010 is a legacy octal literal and let an
+//   identifier only outside of strict mode code.
+// https://tc39.es/ecma262/#sec-directive-prologues-and-the-use-strict-directive
+//------------------------------------------------------------------------------
+
+// Not in a prologue: a block is not a function body
+if (true) {
+    "use strict";
+    var octal = 010;
+}
+
+// Not a directive: the string is part of an expression
+function expression() {
+    "use strict".length;
+    var let = 010;
+    return let;
+}
+
+// After another directive, with or without a semicolon
+function directives() {
+    "use asm";
+    'use strict';
+    return 0o10;
+}
+function lines() {
+    "a directive"
+    "use strict"
+    return 0o10;
+}
+var let = 010;
+
+// Arrow functions and methods
+var arrow = () => { "use strict"; return 0o10; };
+var object = {
+    method() {
+        "use strict";
+        return 0o10;
+    },
+    get octal() {
+        return 010;
+    }
+};
+
+// A class body is strict, the code after it is not
+class Strict {
+    static method() {
+        return 0o10;
+    }
+}
+var after = let + 010;
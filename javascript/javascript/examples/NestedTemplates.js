@@ -0,0 +1,24 @@
+//------------------------------------------------------------------------------
+// Nested template literals and braces
+// A } closes the innermost brace: a ${ substitution of a template string, or
+//   an object literal, block or function body opened inside it.
+//------------------------------------------------------------------------------
+
+var d = 1
+var s = `a${ {b:`c${d}`}.b }e`
+var t = `a${ `b${ `c${d}` }` }e`
+var u = `x${ [1, 2].map(function (n) { return `${n}`; }) }y`
+var v = `x${ (() => { return {d}; })().d }y${ {} }z`
+
+//------------------------------------------------------------------------------
+// Strict mode of a function body
+// A "use strict" directive applies to the function and not to the code
+//   after it, where 010 is a legacy octal literal and let an identifier.
+//------------------------------------------------------------------------------
+
+function strict() {
+    "use strict";
+    return `${ {let: 1}.let }`;
+}
+var octal = 010
+var let = `${octal}`
@@ -0,0 +1,59 @@
+//------------------------------------------------------------------------------
+// Regular expression literal or division
+// A / starts a regular expression where the syntactic grammar expects an
+//   expression, and is a division operator after one.
+// https://tc39.es/ecma262/#sec-ecmascript-language-lexical-grammar
+//------------------------------------------------------------------------------
+
+// After keywords
+function keywords(s) {
+    if (typeof /x/ !== "object") {
+        void /v/;
+    }
+    switch (s) {
+        case /y/:
+            break;
+        default:
+            return /re/.test(s);
+    }
+    return /re/;
+}
+
+// After the parenthesised header of a statement
+function headers(s, x, a) {
+    if (x) /re/.test(s);
+    if (x) /re/g.exec(s); else /re/i.exec(s);
+    while (x) /re/.test(s) && (x = null);
+    for (var i = 0; i < 1; i++) /i/.test(s);
+    for (var k in a) /k/.test(k);
+    with (Math) /w/.test(s);
+}
+
+// After a block
+function blocks(s, x) {
+    {
+    }
+    /re/.test(s);
+    label: {
+        break label;
+    }
+    /re/.test(s);
+    if (x) {
+    } else {
+    }
+    /re/g.exec(s)
+}
+
+// Divisions after expressions ending with a parenthesis, a brace, a
+// keyword used as a property name or a template
+function divisions(x, y, a) {
+    var r = (x + y) / 2 / (x - y);
+    var f = a.if(x) / 2 / y;
+    var m = x.return / 2 / y.typeof;
+    var o = {valueOf: function () { return 2; }} / 2;
+    var g = function () { return 4; } / x / 2;
+    var h = async function () {} / x / 2;
+    var p = {a: {b: 4}} / x / 2;
+    var t = `${x}` / y / 2;
+    return [x][0] / y / 2;
+}
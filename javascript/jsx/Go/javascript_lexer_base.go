@@ -0,0 +1,275 @@
+package parser
+
+import (
+	"unicode"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// Kinds of JSX contexts, innermost last. The lexer is in JSX from the
+// < opening an element to the > closing it and its children.
+const (
+	jsxTag        = iota // <name attributes of an opening tag
+	jsxVoidTag           // the opening tag of a void element such as <br>
+	jsxClosingTag        // </name of a closing tag, or after the / of />
+	jsxChildren          // text and elements between the tags
+	jsxExpression        // { of an attribute value or a child
+	jsxBrace             // { inside an expression
+)
+
+// JavaScriptLexerBase state
+type JavaScriptLexerBase struct {
+	*antlr.BaseLexer
+
+	// The strict modes of the nested scopes, innermost last. By
+	// default the mode is strict if defined externally, see
+	// SetUseStrictDefault.
+	scopeStrictModes []bool
+
+	lastToken        antlr.Token
+	useStrictDefault bool
+	useStrictCurrent bool
+
+	// The depth of nested template string backticks, which tells
+	// whether a } closes an expression inside a template string.
+	templateDepth int
+
+	// The JSX contexts, empty outside of JSX, and whether the last
+	// token closes an element, after which no regex literal can follow.
+	jsxContexts []int
+	jsxEnd      bool
+}
+
+// IsStartOfFile returns true if no token has been produced yet.
+func (l *JavaScriptLexerBase) IsStartOfFile() bool {
+	return l.lastToken == nil
+}
+
+// GetStrictDefault returns the strict mode by default.
+func (l *JavaScriptLexerBase) GetStrictDefault() bool {
+	return l.useStrictDefault
+}
+
+// SetUseStrictDefault sets the strict mode by default, true for an
+// ES module. Call it before lexing.
+func (l *JavaScriptLexerBase) SetUseStrictDefault(value bool) {
+	l.useStrictDefault = value
+	l.useStrictCurrent = value
+}
+
+// IsStrictMode is self explanatory.
+func (l *JavaScriptLexerBase) IsStrictMode() bool {
+	return l.useStrictCurrent
+}
+
+// IsInTemplateString returns true if a } closes an expression inside
+// a template string.
+func (l *JavaScriptLexerBase) IsInTemplateString() bool {
+	return l.templateDepth > 0
+}
+
+// NextToken from the character stream. Between the tags of a JSX
+// element, the text up to the next < or { is a single JsxText token,
+// on the hidden channel if it is only white space.
+func (l *JavaScriptLexerBase) NextToken() antlr.Token {
+	next := l.jsxText()
+	if next == nil {
+		next = l.BaseLexer.NextToken() // Get next token
+	}
+	if next.GetChannel() == antlr.TokenDefaultChannel {
+		l.updateJsx(next)
+		// Keep track of the last token on default channel
+		l.lastToken = next
+	}
+	return next
+}
+
+// ProcessOpenBrace is called when a { is encountered during
+// lexing, we push a new scope everytime.
+func (l *JavaScriptLexerBase) ProcessOpenBrace() {
+	l.useStrictCurrent = l.useStrictDefault
+	if n := len(l.scopeStrictModes); n > 0 && l.scopeStrictModes[n-1] {
+		l.useStrictCurrent = true
+	}
+	l.scopeStrictModes = append(l.scopeStrictModes, l.useStrictCurrent)
+}
+
+// ProcessCloseBrace is called when a } is encountered during
+// lexing, we pop a scope unless we're inside global scope.
+func (l *JavaScriptLexerBase) ProcessCloseBrace() {
+	l.useStrictCurrent = l.useStrictDefault
+	if n := len(l.scopeStrictModes); n > 0 {
+		l.useStrictCurrent = l.scopeStrictModes[n-1]
+		l.scopeStrictModes = l.scopeStrictModes[:n-1]
+	}
+}
+
+// ProcessStringLiteral is called when lexing a string literal. A
+// "use strict" at the start of the input or of a scope makes the
+// scope strict.
+func (l *JavaScriptLexerBase) ProcessStringLiteral() {
+	if l.lastToken == nil || l.lastToken.GetTokenType() == JavaScriptLexerOpenBrace {
+		if l.GetText() == `"use strict"` || l.GetText() == "'use strict'" {
+			if n := len(l.scopeStrictModes); n > 0 {
+				l.scopeStrictModes = l.scopeStrictModes[:n-1]
+			}
+			l.useStrictCurrent = true
+			l.scopeStrictModes = append(l.scopeStrictModes, l.useStrictCurrent)
+		}
+	}
+}
+
+// IncreaseTemplateDepth is called on the backtick opening a template
+// string.
+func (l *JavaScriptLexerBase) IncreaseTemplateDepth() {
+	l.templateDepth++
+}
+
+// DecreaseTemplateDepth is called on the backtick closing a template
+// string.
+func (l *JavaScriptLexerBase) DecreaseTemplateDepth() {
+	l.templateDepth--
+}
+
+// IsRegexPossible returns true if the lexer can match a
+// regex literal.
+func (l *JavaScriptLexerBase) IsRegexPossible() bool {
+	if kind := l.jsxContext(); kind == jsxTag || kind == jsxVoidTag || kind == jsxClosingTag {
+		// The / of </a> or <a/> in a tag.
+		return false
+	}
+	if l.jsxEnd {
+		// <a/> / 2
+		return false
+	}
+	if l.lastToken == nil {
+		// No token has been produced yet: at the start of the input,
+		// no division is possible, so a regex literal _is_ possible.
+		return true
+	}
+	switch l.lastToken.GetTokenType() {
+	case JavaScriptLexerIdentifier, JavaScriptLexerNullLiteral,
+		JavaScriptLexerBooleanLiteral, JavaScriptLexerThis,
+		JavaScriptLexerCloseBracket, JavaScriptLexerCloseParen,
+		JavaScriptLexerOctalIntegerLiteral, JavaScriptLexerDecimalLiteral,
+		JavaScriptLexerHexIntegerLiteral, JavaScriptLexerStringLiteral,
+		JavaScriptLexerPlusPlus, JavaScriptLexerMinusMinus:
+		// After any of the tokens above, no regex literal can follow.
+		return false
+	default:
+		// In all other cases, a regex literal _is_ possible.
+		return true
+	}
+}
+
+// jsxText matches the text between the tags of a JSX element, nil if
+// the lexer is not there or a < or { follows.
+func (l *JavaScriptLexerBase) jsxText() antlr.Token {
+	if l.jsxContext() != jsxChildren {
+		return nil
+	}
+	input := l.GetInputStream()
+	start := input.Index()
+	line, column := l.GetLine(), l.GetCharPositionInLine()
+	channel := antlr.LexerHidden
+	for c := input.LA(1); c != '<' && c != '{' && c != antlr.TokenEOF; c = input.LA(1) {
+		if !unicode.IsSpace(rune(c)) {
+			channel = antlr.TokenDefaultChannel
+		}
+		l.Interpreter.Consume(input)
+	}
+	if input.Index() == start {
+		return nil
+	}
+	return l.GetTokenFactory().Create(l.GetTokenSourceCharStreamPair(), JavaScriptLexerJsxText, "",
+		channel, start, input.Index()-1, line, column)
+}
+
+// updateJsx follows the JSX elements with the tokens of the default
+// channel. A < opens an element where an expression can start, as in
+// return <a/> but not a <b, and among the children of an element.
+// The > of the opening tag of a void element such as <br> ends the
+// element, unless its closing tag follows as in <br></br>.
+func (l *JavaScriptLexerBase) updateJsx(next antlr.Token) {
+	kind := l.jsxContext()
+	end := false
+	input := l.GetInputStream()
+	switch next.GetTokenType() {
+	case JavaScriptLexerLessThan:
+		switch {
+		case kind == jsxChildren && input.LA(1) == '/':
+			l.jsxContexts[len(l.jsxContexts)-1] = jsxClosingTag
+		case kind == jsxChildren || kind != jsxTag && kind != jsxVoidTag && kind != jsxClosingTag &&
+			l.IsRegexPossible() && isTagNameStart(input.LA(1)):
+			if voidElements[tagNameAhead(input, 1)] {
+				l.jsxContexts = append(l.jsxContexts, jsxVoidTag)
+			} else {
+				l.jsxContexts = append(l.jsxContexts, jsxTag)
+			}
+		}
+	case JavaScriptLexerDivide:
+		if kind == jsxTag || kind == jsxVoidTag {
+			// <a/>
+			l.jsxContexts[len(l.jsxContexts)-1] = jsxClosingTag
+		}
+	case JavaScriptLexerMoreThan:
+		switch kind {
+		case jsxTag:
+			l.jsxContexts[len(l.jsxContexts)-1] = jsxChildren
+		case jsxVoidTag:
+			if input.LA(1) == '<' && input.LA(2) == '/' && voidElements[tagNameAhead(input, 3)] {
+				l.jsxContexts[len(l.jsxContexts)-1] = jsxChildren
+			} else {
+				l.jsxContexts = l.jsxContexts[:len(l.jsxContexts)-1]
+				end = true
+			}
+		case jsxClosingTag:
+			l.jsxContexts = l.jsxContexts[:len(l.jsxContexts)-1]
+			end = true
+		}
+	case JavaScriptLexerOpenBrace:
+		switch kind {
+		case jsxTag, jsxVoidTag, jsxChildren:
+			l.jsxContexts = append(l.jsxContexts, jsxExpression)
+		case jsxExpression, jsxBrace:
+			l.jsxContexts = append(l.jsxContexts, jsxBrace)
+		}
+	case JavaScriptLexerCloseBrace:
+		if kind == jsxExpression || kind == jsxBrace {
+			l.jsxContexts = l.jsxContexts[:len(l.jsxContexts)-1]
+		}
+	}
+	l.jsxEnd = end
+}
+
+// jsxContext returns the innermost JSX context, -1 outside of JSX.
+func (l *JavaScriptLexerBase) jsxContext() int {
+	if n := len(l.jsxContexts); n > 0 {
+		return l.jsxContexts[n-1]
+	}
+	return -1
+}
+
+// isTagNameStart returns true if c can start the name of a JSX tag.
+func isTagNameStart(c int) bool {
+	return c == '_' || c == '$' || unicode.IsLetter(rune(c))
+}
+
+// tagNameAhead returns the tag name starting at input.LA(i).
+func tagNameAhead(input antlr.CharStream, i int) string {
+	var name []rune
+	for c := input.LA(i); isTagNameStart(c) || unicode.IsDigit(rune(c)) || c == '-'; c = input.LA(i) {
+		name = append(name, rune(c))
+		i++
+	}
+	return string(name)
+}
+
+// voidElements are the HTML elements that have no children, whose
+// opening tag may be left unclosed as in <br> or <img src={url}>. A
+// capitalized name such as <Input> is a component.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
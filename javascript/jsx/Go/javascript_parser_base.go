@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// JavaScriptParserBase implementation.
+type JavaScriptParserBase struct {
+	*antlr.BaseParser
+
+	// The names of the open JSX elements, innermost last.
+	tagNames []string
+}
+
+// Short for p.prev(str string)
+func (p *JavaScriptParserBase) p(str string) bool {
+	return p.prev(str)
+}
+
+// Whether the previous token value equals to str.
+func (p *JavaScriptParserBase) prev(str string) bool {
+	return p.GetTokenStream().LT(-1).GetText() == str
+}
+
+// Short for p.next(str string)
+func (p *JavaScriptParserBase) n(str string) bool {
+	return p.next(str)
+}
+
+// Whether the next token value equals to str.
+func (p *JavaScriptParserBase) next(str string) bool {
+	return p.GetTokenStream().LT(1).GetText() == str
+}
+
+// Whether no line terminator separates the current token from the
+// previous one.
+func (p *JavaScriptParserBase) notLineTerminator() bool {
+	return !p.lineTerminatorAhead()
+}
+
+func (p *JavaScriptParserBase) notOpenBraceAndNotFunction() bool {
+	nextTokenType := p.GetTokenStream().LT(1).GetTokenType()
+	return nextTokenType != JavaScriptParserOpenBrace && nextTokenType != JavaScriptParserFunction_
+}
+
+func (p *JavaScriptParserBase) closeBrace() bool {
+	return p.GetTokenStream().LT(1).GetTokenType() == JavaScriptParserCloseBrace
+}
+
+// Returns true if a token of the given type is among the hidden
+// channel tokens between the previous token and the current one.
+func (p *JavaScriptParserBase) here(_type int) bool {
+	return hasHiddenToken(p.GetTokenStream(), p.GetCurrentToken().GetTokenIndex(), _type)
+}
+
+// Returns true if a line terminator, or a multi line comment that
+// contains one, is among the hidden channel tokens between the
+// previous token and the current one.
+func (p *JavaScriptParserBase) lineTerminatorAhead() bool {
+	return newlineBefore(p.GetTokenStream(), p.GetCurrentToken().GetTokenIndex())
+}
+
+// Records the name of the JSX element whose opening tag is parsed.
+func (p *JavaScriptParserBase) pushHtmlTagName(tagName string) {
+	p.tagNames = append(p.tagNames, tagName)
+}
+
+// Whether the closing tag name matches the one of the innermost open
+// JSX element, which it closes.
+func (p *JavaScriptParserBase) popHtmlTagName(tagName string) bool {
+	n := len(p.tagNames)
+	if n == 0 {
+		return false
+	}
+	openName := p.tagNames[n-1]
+	p.tagNames = p.tagNames[:n-1]
+	return strings.EqualFold(tagName, openName)
+}
@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// errorCounter counts the syntax errors of a parse.
+type errorCounter struct {
+	*antlr.DefaultErrorListener
+	errors int
+}
+
+func (c *errorCounter) SyntaxError(recognizer antlr.Recognizer, offendingSymbol interface{}, line, column int, msg string, e antlr.RecognitionException) {
+	c.errors++
+}
+
+// The line terminators that end a statement may follow any number of
+// comments.
+func TestLineTerminatorAhead(t *testing.T) {
+	tests := []struct {
+		src        string
+		statements int // 0 for a syntax error
+	}{
+		{"a /*x*/ /*y*/ \n b", 2},
+		{"a /*x*/\n/*y*/ b", 2},
+		{"a /*x\n*/ b", 2},
+		{"a // x\n b", 2},
+		{"a\u2028b", 2},
+		{"a /*x*/ /*y*/ b", 0},
+		{"x = <a/> /*x*/ /*y*/ \n y", 2},
+		{"x = <a/> /*x*/ /*y*/ y", 0},
+	}
+	for _, test := range tests {
+		errors := &errorCounter{DefaultErrorListener: antlr.NewDefaultErrorListener()}
+		lexer := NewJavaScriptLexer(antlr.NewInputStream(test.src))
+		lexer.RemoveErrorListeners()
+		lexer.AddErrorListener(errors)
+		p := NewJavaScriptParser(antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel))
+		p.RemoveErrorListeners()
+		p.AddErrorListener(errors)
+		tree := p.Program().(*ProgramContext)
+		statements := 0
+		if tree.SourceElements() != nil && errors.errors == 0 {
+			statements = len(tree.SourceElements().(*SourceElementsContext).AllSourceElement())
+		}
+		if statements != test.statements {
+			t.Errorf("%q: %d statements, want %d (%d errors)", test.src, statements, test.statements, errors.errors)
+		}
+	}
+}
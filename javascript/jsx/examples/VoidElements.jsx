@@ -0,0 +1,19 @@
+import React from 'react';
+
+function Address({ street, city, photo }) {
+  return (
+    <p>
+      <img src={photo} alt="photo">
+      {street}<br>
+      {city}<br/>
+      <input type="checkbox" checked> visited
+      <hr></hr>
+      <Input label="name">Name</Input>
+    </p>
+  );
+}
+
+const divider = <hr>;
+const half = 1 / 2;
+
+export default Address;
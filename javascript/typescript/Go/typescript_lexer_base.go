@@ -0,0 +1,124 @@
+package parser
+
+import "github.com/antlr/antlr4/runtime/Go/antlr"
+
+// TypeScriptLexerBase state
+type TypeScriptLexerBase struct {
+	*antlr.BaseLexer
+
+	// The strict modes of the nested scopes, innermost last. By
+	// default the mode is strict if defined externally, see
+	// SetUseStrictDefault.
+	scopeStrictModes []bool
+
+	lastToken        antlr.Token
+	useStrictDefault bool
+	useStrictCurrent bool
+
+	// The depth of nested template string backticks, which tells
+	// whether a } closes an expression inside a template string.
+	templateDepth int
+}
+
+// GetStrictDefault returns the strict mode by default.
+func (l *TypeScriptLexerBase) GetStrictDefault() bool {
+	return l.useStrictDefault
+}
+
+// SetUseStrictDefault sets the strict mode by default, true for an
+// ES module. Call it before lexing.
+func (l *TypeScriptLexerBase) SetUseStrictDefault(value bool) {
+	l.useStrictDefault = value
+	l.useStrictCurrent = value
+}
+
+// IsStrictMode is self explanatory.
+func (l *TypeScriptLexerBase) IsStrictMode() bool {
+	return l.useStrictCurrent
+}
+
+// IsInTemplateString returns true if a } closes an expression inside
+// a template string.
+func (l *TypeScriptLexerBase) IsInTemplateString() bool {
+	return l.templateDepth > 0
+}
+
+// NextToken from the character stream.
+func (l *TypeScriptLexerBase) NextToken() antlr.Token {
+	next := l.BaseLexer.NextToken() // Get next token
+	if next.GetChannel() == antlr.TokenDefaultChannel {
+		// Keep track of the last token on default channel
+		l.lastToken = next
+	}
+	return next
+}
+
+// ProcessOpenBrace is called when a { is encountered during
+// lexing, we push a new scope everytime.
+func (l *TypeScriptLexerBase) ProcessOpenBrace() {
+	l.useStrictCurrent = l.useStrictDefault
+	if n := len(l.scopeStrictModes); n > 0 && l.scopeStrictModes[n-1] {
+		l.useStrictCurrent = true
+	}
+	l.scopeStrictModes = append(l.scopeStrictModes, l.useStrictCurrent)
+}
+
+// ProcessCloseBrace is called when a } is encountered during
+// lexing, we pop a scope unless we're inside global scope.
+func (l *TypeScriptLexerBase) ProcessCloseBrace() {
+	l.useStrictCurrent = l.useStrictDefault
+	if n := len(l.scopeStrictModes); n > 0 {
+		l.useStrictCurrent = l.scopeStrictModes[n-1]
+		l.scopeStrictModes = l.scopeStrictModes[:n-1]
+	}
+}
+
+// ProcessStringLiteral is called when lexing a string literal. A
+// "use strict" at the start of the input or of a scope makes the
+// scope strict.
+func (l *TypeScriptLexerBase) ProcessStringLiteral() {
+	if l.lastToken == nil || l.lastToken.GetTokenType() == TypeScriptLexerOpenBrace {
+		if l.GetText() == `"use strict"` || l.GetText() == "'use strict'" {
+			if n := len(l.scopeStrictModes); n > 0 {
+				l.scopeStrictModes = l.scopeStrictModes[:n-1]
+			}
+			l.useStrictCurrent = true
+			l.scopeStrictModes = append(l.scopeStrictModes, l.useStrictCurrent)
+		}
+	}
+}
+
+// IncreaseTemplateDepth is called on the backtick opening a template
+// string.
+func (l *TypeScriptLexerBase) IncreaseTemplateDepth() {
+	l.templateDepth++
+}
+
+// DecreaseTemplateDepth is called on the backtick closing a template
+// string.
+func (l *TypeScriptLexerBase) DecreaseTemplateDepth() {
+	l.templateDepth--
+}
+
+// IsRegexPossible returns true if the lexer can match a
+// regex literal.
+func (l *TypeScriptLexerBase) IsRegexPossible() bool {
+	if l.lastToken == nil {
+		// No token has been produced yet: at the start of the input,
+		// no division is possible, so a regex literal _is_ possible.
+		return true
+	}
+	switch l.lastToken.GetTokenType() {
+	case TypeScriptLexerIdentifier, TypeScriptLexerNullLiteral,
+		TypeScriptLexerBooleanLiteral, TypeScriptLexerThis,
+		TypeScriptLexerCloseBracket, TypeScriptLexerCloseParen,
+		TypeScriptLexerOctalIntegerLiteral, TypeScriptLexerDecimalLiteral,
+		TypeScriptLexerHexIntegerLiteral, TypeScriptLexerStringLiteral,
+		TypeScriptLexerPlusPlus, TypeScriptLexerMinusMinus:
+		// After any of the tokens above, no regex literal can follow.
+		return false
+	default:
+		// In all other cases, a regex literal _is_ possible.
+		return true
+	}
+}
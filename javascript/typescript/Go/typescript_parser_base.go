@@ -0,0 +1,56 @@
+package parser
+
+import "github.com/antlr/antlr4/runtime/Go/antlr"
+
+// TypeScriptParserBase implementation.
+type TypeScriptParserBase struct {
+	*antlr.BaseParser
+}
+
+// Short for p.prev(str string)
+func (p *TypeScriptParserBase) p(str string) bool {
+	return p.prev(str)
+}
+
+// Whether the previous token value equals to str.
+func (p *TypeScriptParserBase) prev(str string) bool {
+	return p.GetTokenStream().LT(-1).GetText() == str
+}
+
+// Short for p.next(str string)
+func (p *TypeScriptParserBase) n(str string) bool {
+	return p.next(str)
+}
+
+// Whether the next token value equals to str.
+func (p *TypeScriptParserBase) next(str string) bool {
+	return p.GetTokenStream().LT(1).GetText() == str
+}
+
+// Whether no line terminator separates the current token from the
+// previous one.
+func (p *TypeScriptParserBase) notLineTerminator() bool {
+	return !p.lineTerminatorAhead()
+}
+
+func (p *TypeScriptParserBase) notOpenBraceAndNotFunction() bool {
+	nextTokenType := p.GetTokenStream().LT(1).GetTokenType()
+	return nextTokenType != TypeScriptParserOpenBrace && nextTokenType != TypeScriptParserFunction_
+}
+
+func (p *TypeScriptParserBase) closeBrace() bool {
+	return p.GetTokenStream().LT(1).GetTokenType() == TypeScriptParserCloseBrace
+}
+
+// Returns true if a token of the given type is among the hidden
+// channel tokens between the previous token and the current one.
+func (p *TypeScriptParserBase) here(_type int) bool {
+	return hasHiddenToken(p.GetTokenStream(), p.GetCurrentToken().GetTokenIndex(), _type)
+}
+
+// Returns true if a line terminator, or a multi line comment that
+// contains one, is among the hidden channel tokens between the
+// previous token and the current one.
+func (p *TypeScriptParserBase) lineTerminatorAhead() bool {
+	return newlineBefore(p.GetTokenStream(), p.GetCurrentToken().GetTokenIndex())
+}
@@ -0,0 +1,51 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// errorCounter counts the syntax errors of a parse.
+type errorCounter struct {
+	*antlr.DefaultErrorListener
+	errors int
+}
+
+func (c *errorCounter) SyntaxError(recognizer antlr.Recognizer, offendingSymbol interface{}, line, column int, msg string, e antlr.RecognitionException) {
+	c.errors++
+}
+
+// The line terminators that end a statement may follow any number of
+// comments. The grammar only needs them after some statements, such as
+// debugger.
+func TestLineTerminatorAhead(t *testing.T) {
+	tests := []struct {
+		src        string
+		statements int // 0 for a syntax error
+	}{
+		{"debugger /*x*/ /*y*/ \n a", 2},
+		{"debugger /*x*/\n/*y*/ a", 2},
+		{"debugger /*x\n*/ a", 2},
+		{"debugger // x\n a", 2},
+		{"debugger\u2028a", 2},
+		{"debugger /*x*/ /*y*/ a", 0},
+	}
+	for _, test := range tests {
+		errors := &errorCounter{DefaultErrorListener: antlr.NewDefaultErrorListener()}
+		lexer := NewTypeScriptLexer(antlr.NewInputStream(test.src))
+		lexer.RemoveErrorListeners()
+		lexer.AddErrorListener(errors)
+		p := NewTypeScriptParser(antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel))
+		p.RemoveErrorListeners()
+		p.AddErrorListener(errors)
+		tree := p.Program().(*ProgramContext)
+		statements := 0
+		if tree.SourceElements() != nil && errors.errors == 0 {
+			statements = len(tree.SourceElements().(*SourceElementsContext).AllSourceElement())
+		}
+		if statements != test.statements {
+			t.Errorf("%q: %d statements, want %d (%d errors)", test.src, statements, test.statements, errors.errors)
+		}
+	}
+}